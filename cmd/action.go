@@ -1,15 +1,43 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/action"
 	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
 	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
 	listActionsFlag bool
 	silentListFlag  bool
+
+	actionListQuietFlag bool
+
+	actionShowRenderFlag   bool
+	actionShowWorktreeFlag string
+
+	actionCmdsFlag          []string
+	actionDirFlag           string
+	actionTypeFlag          string
+	actionPluginFlag        string
+	actionReviewContextFlag bool
+
+	actionRunDirFlag string
+
+	actionAddInteractiveFlag bool
 )
 
 var actionCmd = &cobra.Command{
@@ -20,52 +48,630 @@ var actionCmd = &cobra.Command{
 	`),
 	Example: heredoc.Doc(`
 		# List all available actions
-		gh wt action --list
+		gh wt action list
 
-		# List action names only (silent mode)
-		gh wt action -s
+		# Add an action
+		gh wt action add test --cmd "make test"
+
+		# Run it in the worktree of PR #123
+		gh wt action run test pr_123
 	`),
 	RunE:    runAction,
 	GroupID: "worktrees",
 }
 
+// actionListCmd represents the action list command.
+var actionListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List actions",
+	Example: heredoc.Doc(`
+		# List all available actions
+		gh wt action list
+
+		# Print action names only, one per line
+		gh wt action list --quiet
+	`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listActions(actionListQuietFlag)
+	},
+}
+
+// actionShowCmd represents the action show command.
+var actionShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show an action's definition",
+	Long: heredoc.Doc(`
+		Print an action's full definition: its type, commands, directory, the
+		environment it runs with, and the config file it comes from.
+
+		With --render, the command, directory, and env templates are rendered
+		against a sample PR worktree, or with --worktree against an existing
+		worktree, to preview what would run.
+	`),
+	Example: heredoc.Doc(`
+		# Show the claude action
+		gh wt action show claude
+
+		# Preview its commands with sample template data
+		gh wt action show claude --render
+
+		# Preview its commands for the worktree of PR #123
+		gh wt action show claude --worktree pr_123
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runActionShow,
+	ValidArgsFunction: completeActionName,
+}
+
+// actionAddCmd represents the action add command.
+var actionAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Add an action",
+	Long: heredoc.Doc(`
+		Add an action to the config file. Each --cmd is a command template,
+		run in order.
+
+		With --interactive, you are walked through the action's name, type,
+		commands (in $VISUAL or $EDITOR), directory, and env vars, and shown
+		its commands rendered against a sample worktree before it is saved.
+	`),
+	Example: heredoc.Doc(`
+		# Add a test action
+		gh wt action add test --cmd "make build" --cmd "make test"
+
+		# Add an action running in a subdirectory of the worktree
+		gh wt action add web --dir "{{.WorktreePath}}/web" --cmd "npm ci"
+
+		# Add an exec-plugin action running gh-wt-action-vscode
+		gh wt action add vscode --type exec-plugin
+
+		# Build an action step by step
+		gh wt action add --interactive
+	`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runActionAdd,
+}
+
+// actionEditCmd represents the action edit command.
+var actionEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Change an action",
+	Long: heredoc.Doc(`
+		Change the settings of an action given by flags; the others are kept.
+		--cmd replaces all of the action's commands.
+
+		Without flags, the action is opened as YAML in $VISUAL or $EDITOR and
+		saved when the editor exits. Changing its name there renames it.
+	`),
+	Example: heredoc.Doc(`
+		# Edit the test action in your editor
+		gh wt action edit test
+
+		# Replace the commands of the test action
+		gh wt action edit test --cmd "go test ./..."
+
+		# Run it from the repository root instead of the worktree
+		gh wt action edit test --dir "{{.ROOT_DIR}}"
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runActionEdit,
+	ValidArgsFunction: completeActionName,
+}
+
+// actionRenameCmd represents the action rename command.
+var actionRenameCmd = &cobra.Command{
+	Use:     "rename <name> <new-name>",
+	Aliases: []string{"mv"},
+	Short:   "Rename an action",
+	Example: heredoc.Doc(`
+		# Rename the test action
+		gh wt action rename test unit-test
+	`),
+	Args:              cobra.ExactArgs(2),
+	RunE:              runActionRename,
+	ValidArgsFunction: completeActionName,
+}
+
+// actionRemoveCmd represents the action remove command.
+var actionRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove an action",
+	Example: heredoc.Doc(`
+		# Remove the test action without confirmation
+		gh wt action remove test --force
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runActionRemove,
+	ValidArgsFunction: completeActionName,
+}
+
+// actionRunCmd represents the action run command.
+var actionRunCmd = &cobra.Command{
+	Use:   "run <name> [worktree|number|url] [-- args]",
+	Short: "Run an action in a worktree or directory",
+	Long: heredoc.Doc(`
+		Run an action in an existing worktree, like gh wt run with the action
+		given first. Arguments after -- are passed to the action.
+
+		Without a worktree, the action runs in the current directory, or the
+		one given by --dir, so actions can be used as task runners and tried
+		out while writing them. The template data is that of the worktree
+		containing the directory, or of a local worktree named after it.
+	`),
+	Example: heredoc.Doc(`
+		# Run the test action in the worktree of PR #123
+		gh wt action run test 123
+
+		# Pass arguments to the claude action
+		gh wt action run claude pr_123 -- fix the failing test
+
+		# Run the test action in the current directory
+		gh wt action run test
+
+		# Run it in another checkout
+		gh wt action run test --dir ~/src/gh-wt
+	`),
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runActionRun,
+	ValidArgsFunction: completeActionRunArgs,
+}
+
 func init() {
 	rootCmd.AddCommand(actionCmd)
 	actionCmd.Flags().BoolVarP(&listActionsFlag, "list", "l", false, "list all available actions")
 	actionCmd.Flags().BoolVarP(&silentListFlag, "silent", "s", false, "suppress output when listing")
+	_ = actionCmd.Flags().MarkDeprecated("list", "use 'gh wt action list' instead")
+	_ = actionCmd.Flags().MarkDeprecated("silent", "use 'gh wt action list --quiet' instead")
+
+	actionListCmd.Flags().BoolVarP(&actionListQuietFlag, "quiet", "q", false, "print action names only")
+
+	actionShowCmd.Flags().BoolVarP(&actionShowRenderFlag, "render", "r", false, "render templates against a sample worktree")
+	actionShowCmd.Flags().StringVarP(&actionShowWorktreeFlag, "worktree", "w", "", "render templates against this worktree")
+
+	for _, c := range []*cobra.Command{actionAddCmd, actionEditCmd} {
+		c.Flags().StringArrayVarP(&actionCmdsFlag, "cmd", "c", nil, "command template to run (repeatable)")
+		c.Flags().StringVarP(&actionDirFlag, "dir", "d", "", "directory template to run the commands in (default the worktree)")
+		c.Flags().StringVarP(&actionTypeFlag, "type", "t", "", "action type: shell or exec-plugin")
+		c.Flags().StringVar(&actionPluginFlag, "plugin", "", "for exec-plugin actions, run gh-wt-action-<plugin> (default the action name)")
+		c.Flags().BoolVar(&actionReviewContextFlag, "review-context", false, "write the PR review context to {{.ReviewFile}} for PR worktrees")
+	}
+
+	actionAddCmd.Flags().BoolVarP(&actionAddInteractiveFlag, "interactive", "i", false, "build the action step by step with prompts")
+	actionRunCmd.Flags().StringVarP(&actionRunDirFlag, "dir", "d", "", "run in this directory instead of a worktree (default the current directory)")
+
+	actionCmd.AddCommand(actionListCmd, actionShowCmd, actionAddCmd, actionEditCmd, actionRenameCmd, actionRemoveCmd, actionRunCmd)
 }
 
+// runAction handles the deprecated --list and --silent flags.
 func runAction(cmd *cobra.Command, args []string) error {
+	if listActionsFlag || silentListFlag {
+		return listActions(silentListFlag)
+	}
+	return cmd.Help()
+}
+
+// listActions prints the configured actions, or with quiet only their names,
+// one per line.
+func listActions(quiet bool) error {
 	cfg, err := config.Get()
 	if err != nil {
 		return err
 	}
 
-	if listActionsFlag {
-		// List all available actions
-		if len(cfg.Actions) == 0 {
-			if !silentListFlag {
-				Log.Outf(logger.Yellow, "No actions configured.\n")
-			}
+	if len(cfg.Actions) == 0 {
+		if !quiet {
+			Log.Outf(logger.Yellow, "No actions configured.\n")
+		}
+		return nil
+	}
+
+	// Quiet mode: just print action names, one per line
+	if quiet {
+		for _, action := range cfg.Actions {
+			Log.Outf(logger.Default, "%s\n", action.Name)
+		}
+		return nil
+	}
+
+	// Normal mode: print with formatting
+	Log.Outf(logger.Default, "Available actions:\n")
+	for _, action := range cfg.Actions {
+		Log.Outf(logger.Default, "  - %s\n", action.Name)
+	}
+	return nil
+}
+
+func runActionAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	var a config.Action
+	if actionAddInteractiveFlag {
+		var save bool
+		if a, save, err = buildAction(cfg, name); err != nil {
+			return err
+		}
+		if !save {
+			Log.Warnf("Cancelled - no changes made\n")
 			return nil
 		}
+	} else {
+		if name == "" {
+			return fmt.Errorf("an action name is required without --interactive")
+		}
+		if slices.ContainsFunc(cfg.Actions, func(a config.Action) bool { return a.Name == name }) {
+			return fmt.Errorf("action '%s' already exists; use 'gh wt action edit' to change it", name)
+		}
+		a = config.Action{Name: name}
+		applyActionFlags(cmd, &a)
+	}
 
-		// Silent mode: just print action names, one per line
-		if silentListFlag {
-			for _, action := range cfg.Actions {
-				Log.Outf(logger.Default, "%s\n", action.Name)
-			}
+	if err := config.SaveActions(append(cfg.Actions, a)); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "✓ Added action '%s'\n", a.Name)
+	return nil
+}
+
+func runActionEdit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	a, err := action.Find(cfg.Actions, args[0])
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc([]string{"cmd", "dir", "type", "plugin", "review-context"}, cmd.Flags().Changed) {
+		if !term.IsTerminal(os.Stdin) {
+			return fmt.Errorf("nothing to change; pass --cmd, --dir, --type, --plugin, or --review-context, or run in a terminal to use an editor")
+		}
+		edited, ok, err := editAction(*a)
+		if err != nil || !ok {
+			return err
+		}
+		if edited.Name != a.Name && slices.ContainsFunc(cfg.Actions, func(b config.Action) bool { return b.Name == edited.Name }) {
+			return fmt.Errorf("action '%s' already exists", edited.Name)
+		}
+		*a = edited
+	} else {
+		applyActionFlags(cmd, a)
+	}
+
+	if err := config.SaveActions(cfg.Actions); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "✓ Updated action '%s'\n", a.Name)
+	return nil
+}
+
+// editAction opens a as YAML in the user's editor until it parses, and
+// returns the edited action. It returns false when nothing was changed or
+// the user gave up on an invalid edit.
+func editAction(a config.Action) (config.Action, bool, error) {
+	data, err := config.MarshalAction(a)
+	if err != nil {
+		return a, false, err
+	}
+	text := string(data)
+	for {
+		edited, err := editText(text, "gh-wt-action-*.yaml")
+		if err != nil {
+			return a, false, err
+		}
+		if edited == string(data) {
+			Log.Warnf("No changes made\n")
+			return a, false, nil
+		}
+		parsed, err := config.ParseAction([]byte(edited))
+		if err == nil {
+			return parsed, true, nil
+		}
+		Log.Errorf("%v\n", err)
+		again, perr := newPrompter(os.Stdout).Confirm("Edit again?", true)
+		if perr != nil {
+			return a, false, fmt.Errorf("prompt failed: %w", perr)
+		}
+		if !again {
+			Log.Warnf("Cancelled - no changes made\n")
+			return a, false, nil
+		}
+		text = edited
+	}
+}
+
+func runActionRename(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	name, newName := args[0], strings.TrimSpace(args[1])
+	a, err := action.Find(cfg.Actions, name)
+	if err != nil {
+		return err
+	}
+	if source := config.ActionSource(*a); source != "" {
+		return fmt.Errorf("action '%s' is defined in %s; rename it there", name, source)
+	}
+	if newName == "" {
+		return fmt.Errorf("the new name cannot be empty")
+	}
+	if slices.ContainsFunc(cfg.Actions, func(b config.Action) bool { return b.Name == newName }) {
+		return fmt.Errorf("action '%s' already exists", newName)
+	}
+
+	a.Name = newName
+	if err := config.SaveActions(cfg.Actions); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "✓ Renamed action '%s' to '%s'\n", name, newName)
+	return nil
+}
+
+// applyActionFlags sets the fields of a given by the add and edit flags.
+func applyActionFlags(cmd *cobra.Command, a *config.Action) {
+	flags := cmd.Flags()
+	if flags.Changed("cmd") {
+		a.Cmds = actionCmdsFlag
+	}
+	if flags.Changed("dir") {
+		a.Dir = actionDirFlag
+	}
+	if flags.Changed("type") {
+		a.Type = actionTypeFlag
+	}
+	if flags.Changed("plugin") {
+		a.Plugin = actionPluginFlag
+	}
+	if flags.Changed("review-context") {
+		a.ReviewContext = actionReviewContextFlag
+	}
+}
+
+func runActionRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	a, err := action.Find(cfg.Actions, name)
+	if err != nil {
+		return err
+	}
+	if source := config.ActionSource(*a); source != "" {
+		if dir, err := config.IncludesDir(); err == nil && filepath.Dir(source) == dir {
+			return fmt.Errorf("action '%s' comes from the shared config %s; stop using it with 'gh wt config pull <owner/repo> --remove', or override the action with 'gh wt action edit'", name, source)
+		}
+		return fmt.Errorf("action '%s' is defined in %s; remove it there, or override it with 'gh wt action edit'", name, source)
+	}
+
+	if !forceFlag {
+		p := newPrompter(os.Stdout)
+		confirm, err := p.Confirm(fmt.Sprintf("Remove action '%s'?", name), false)
+		if err != nil {
+			return fmt.Errorf("prompt failed: %w", err)
+		}
+		if !confirm {
+			Log.Warnf("Cancelled - no changes made\n")
 			return nil
 		}
+	}
 
-		// Normal mode: print with formatting
-		Log.Outf(logger.Default, "Available actions:\n")
-		for _, action := range cfg.Actions {
-			Log.Outf(logger.Default, "  - %s\n", action.Name)
+	actions := slices.DeleteFunc(cfg.Actions, func(a config.Action) bool { return a.Name == name })
+	if err := config.SaveActions(actions); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "✓ Removed action '%s'\n", name)
+	return nil
+}
+
+func runActionShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	a, err := action.Find(cfg.Actions, args[0])
+	if err != nil {
+		return err
+	}
+
+	var data *action.TemplateData
+	switch {
+	case actionShowWorktreeFlag != "":
+		if data, err = worktreeTemplateData(actionShowWorktreeFlag); err != nil {
+			return err
 		}
-		return nil
+	case actionShowRenderFlag:
+		data = sampleTemplateData(cfg.WorktreeBase)
 	}
+	if data != nil {
+		data.Action = a.Name
+	}
+	return printAction(cfg, a, data)
+}
 
-	// No flag provided, show help
-	return cmd.Help()
+// printAction prints the definition of a, with its templates rendered
+// against data when it is not nil.
+func printAction(cfg config.Config, a *config.Action, data *action.TemplateData) error {
+	render := func(name, text string) (string, error) {
+		if data == nil {
+			return text, nil
+		}
+		out, err := action.Render(name, text, data)
+		if err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		return out, nil
+	}
+
+	typ := a.Type
+	if typ == "" {
+		typ = "shell"
+	}
+	Log.Outf(logger.Default, "Name:    %s\n", a.Name)
+	Log.Outf(logger.Default, "Type:    %s\n", typ)
+	if typ == "exec-plugin" {
+		plugin := a.Plugin
+		if plugin == "" {
+			plugin = a.Name
+		}
+		Log.Outf(logger.Default, "Plugin:  %s%s\n", action.PluginPrefix, plugin)
+	}
+	dir, err := render("dir", a.Dir)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = "(worktree)"
+	}
+	Log.Outf(logger.Default, "Dir:     %s\n", dir)
+	if a.ReviewContext {
+		Log.Outf(logger.Default, "Review:  writes PR review context to {{.ReviewFile}}\n")
+	}
+	source := config.ActionSource(*a)
+	if source == "" {
+		source = config.ConfigFileUsed()
+	}
+	Log.Outf(logger.Default, "Source:  %s\n", source)
+	if data != nil {
+		Log.Outf(logger.Default, "\nRendered for %s\n", data.WorktreePath)
+	}
+
+	if len(a.Cmds) > 0 {
+		Log.Outf(logger.Default, "\nCommands:\n")
+		for i, c := range a.Cmds {
+			out, err := render(fmt.Sprintf("cmds[%d]", i), c)
+			if err != nil {
+				return err
+			}
+			// Indent the continuation lines of multi-line commands.
+			Log.Outf(logger.Default, "  %d. %s\n", i+1, strings.ReplaceAll(out, "\n", "\n     "))
+		}
+	}
+
+	if env := append(slices.Clip(cfg.Env), a.Env...); len(env) > 0 {
+		Log.Outf(logger.Default, "\nEnv:\n")
+		for _, v := range env {
+			out, err := render("env "+v.Name, v.Value)
+			if err != nil {
+				return err
+			}
+			Log.Outf(logger.Default, "  %s=%s\n", v.Name, out)
+		}
+	}
+	return nil
+}
+
+// worktreeTemplateData returns the action template data of an existing
+// worktree.
+func worktreeTemplateData(name string) (*action.TemplateData, error) {
+	wt, err := findWorktree(name)
+	if err != nil {
+		return nil, err
+	}
+	owner, repoName, err := currentRepo()
+	if err != nil {
+		return nil, err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+	data, err := action.NewTemplateData(wt.Path, runInfo(wt, store, owner, repoName))
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// sampleTemplateData returns template data for a made-up PR worktree, used to
+// preview templates without touching a real worktree.
+func sampleTemplateData(worktreeBase string) *action.TemplateData {
+	info := &worktree.WorktreeInfo{
+		Type:         worktree.PR,
+		Owner:        "octocat",
+		Repo:         "hello-world",
+		Number:       123,
+		BranchName:   "fix-login",
+		WorktreeName: "pr_123",
+		Title:        "Fix login redirect",
+	}
+	data := &action.TemplateData{
+		WorktreePath:       filepath.Join(worktreeBase, info.Repo, info.WorktreeName),
+		WorktreeName:       info.WorktreeName,
+		OS:                 runtime.GOOS,
+		ARCH:               runtime.GOARCH,
+		ROOT_DIR:           filepath.Join(worktreeBase, info.Repo, info.Repo),
+		ComposeProjectName: action.ComposeProjectName(info.Repo, info.WorktreeName),
+		Port:               4000,
+		WorktreeInfo:       info,
+	}
+	data.SetArgs([]string{"sample", "args"})
+	return data
+}
+
+func runActionRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 2 {
+		if actionRunDirFlag != "" {
+			return fmt.Errorf("--dir cannot be used with a worktree")
+		}
+		return runInWorktree(cmd, args[1], args[0])
+	}
+
+	dir := actionRunDirFlag
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("directory %s does not exist", dir)
+	}
+
+	if err := action.Execute(context.Background(), &action.ExecuteOptions{
+		ActionName:   args[0],
+		WorktreePath: dir,
+		Info:         dirInfo(dir),
+		CLIArgs:      cliArgs,
+		Args:         cliArgv,
+		Logger:       Log,
+		Stdin:        os.Stdin,
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		Env:          os.Environ(),
+	}); err != nil {
+		return fmt.Errorf("action '%s' failed: %w", args[0], err)
+	}
+	return nil
+}
+
+// dirInfo returns the template data of dir for actions run outside the
+// worktree lifecycle: the worktree's metadata when dir is a managed worktree,
+// and otherwise a local worktree named after dir on its current branch.
+func dirInfo(dir string) *worktree.WorktreeInfo {
+	wt := git.WorktreeInfo{Path: dir}
+	if branch, err := git.GetCurrentBranch(dir); err == nil && branch != "HEAD" {
+		wt.Branch = branch
+	}
+	// Outside a GitHub repository the owner and repo are left empty.
+	owner, repoName, _ := currentRepo()
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+	return runInfo(wt, store, owner, repoName)
 }
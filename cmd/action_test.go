@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintAction(t *testing.T) {
+	var out bytes.Buffer
+	Log = &logger.Logger{Stdout: &out}
+	t.Cleanup(func() { Log = nil })
+
+	cfg := config.Config{Env: []config.EnvVar{{Name: "APP", Value: "app_{{.WorktreeName}}"}}}
+	a := &config.Action{
+		Name: "test",
+		Cmds: []string{"make test PR={{.Number}}", "echo {{.ArgsQuoted}}"},
+		Dir:  "{{.WorktreePath}}/web",
+	}
+
+	require.NoError(t, printAction(cfg, a, nil))
+	assert.Contains(t, out.String(), "Type:    shell\n")
+	assert.Contains(t, out.String(), "Dir:     {{.WorktreePath}}/web\n")
+	assert.Contains(t, out.String(), "  1. make test PR={{.Number}}\n")
+	assert.Contains(t, out.String(), "  APP=app_{{.WorktreeName}}\n")
+
+	out.Reset()
+	data := sampleTemplateData("/wt")
+	require.NoError(t, printAction(cfg, a, data))
+	assert.Contains(t, out.String(), "Dir:     /wt/hello-world/pr_123/web\n")
+	assert.Contains(t, out.String(), "  1. make test PR=123\n")
+	assert.Contains(t, out.String(), "  2. echo sample args\n")
+	assert.Contains(t, out.String(), "  APP=app_pr_123\n")
+
+	a.Cmds = []string{"{{.Nope}}"}
+	assert.ErrorContains(t, printAction(cfg, a, data), "failed to render cmds[0]")
+}
+
+func TestApplyActionFlags(t *testing.T) {
+	t.Cleanup(func() {
+		actionCmdsFlag, actionDirFlag = nil, ""
+		for _, name := range []string{"cmd", "dir"} {
+			actionEditCmd.Flags().Lookup(name).Changed = false
+		}
+	})
+	require.NoError(t, actionEditCmd.Flags().Set("cmd", "go build ./..."))
+	require.NoError(t, actionEditCmd.Flags().Set("cmd", "go test ./..."))
+	require.NoError(t, actionEditCmd.Flags().Set("dir", "{{.ROOT_DIR}}"))
+
+	a := config.Action{Name: "test", Type: "shell", Cmds: []string{"make test"}, ReviewContext: true}
+	applyActionFlags(actionEditCmd, &a)
+	assert.Equal(t, config.Action{
+		Name:          "test",
+		Type:          "shell",
+		Cmds:          []string{"go build ./...", "go test ./..."},
+		Dir:           "{{.ROOT_DIR}}",
+		ReviewContext: true,
+	}, a)
+}
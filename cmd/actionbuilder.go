@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/execext"
+	"github.com/ffalor/gh-wt/internal/logger"
+)
+
+// commandSeparator separates commands in the editor of the interactive action
+// builder, so a single command can span several lines.
+const commandSeparator = "---"
+
+// envNamePattern matches valid environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// buildAction walks through the settings of a new action: its name, type,
+// commands, directory, and env vars, then previews it against a sample
+// worktree. It returns false if the user chose not to save it.
+func buildAction(cfg config.Config, name string) (config.Action, bool, error) {
+	if !term.IsTerminal(os.Stdin) {
+		return config.Action{}, false, fmt.Errorf("--interactive requires a terminal")
+	}
+	p := newPrompter(os.Stdout)
+	var a config.Action
+	var err error
+	if a.Name, err = inputActionName(p, cfg.Actions, name); err != nil {
+		return a, false, err
+	}
+
+	types := []string{"shell", "exec-plugin"}
+	idx, err := p.Select("Type:", types[0], types)
+	if err != nil {
+		return a, false, fmt.Errorf("prompt failed: %w", err)
+	}
+	if types[idx] == "exec-plugin" {
+		a.Type = types[idx]
+		plugin, err := p.Input("Plugin (runs gh-wt-action-<plugin>):", a.Name)
+		if err != nil {
+			return a, false, fmt.Errorf("prompt failed: %w", err)
+		}
+		if plugin = strings.TrimSpace(plugin); plugin != a.Name {
+			a.Plugin = plugin
+		}
+	} else {
+		Log.Infof("Opening an editor for the commands; separate commands with a line containing only %s\n", commandSeparator)
+		if a.Cmds, err = editCommands(p); err != nil {
+			return a, false, err
+		}
+	}
+
+	if a.Dir, err = inputTemplate(p, "Directory template (empty for the worktree):"); err != nil {
+		return a, false, err
+	}
+	if a.Env, err = inputEnv(p); err != nil {
+		return a, false, err
+	}
+
+	Log.Outf(logger.Default, "\n")
+	if err := printAction(cfg, &a, sampleTemplateData(cfg.WorktreeBase)); err != nil {
+		Log.Warnf("Preview failed: %v\n", err)
+	}
+	Log.Outf(logger.Default, "\n")
+	save, err := p.Confirm(fmt.Sprintf("Save action '%s'?", a.Name), true)
+	if err != nil {
+		return a, false, fmt.Errorf("prompt failed: %w", err)
+	}
+	return a, save, nil
+}
+
+// inputActionName asks for the name of a new action until it is not empty
+// and not taken by one of actions.
+func inputActionName(p prompter, actions []config.Action, name string) (string, error) {
+	for {
+		answer, err := p.Input("Action name:", name)
+		if err != nil {
+			return "", fmt.Errorf("prompt failed: %w", err)
+		}
+		answer = strings.TrimSpace(answer)
+		switch {
+		case answer == "":
+			Log.Warnf("The name is required\n")
+		case slices.ContainsFunc(actions, func(a config.Action) bool { return a.Name == answer }):
+			Log.Warnf("Action '%s' already exists\n", answer)
+		default:
+			return answer, nil
+		}
+		name = ""
+	}
+}
+
+// editCommands opens the commands in an editor until they are not empty and
+// every command parses as a template.
+func editCommands(p prompter) ([]string, error) {
+	text := ""
+	for {
+		edited, err := editText(text, "gh-wt-action-*.sh")
+		if err != nil {
+			return nil, err
+		}
+		text = edited
+		cmds := splitCommands(text)
+		err = checkTemplates(cmds)
+		if err == nil && len(cmds) > 0 {
+			return cmds, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("at least one command is required")
+		}
+		Log.Warnf("%v\n", err)
+		again, perr := p.Confirm("Edit the commands again?", true)
+		if perr != nil {
+			return nil, fmt.Errorf("prompt failed: %w", perr)
+		}
+		if !again {
+			return nil, cancelled()
+		}
+	}
+}
+
+// splitCommands splits editor text into commands at lines containing only
+// commandSeparator, dropping empty commands.
+func splitCommands(text string) []string {
+	var cmds []string
+	var current []string
+	flush := func() {
+		if c := strings.TrimSpace(strings.Join(current, "\n")); c != "" {
+			cmds = append(cmds, c)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == commandSeparator {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return cmds
+}
+
+// checkTemplates returns an error naming the first command that does not
+// parse as a template.
+func checkTemplates(cmds []string) error {
+	for i, c := range cmds {
+		if _, err := template.New(fmt.Sprintf("command %d", i+1)).Parse(c); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
+
+// inputTemplate asks for an optional template until the answer parses.
+func inputTemplate(p prompter, prompt string) (string, error) {
+	for {
+		answer, err := p.Input(prompt, "")
+		if err != nil {
+			return "", fmt.Errorf("prompt failed: %w", err)
+		}
+		answer = strings.TrimSpace(answer)
+		if _, err := template.New("dir").Parse(answer); err != nil {
+			Log.Warnf("Invalid template: %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// inputEnv asks for NAME=value env vars until an empty answer.
+func inputEnv(p prompter) ([]config.EnvVar, error) {
+	var env []config.EnvVar
+	for {
+		answer, err := p.Input("Env var as NAME=value (empty to finish):", "")
+		if err != nil {
+			return nil, fmt.Errorf("prompt failed: %w", err)
+		}
+		if answer = strings.TrimSpace(answer); answer == "" {
+			return env, nil
+		}
+		v, err := parseEnvVar(answer)
+		if err != nil {
+			Log.Warnf("%v\n", err)
+			continue
+		}
+		env = append(env, v)
+	}
+}
+
+// parseEnvVar parses NAME=value, where value is a template.
+func parseEnvVar(s string) (config.EnvVar, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || !envNamePattern.MatchString(name) {
+		return config.EnvVar{}, fmt.Errorf("expected NAME=value, got %q", s)
+	}
+	if _, err := template.New(name).Parse(value); err != nil {
+		return config.EnvVar{}, fmt.Errorf("invalid template: %w", err)
+	}
+	return config.EnvVar{Name: name, Value: value}, nil
+}
+
+// editText opens text in the user's editor ($VISUAL or $EDITOR) in a
+// temporary file named after pattern and returns the edited text.
+func editText(text, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := textEditor()
+	if err := execext.RunCommand(context.Background(), &execext.RunCommandOptions{
+		Command: editor + ` "$1"`,
+		Args:    []string{f.Name()},
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read temporary file: %w", err)
+	}
+	return string(data), nil
+}
+
+// textEditor returns the command of the user's editor.
+func textEditor() string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(key)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
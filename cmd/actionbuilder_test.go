@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommands(t *testing.T) {
+	text := "make build\n---\nif true; then\n  make test\nfi\n---\n\n---\n"
+	assert.Equal(t, []string{"make build", "if true; then\n  make test\nfi"}, splitCommands(text))
+	assert.Empty(t, splitCommands("\n\n"))
+}
+
+func TestParseEnvVar(t *testing.T) {
+	v, err := parseEnvVar("APP=app_{{.WorktreeName}}")
+	require.NoError(t, err)
+	assert.Equal(t, config.EnvVar{Name: "APP", Value: "app_{{.WorktreeName}}"}, v)
+
+	_, err = parseEnvVar("1APP=x")
+	assert.ErrorContains(t, err, "expected NAME=value")
+	_, err = parseEnvVar("APP={{.Nope")
+	assert.ErrorContains(t, err, "invalid template")
+}
+
+func TestInputPrompts(t *testing.T) {
+	Log = &logger.Logger{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	t.Cleanup(func() { Log = nil })
+
+	var out bytes.Buffer
+	p := newPlainPrompter(strings.NewReader("\ntest\nlint\n"), &out)
+	name, err := inputActionName(p, []config.Action{{Name: "test"}}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "lint", name, "empty and taken names are asked again")
+
+	p = newPlainPrompter(strings.NewReader("BAD\nA=1\nB={{.Port}}\n\n"), &out)
+	env, err := inputEnv(p)
+	require.NoError(t, err)
+	assert.Equal(t, []config.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "{{.Port}}"}}, env)
+}
@@ -3,23 +3,31 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
-	gh "github.com/cli/go-gh/v2"
-	"github.com/cli/go-gh/v2/pkg/prompter"
 	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/cli/go-gh/v2/pkg/term"
 	"github.com/ffalor/gh-wt/internal/action"
 	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/ffalor/gh-wt/internal/execext"
 	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/gitlab"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
 	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
 	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/ffalor/gh-wt/pkg/wt"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +39,8 @@ var addCmd = &cobra.Command{
 		Add a new git worktree from either:
 		  - A GitHub pull request URL or number
 		  - A GitHub issue URL or number
+		  - A GitLab merge request or issue URL or number (see providers in the config)
+		  - A URL matched by one of the url_parsers in the config (e.g. Jira or Linear)
 		  - A name to use for the new worktree and branch
 	`),
 	Example: heredoc.Doc(`
@@ -40,6 +50,12 @@ var addCmd = &cobra.Command{
 		# Create worktree from Issue URL
 		gh wt add https://github.com/owner/repo/issues/456
 
+		# Create worktree from a GitLab merge request URL
+		gh wt add https://gitlab.com/group/project/-/merge_requests/789
+
+		# Create a worktree from a Linear issue URL matched by url_parsers
+		gh wt add https://linear.app/acme/issue/ENG-123/fix-login
+
 		# Create a worktree from a local branch
 		gh wt add my-feature-branch
 
@@ -54,6 +70,27 @@ var addCmd = &cobra.Command{
 
 		# Create worktree with custom name
 		gh wt add https://github.com/owner/repo/pull/123 --name my-custom-name
+
+		# Create worktree from the open PR of a branch
+		gh wt add --pr-branch feature/login
+
+		# Create worktrees for every PR of a stack of dependent PRs
+		gh wt add --pr 123 --with-stack
+
+		# Check out a contributor's PR branch from their fork, to push to it
+		gh wt add --pr 123 --fork-remote
+
+		# Create worktree from the PR as merged into its base branch
+		gh wt add --pr 123 --merge-ref
+
+		# Create a PR worktree without gh authentication
+		gh wt add --pr 123 --git-only
+
+		# Create a worktree and cd into it
+		cd "$(gh wt add 123 --print-path)"
+
+		# Create a throwaway worktree without running hooks or setup
+		gh wt add scratch --no-verify
 	`),
 	Aliases: []string{"create"},
 	Args:    cobra.RangeArgs(0, 1),
@@ -64,23 +101,78 @@ var addCmd = &cobra.Command{
 func init() {
 	addCmd.Flags().StringVar(&prFlag, "pr", "", "PR number, PR URL, or git remote URL with PR ref")
 	addCmd.Flags().StringVar(&issueFlag, "issue", "", "issue number, issue URL, or git remote URL with issue ref")
+	addCmd.Flags().StringVar(&prBranchFlag, "pr-branch", "", "create a worktree for the open PR whose head is this branch ([owner:]branch)")
+	addCmd.MarkFlagsMutuallyExclusive("pr", "issue", "pr-branch")
 	addCmd.Flags().StringVarP(&branchFlag, "branch", "b", "", "branch name to use for the new worktree")
 	addCmd.Flags().StringVarP(&nameFlag, "name", "n", "", "name to use for the worktree (overrides default for PR/Issue)")
 	addCmd.Flags().StringVarP(&actionFlag, "action", "a", "", "action to run after worktree creation")
+	_ = addCmd.RegisterFlagCompletionFunc("action", completeActions)
+	_ = addCmd.RegisterFlagCompletionFunc("pr", completePullRequests)
+	addCmd.Flags().BoolVar(&mergeRefFlag, "merge-ref", false, "check out the PR as merged into its base (refs/pull/N/merge) instead of its head")
+	addCmd.Flags().BoolVar(&withStackFlag, "with-stack", false, "also create worktrees for the PRs the PR is stacked on and those stacked on it")
+	addCmd.Flags().BoolVar(&forkRemoteFlag, "fork-remote", false, "fetch a fork's PR branch through a remote for the fork, so git push updates the PR")
+	addCmd.MarkFlagsMutuallyExclusive("merge-ref", "fork-remote")
+	addCmd.Flags().BoolVar(&assignFlag, "assign", false, "assign yourself to the issue (default from config issue.assign)")
+	addCmd.Flags().Bool("git-only", false, "create PR and issue worktrees without the GitHub API, fetching refs/pull/N/head (default from config git_only)")
+	addCmd.Flags().BoolVar(&printPathFlag, "print-path", false, "print only the absolute worktree path on stdout; all other output goes to stderr")
+	addCmd.Flags().BoolVar(&addJSONFlag, "json", false, "print the worktree and the outcome of each creation step as JSON to stdout; all other output goes to stderr")
+	addCmd.MarkFlagsMutuallyExclusive("print-path", "json")
+	addCmd.MarkFlagsMutuallyExclusive("with-stack", "issue")
+	addCmd.MarkFlagsMutuallyExclusive("with-stack", "name")
+	addCmd.MarkFlagsMutuallyExclusive("with-stack", "branch")
+	addCmd.MarkFlagsMutuallyExclusive("with-stack", "print-path")
+	addCmd.MarkFlagsMutuallyExclusive("with-stack", "json")
+	addCmd.Flags().BoolVar(&noVerifyFlag, "no-verify", false, "skip git hooks and post-create setup (env file, .envrc, git identity); an explicit --action still runs")
 	addCmd.Flags().StringVarP(&startPointFlag, "start-point", "s", "HEAD", "starting point for the new branch (e.g., branch, tag, commit); ignored for PRs")
 	rootCmd.AddCommand(addCmd)
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
+	if err := config.BindFlag("issue.assign", cmd.Flags().Lookup("assign")); err != nil {
+		return err
+	}
+	if err := config.BindFlag("git_only", cmd.Flags().Lookup("git-only")); err != nil {
+		return err
+	}
+	if noVerifyFlag {
+		git.SetSkipHooks(true)
+	}
+	if printPathFlag || addJSONFlag {
+		// Keep stdout for the path or report alone so it can be captured.
+		Log.Stdout = os.Stderr
+		git.SetOutput(os.Stderr)
+	}
+	if len(args) == 0 && prFlag == "" && issueFlag == "" && prBranchFlag == "" {
+		return cmd.Help()
+	}
+
+	p := newProgress(Log)
+	err := addWorktree(p, args)
+	if addJSONFlag {
+		p.finish(err)
+		if err := json.NewEncoder(os.Stdout).Encode(p.report); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// addWorktree creates the worktree for --pr, --issue, or the argument,
+// reporting its steps to p.
+func addWorktree(p *progress, args []string) error {
 	// Determine the type of input
 	if prFlag != "" {
-		return createFromPR(prFlag)
+		return addPR(p, prFlag)
 	}
 	if issueFlag != "" {
-		return createFromIssue(issueFlag)
+		return createFromIssue(p, issueFlag)
 	}
-	if len(args) == 0 {
-		return cmd.Help()
+	if prBranchFlag != "" {
+		number, err := prForBranch(prBranchFlag)
+		if err != nil {
+			return err
+		}
+		return addPR(p, strconv.Itoa(number))
 	}
 
 	// This is the main entry point for creating a worktree
@@ -92,44 +184,56 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	switch worktreeType {
 	case worktree.PR:
-		return createFromPR(arg)
+		return addPR(p, arg)
 	case worktree.Issue:
-		return createFromIssue(arg)
+		return createFromIssue(p, arg)
 	default:
-		return createFromLocal(arg)
+		cfg, err := config.Get()
+		if err != nil {
+			return err
+		}
+		name, parser, err := nameFromURL(cfg.URLParsers, arg)
+		if err != nil {
+			return err
+		}
+		if parser != nil {
+			if parser.Name != "" {
+				Log.Infof("Matched %s URL, using name '%s'\n", parser.Name, name)
+			}
+			arg = name
+		}
+		return createFromLocal(p, arg)
 	}
 }
 
-// createFromPR handles creation from a PR URL or number.
-func createFromPR(value string) error {
-	Log.Infof("Fetching Pull Request info...\n")
-	args := []string{"pr", "view", value, "--json", "number,title,headRefName,url"}
-	stdout, stderr, err := gh.Exec(args...)
+// createFromPR handles creation from a PR URL or number. stackedOn is the PR
+// it is stacked on with --with-stack, or 0.
+func createFromPR(p *progress, value string, stackedOn int) error {
+	provider := providerFor(value)
+	prInfo, repo, err := provider.lookupPR(value)
 	if err != nil {
-		return fmt.Errorf("failed to fetch PR info: %w\n%s", err, stderr.String())
-	}
-
-	var prInfo struct {
-		Number      int    `json:"number"`
-		Title       string `json:"title"`
-		HeadRefName string `json:"headRefName"`
-		URL         string `json:"url"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &prInfo); err != nil {
-		return fmt.Errorf("failed to parse PR info: %w", err)
+		return err
 	}
 
-	repo, err := repository.Current()
-	if err != nil {
-		return err
+	remote := "origin"
+	plan := wt.PlanPR(prInfo, remote, mergeRefFlag)
+	if forkRemoteFlag {
+		if remote, err = forkRemote(prInfo, provider); err != nil {
+			return err
+		}
+		if remote != "origin" {
+			plan = wt.PlanForkPR(prInfo, remote)
+		}
 	}
 
-	branchName := prInfo.HeadRefName
+	branchName := plan.Branch
+	worktreeName := plan.Name
 	if branchFlag != "" {
+		if err := checkBranchFlag(); err != nil {
+			return err
+		}
 		branchName = branchFlag
 	}
-
-	worktreeName := fmt.Sprintf("pr_%d", prInfo.Number)
 	if nameFlag != "" {
 		worktreeName = nameFlag
 	}
@@ -141,45 +245,140 @@ func createFromPR(value string) error {
 		Number:       prInfo.Number,
 		BranchName:   branchName,
 		WorktreeName: worktreeName,
+		Provider:     recordedProvider(provider),
+		Title:        prInfo.Title,
+		BaseBranch:   prInfo.BaseRefName,
+		StackedOn:    stackedOn,
 	}
 
-	Log.Outf(logger.Green, "Creating worktree for PR #%d: %s\n", info.Number, prInfo.Title)
+	if prInfo.Title != "" {
+		Log.Outf(logger.Green, "Creating worktree for PR #%d: %s\n", info.Number, prInfo.Title)
+	} else {
+		Log.Outf(logger.Green, "Creating worktree for PR #%d\n", info.Number)
+	}
 
-	// Fetch the PR ref
-	prRef := fmt.Sprintf("refs/pull/%d/head", info.Number)
-	Log.Infof("Fetching PR #%d...\n", info.Number)
-	if err := git.Fetch(prRef); err != nil {
-		return fmt.Errorf("failed to fetch PR: %w", err)
+	fetch := func() error {
+		if remote != "origin" {
+			Log.Infof("Fetching PR #%d from %s...\n", info.Number, remote)
+			cfg, err := config.Get()
+			if err != nil {
+				return err
+			}
+			if err := git.Fetch(remote, git.FetchOptions{Prune: cfg.Fetch.Prune}, plan.Refspec); err != nil {
+				return fmt.Errorf("failed to fetch PR branch from %s: %w", remote, err)
+			}
+			return nil
+		}
+		Log.Infof("Fetching PR #%d...\n", info.Number)
+		if plan.RequireRef != "" {
+			exists, err := git.RemoteRefExists("origin", plan.RequireRef)
+			if err != nil {
+				return fmt.Errorf("failed to check for PR merge ref: %w", err)
+			}
+			if !exists {
+				return fmt.Errorf("PR #%d has no merge ref (%s); it may have conflicts with its base branch or be closed", info.Number, plan.RequireRef)
+			}
+		}
+		if err := fetchRefs(plan.Refspec); err != nil {
+			return fmt.Errorf("failed to fetch PR: %w", err)
+		}
+		return nil
 	}
 
-	return createWorktree(info, "FETCH_HEAD")
+	return createWorktree(p, info, plan.StartPoint, plan.Upstream, fetch)
 }
 
-// createFromIssue handles creation from an Issue URL or number.
-func createFromIssue(value string) error {
-	Log.Infof("Fetching Issue info...\n")
-	args := []string{"issue", "view", value, "--json", "number,title,url"}
-	stdout, stderr, err := gh.Exec(args...)
+// forkRemote returns the remote to fetch the head branch of pr from with
+// --fork-remote: a remote named after the fork's owner, added when missing.
+// Pull requests from branches of the repository itself use origin.
+func forkRemote(pr wt.PullRequest, p provider) (string, error) {
+	if gitOnly() || p.name() != config.ProviderGitHub {
+		return "", fmt.Errorf("--fork-remote looks up the fork with the GitHub API and cannot be used with --git-only or %s", p.name())
+	}
+	if !pr.IsCrossRepository {
+		Log.Infof("PR #%d is not from a fork; fetching its branch from origin\n", pr.Number)
+		return "origin", nil
+	}
+	owner, _, ok := strings.Cut(pr.HeadRepository, "/")
+	if !ok || pr.HeadRefName == "" {
+		return "", fmt.Errorf("the fork of PR #%d is unknown; it may have been deleted", pr.Number)
+	}
+
+	origin, err := git.RemoteURL("origin")
 	if err != nil {
-		return fmt.Errorf("failed to fetch Issue info: %w\n%s", err, stderr.String())
+		return "", err
+	}
+	forkURL := forkRemoteURL(origin, pr.HeadRepository)
+	if existing, err := git.RemoteURL(owner); err == nil {
+		if !sameRepoURL(existing, forkURL) {
+			return "", fmt.Errorf("remote '%s' already exists for %s, not the fork %s", owner, existing, pr.HeadRepository)
+		}
+		return owner, nil
 	}
+	Log.Infof("Adding remote '%s' for %s\n", owner, forkURL)
+	if err := git.AddRemote(owner, forkURL); err != nil {
+		return "", fmt.Errorf("failed to add remote for %s: %w", pr.HeadRepository, err)
+	}
+	return owner, nil
+}
 
-	var issueInfo struct {
-		Number int    `json:"number"`
-		Title  string `json:"title"`
-		URL    string `json:"url"`
+// forkRemoteURL returns the URL of the repository repo (owner/name) on the
+// host of origin, using the same protocol as origin: SSH or HTTPS.
+func forkRemoteURL(origin, repo string) string {
+	if u, err := url.Parse(origin); err == nil && u.Scheme != "" && u.Host != "" {
+		u.Path = "/" + repo + ".git"
+		return u.String()
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &issueInfo); err != nil {
-		return fmt.Errorf("failed to parse issue info: %w", err)
+	if host, _, ok := strings.Cut(origin, ":"); ok {
+		return host + ":" + repo + ".git"
 	}
+	return "https://github.com/" + repo + ".git"
+}
 
-	repo, err := repository.Current()
+// sameRepoURL reports whether two remote URLs name the same repository,
+// ignoring a trailing .git or slash and case.
+func sameRepoURL(a, b string) bool {
+	trim := func(s string) string {
+		return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git"))
+	}
+	return trim(a) == trim(b)
+}
+
+// fetchRefs fetches refspec from origin with the fetch options from the
+// config, followed by the configured additional remotes.
+func fetchRefs(refspec string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	opts := git.FetchOptions{Prune: cfg.Fetch.Prune, Tags: cfg.Fetch.Tags}
+	if err := git.Fetch("origin", opts, append([]string{refspec}, cfg.Fetch.Refspecs...)...); err != nil {
+		return err
+	}
+	for _, remote := range cfg.Fetch.Remotes {
+		if remote == "origin" {
+			continue
+		}
+		if err := git.Fetch(remote, git.FetchOptions{Prune: cfg.Fetch.Prune}); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", remote, err)
+		}
+	}
+	return nil
+}
+
+// createFromIssue handles creation from an Issue URL or number.
+func createFromIssue(p *progress, value string) error {
+	provider := providerFor(value)
+	issueInfo, repo, err := provider.lookupIssue(value)
 	if err != nil {
 		return err
 	}
 
 	branchName := fmt.Sprintf("issue_%d", issueInfo.Number)
 	if branchFlag != "" {
+		if err := checkBranchFlag(); err != nil {
+			return err
+		}
 		branchName = branchFlag
 	}
 	worktreeName := branchName
@@ -194,17 +393,207 @@ func createFromIssue(value string) error {
 		Number:       issueInfo.Number,
 		BranchName:   branchName,
 		WorktreeName: worktreeName,
+		Provider:     recordedProvider(provider),
+		Title:        issueInfo.Title,
+	}
+
+	if issueInfo.Title != "" {
+		Log.Outf(logger.Green, "Creating worktree for Issue #%d: %s\n", info.Number, issueInfo.Title)
+	} else {
+		Log.Outf(logger.Green, "Creating worktree for Issue #%d\n", info.Number)
+	}
+
+	return createWorktree(p, info, startPointFlag, nil, nil)
+}
+
+// lookupPR returns the pull request for a PR URL or number and the repository
+// it belongs to. In git-only mode GitHub is not queried, so only the number is
+// known.
+func lookupPR(value string) (wt.PullRequest, repository.Repository, error) {
+	if gitOnly() {
+		number, repo, err := gitOnlyRef(value, worktree.PR)
+		return wt.PullRequest{Number: number}, repo, err
+	}
+
+	Log.Infof("Fetching Pull Request info...\n")
+	args := []string{"pr", "view", value, "--json", "number,title,headRefName,baseRefName,isCrossRepository,headRepository,headRepositoryOwner,url"}
+	stdout, stderr, err := ghExec(args...)
+	if err != nil {
+		return wt.PullRequest{}, repository.Repository{}, fmt.Errorf("failed to fetch PR info (use --git-only to skip the GitHub API): %w\n%s", err, stderr.String())
+	}
+
+	var prInfo struct {
+		Number      int    `json:"number"`
+		Title       string `json:"title"`
+		HeadRefName string `json:"headRefName"`
+		BaseRefName string `json:"baseRefName"`
+		// IsCrossRepository is true when the PR head lives in a fork.
+		IsCrossRepository bool `json:"isCrossRepository"`
+		HeadRepository    struct {
+			Name string `json:"name"`
+		} `json:"headRepository"`
+		HeadRepositoryOwner struct {
+			Login string `json:"login"`
+		} `json:"headRepositoryOwner"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &prInfo); err != nil {
+		return wt.PullRequest{}, repository.Repository{}, fmt.Errorf("failed to parse PR info: %w", err)
+	}
+
+	repo, err := repository.Current()
+	if err != nil {
+		return wt.PullRequest{}, repository.Repository{}, err
+	}
+	pr := wt.PullRequest{
+		Number:            prInfo.Number,
+		Title:             prInfo.Title,
+		HeadRefName:       prInfo.HeadRefName,
+		BaseRefName:       prInfo.BaseRefName,
+		IsCrossRepository: prInfo.IsCrossRepository,
+	}
+	if prInfo.HeadRepositoryOwner.Login != "" && prInfo.HeadRepository.Name != "" {
+		pr.HeadRepository = prInfo.HeadRepositoryOwner.Login + "/" + prInfo.HeadRepository.Name
+	}
+	return pr, repo, nil
+}
+
+// branchPR is an open pull request found by its head branch.
+type branchPR struct {
+	Number              int    `json:"number"`
+	Title               string `json:"title"`
+	HeadRepositoryOwner struct {
+		Login string `json:"login"`
+	} `json:"headRepositoryOwner"`
+}
+
+// prForBranch returns the number of the open pull request of the current
+// repository whose head is branch, given as branch or owner:branch to pick
+// among forks with the same branch name. Several matches are offered for
+// selection.
+func prForBranch(branch string) (int, error) {
+	if gitOnly() {
+		return 0, fmt.Errorf("--pr-branch looks up the PR with the GitHub API and cannot be used with --git-only; use --pr <number>")
+	}
+	if p := providerFor(""); p.name() != config.ProviderGitHub {
+		return 0, fmt.Errorf("--pr-branch is not supported for %s; use --pr <number>", p.name())
+	}
+
+	owner, head, ok := strings.Cut(branch, ":")
+	if !ok {
+		owner, head = "", branch
+	}
+	Log.Infof("Looking up the open PR of branch '%s'...\n", branch)
+	stdout, stderr, err := ghExec("pr", "list", "--head", head, "--state", "open", "--limit", "20",
+		"--json", "number,title,headRepositoryOwner")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the PR of branch '%s': %w: %s", branch, err, strings.TrimSpace(stderr.String()))
+	}
+	var prs []branchPR
+	if err := json.Unmarshal(stdout.Bytes(), &prs); err != nil {
+		return 0, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	return pickBranchPR(prs, owner, branch)
+}
+
+// pickBranchPR returns the number of the pull request in prs whose head
+// belongs to owner, or to anyone when owner is empty. Several matches are
+// offered for selection, or listed in the error without a terminal.
+func pickBranchPR(prs []branchPR, owner, branch string) (int, error) {
+	var matches []branchPR
+	for _, pr := range prs {
+		if owner == "" || strings.EqualFold(pr.HeadRepositoryOwner.Login, owner) {
+			matches = append(matches, pr)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no open PR has head branch '%s'", branch)
+	case 1:
+		Log.Infof("Found PR #%d: %s\n", matches[0].Number, matches[0].Title)
+		return matches[0].Number, nil
+	}
+
+	options := make([]string, len(matches))
+	for i, pr := range matches {
+		options[i] = fmt.Sprintf("#%d %s (%s:%s)", pr.Number, pr.Title, pr.HeadRepositoryOwner.Login, strings.TrimPrefix(branch, owner+":"))
+	}
+	if forceFlag || !term.IsTerminal(os.Stdin) {
+		return 0, fmt.Errorf("several open PRs have head branch '%s'; use owner:branch or --pr:\n  %s", branch, strings.Join(options, "\n  "))
+	}
+	idx, err := newPrompter(promptOut()).Select(fmt.Sprintf("Several open PRs have head branch '%s'. Select one:", branch), options[0], options)
+	if err != nil {
+		return 0, fmt.Errorf("prompt failed: %w", err)
+	}
+	return matches[idx].Number, nil
+}
+
+// lookupIssue returns the issue for an issue URL or number and the repository
+// it belongs to. In git-only mode GitHub is not queried, so only the number is
+// known.
+func lookupIssue(value string) (wt.Issue, repository.Repository, error) {
+	if gitOnly() {
+		number, repo, err := gitOnlyRef(value, worktree.Issue)
+		return wt.Issue{Number: number}, repo, err
+	}
+
+	Log.Infof("Fetching Issue info...\n")
+	args := []string{"issue", "view", value, "--json", "number,title,url"}
+	stdout, stderr, err := ghExec(args...)
+	if err != nil {
+		return wt.Issue{}, repository.Repository{}, fmt.Errorf("failed to fetch Issue info (use --git-only to skip the GitHub API): %w\n%s", err, stderr.String())
+	}
+
+	var issueInfo struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		URL    string `json:"url"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &issueInfo); err != nil {
+		return wt.Issue{}, repository.Repository{}, fmt.Errorf("failed to parse issue info: %w", err)
+	}
+
+	repo, err := repository.Current()
+	if err != nil {
+		return wt.Issue{}, repository.Repository{}, err
 	}
+	return wt.Issue{Number: issueInfo.Number, Title: issueInfo.Title}, repo, nil
+}
 
-	Log.Outf(logger.Green, "Creating worktree for Issue #%d: %s\n", info.Number, issueInfo.Title)
+// gitOnly reports whether GitHub should not be queried (--git-only or git_only).
+func gitOnly() bool {
+	cfg, err := config.Get()
+	return err == nil && cfg.GitOnly
+}
+
+// gitOnlyRef parses a PR or issue URL or number without querying GitHub. The
+// repository comes from the git remotes and must match the URL, since the
+// pull request is fetched from origin.
+func gitOnlyRef(value string, typ worktree.WorktreeType) (int, repository.Repository, error) {
+	ref, ok := parseWorktreeRef(value)
+	if !ok || (ref.Type != "" && ref.Type != typ) {
+		return 0, repository.Repository{}, fmt.Errorf("--git-only needs a %s number or URL, got '%s'", typ, value)
+	}
 
-	return createWorktree(info, startPointFlag)
+	owner, name, err := currentRepo()
+	if err != nil {
+		return 0, repository.Repository{}, err
+	}
+	repo := repository.Repository{Host: "github.com", Owner: owner, Name: name}
+	if ref.Owner != "" {
+		if owner != "" && !strings.EqualFold(ref.Owner+"/"+ref.Repo, owner+"/"+name) {
+			return 0, repository.Repository{}, fmt.Errorf("%s belongs to %s/%s, but this repository is %s/%s", value, ref.Owner, ref.Repo, owner, name)
+		}
+		repo.Owner, repo.Name = ref.Owner, ref.Repo
+	}
+	return ref.Number, repo, nil
 }
 
 // createFromLocal handles creation from a local branch name.
-func createFromLocal(name string) error {
+func createFromLocal(p *progress, name string) error {
 	if !git.IsGitRepository(".") {
-		return fmt.Errorf("not in a git repository")
+		return git.ErrNotARepo
 	}
 
 	// Get repo name using the shared helper
@@ -213,21 +602,33 @@ func createFromLocal(name string) error {
 		return err
 	}
 
+	if nameFlag != "" {
+		name = nameFlag
+	}
+	if name, err = normalizeName(name); err != nil {
+		return err
+	}
+
 	// Branch name: --branch > --name > argument
 	branchName := name
-	if branchFlag != "" {
-		branchName = branchFlag
-	} else if nameFlag != "" {
-		branchName = nameFlag
-	}
 
 	// Worktree name: --name > argument
 	worktreeName := name
-	if nameFlag != "" {
-		worktreeName = nameFlag
-	}
 
-	branchName = SanitizeBranchName(branchName)
+	// An explicit --branch must already be valid; a name derived from the
+	// argument is fixed up where git would reject it, e.g. "fix: login" becomes
+	// "fix_login" while "feature/auth.v2" is kept.
+	if branchFlag != "" {
+		if err := checkBranchFlag(); err != nil {
+			return err
+		}
+		branchName = branchFlag
+	} else if err := git.CheckBranchName(branchName); err != nil {
+		branchName, err = promptBranchName(branchName, err, worktreeName)
+		if err != nil {
+			return err
+		}
+	}
 
 	info := &worktree.WorktreeInfo{
 		Type:         worktree.Local,
@@ -236,10 +637,13 @@ func createFromLocal(name string) error {
 		WorktreeName: worktreeName,
 	}
 
-	return createWorktree(info, startPointFlag)
+	return createWorktree(p, info, startPointFlag, nil, nil)
 }
 
-func createWorktree(info *worktree.WorktreeInfo, startPoint string) error {
+// createWorktree creates the worktree for info from startPoint, reporting
+// each step to p. fetch, when set, fetches startPoint before anything else.
+// When track is set, the new branch is configured to track it.
+func createWorktree(p *progress, info *worktree.WorktreeInfo, startPoint string, track *wt.Upstream, fetch func() error) error {
 	cfg, err := config.Get()
 	if err != nil {
 		return err
@@ -247,80 +651,347 @@ func createWorktree(info *worktree.WorktreeInfo, startPoint string) error {
 	baseDir := cfg.WorktreeBase
 	worktreePath := filepath.Join(baseDir, info.Repo, info.WorktreeName)
 	absPath, _ := filepath.Abs(worktreePath)
+	Log.Worktree = absPath
+	p.report.Worktree = absPath
+	p.report.Branch = info.BranchName
+	p.report.Type = string(info.Type)
+	p.report.Number = info.Number
 
-	branchExists := git.BranchExists(info.BranchName)
-	worktreeDirExists := worktree.Exists(worktreePath)
-	worktreeGitRegistered := git.WorktreeIsRegistered(worktreePath)
+	repoLock, err := lockRepoDir(filepath.Dir(worktreePath))
+	if err != nil {
+		return err
+	}
+	defer unlockRepoDir(repoLock)
 
-	hasConflict := worktreeDirExists || worktreeGitRegistered || branchExists
+	if err := prepareRepo(); err != nil {
+		return err
+	}
 
-	if hasConflict {
+	if fetch != nil {
+		if err := p.run(stepFetch, fetch); err != nil {
+			return err
+		}
+	} else {
+		p.skip(stepFetch)
+	}
+
+	conflicts := lifecycle.FindConflicts(context.Background(), git.Default(), worktreePath, info.BranchName)
+	if conflicts.Any() {
+		if err := lifecycle.CheckOverwrite(context.Background(), git.Default(), absPath, info.BranchName, conflicts, forceFlag); err != nil {
+			if errors.Is(err, lifecycle.ErrProtectedBranch) {
+				return fmt.Errorf("branch '%s' already exists and is a default branch; refusing to delete it to overwrite (use --force)", info.BranchName)
+			}
+			return pinnedError(err, absPath)
+		}
 		if !forceFlag {
-			message := buildConflictMessage(info, absPath, worktreePath, worktreeDirExists, worktreeGitRegistered, branchExists)
-			p := prompter.New(os.Stdin, os.Stdout, os.Stderr)
+			openPR := 0
+			if conflicts.BranchExists {
+				openPR = openPullRequest(cfg, info)
+			}
+			message := buildConflictMessage(info, absPath, worktreePath, conflicts, openPR)
+			p := newPrompter(promptOut())
 			overwrite, err := p.Confirm(message, false)
 			if err != nil {
 				return fmt.Errorf("failed to read confirmation: %w", err)
 			}
+			if overwrite && openPR != 0 {
+				overwrite, err = p.Confirm(fmt.Sprintf("Branch '%s' has open PR #%d. Really delete it?", info.BranchName, openPR), false)
+				if err != nil {
+					return fmt.Errorf("failed to read confirmation: %w", err)
+				}
+			}
 			if !overwrite {
 				Log.Warnf("Cancelled - no changes made\n")
 				return nil
 			}
 		}
 
-		if err := performCleanup(worktreePath, worktreeDirExists, worktreeGitRegistered, branchExists, info.BranchName); err != nil {
+		err := p.run(stepCleanup, func() error {
+			if conflicts.BranchExists {
+				Log.Infof("Deleting existing branch '%s'...\n", info.BranchName)
+			}
+			return lifecycle.Clear(context.Background(), git.Default(), worktreePath, info.BranchName, conflicts)
+		})
+		if err != nil {
 			return err
 		}
+	} else {
+		p.skip(stepCleanup)
 	}
 
-	err = worktree.Create(worktreePath, info.BranchName, startPoint)
-	if err != nil {
-		if worktree.Exists(worktreePath) {
-			os.RemoveAll(worktreePath)
+	err = p.run(stepWorktreeAdd, func() error {
+		if err := lifecycle.Add(context.Background(), git.Default(), worktreePath, info.BranchName, startPoint); err != nil {
+			return err
+		}
+		if _, err := lifecycle.AssignPorts(absPath); err != nil {
+			Log.Warnf("%v\n", err)
 		}
+		if track != nil {
+			if err := git.SetUpstream(info.BranchName, track.Remote, track.Merge); err != nil {
+				Log.Warnf("Failed to set upstream for branch '%s': %v\n", info.BranchName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
 	printSuccess(absPath)
+	if _, err := os.Stat(filepath.Join(absPath, ".gitmodules")); err == nil {
+		Log.Infof("This repository has submodules; run 'git submodule update --init --recursive' in the worktree to check them out\n")
+	}
+
+	now := time.Now()
+	if err := metadata.Record(metadata.Entry{
+		Path:         absPath,
+		Name:         info.WorktreeName,
+		Branch:       info.BranchName,
+		Type:         info.Type,
+		Owner:        info.Owner,
+		Repo:         info.Repo,
+		Number:       info.Number,
+		Provider:     info.Provider,
+		Title:        info.Title,
+		CreatedAt:    now,
+		LastUsedAt:   now,
+		UseCount:     1,
+		SetupSkipped: noVerifyFlag,
+		BaseBranch:   info.BaseBranch,
+		StackedOn:    info.StackedOn,
+	}); err != nil {
+		Log.Warnf("Failed to record worktree metadata: %v\n", err)
+	}
+	recordHistory(history.Event{
+		Op:       history.OpAdd,
+		Repo:     info.Repo,
+		Worktree: absPath,
+		Branch:   info.BranchName,
+		Detail:   historyDetail(info.Type, info.Number, info.Title),
+	})
+	clearCompletionCache()
+	syncWorkspace(baseDir, absPath)
+	unlockRepoDir(repoLock)
+
+	journal := &creationJournal{
+		BaseDir: baseDir,
+		Repo:    info.Repo,
+		Path:    absPath,
+		Branch:  info.BranchName,
+		Detail:  historyDetail(info.Type, info.Number, info.Title),
+	}
+
+	if noVerifyFlag {
+		Log.Infof("Skipping post-create setup (--no-verify)\n")
+		p.skip(stepPostCreate)
+	} else if err := p.run(stepPostCreate, func() error {
+		var errs []error
+		for _, hook := range []func() error{
+			func() error { return writeEnvFile(cfg.EnvFile, absPath, info) },
+			func() error { return writeEnvrc(cfg.Direnv, absPath, info) },
+			func() error { return applyIdentity(cfg.Identities, absPath, info) },
+		} {
+			if err := hook(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}); err != nil {
+		if rolledBack, rbErr := failedSetup(p, journal, err); rolledBack || rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+	}
+
+	updateGitHub(cfg, absPath, info)
+
+	if actionFlag != "" || len(cliArgv) > 0 {
+		err := p.run(stepAction, func() error {
+			return executePostCreation(actionFlag, cliArgv, absPath, info)
+		})
+		if err != nil {
+			if rolledBack, rbErr := failedSetup(p, journal, err); rolledBack || rbErr != nil {
+				return errors.Join(err, rbErr)
+			}
+		}
+	} else {
+		p.skip(stepAction)
+	}
+	if printPathFlag {
+		fmt.Fprintln(os.Stdout, absPath)
+	}
+	return nil
+}
+
+// failedSetup reports err, the failure of a post-create step, and offers to
+// roll back the worktree recorded in j. A kept worktree is not an error.
+func failedSetup(p *progress, j *creationJournal, err error) (bool, error) {
+	Log.Warnf("\n⚠️  %v\n", err)
+	rolledBack, rbErr := offerRollback(j)
+	p.report.RolledBack = rolledBack
+	return rolledBack, rbErr
+}
+
+// prepareRepo checks that a worktree of the current repository can be created
+// safely. Submodules are refused without --force since git's worktree support
+// for them is incomplete, and a core.worktree in the shared config is moved
+// out of the way of the new worktree.
+func prepareRepo() error {
+	support, err := git.CheckWorktreeSupport("")
+	if err != nil {
+		return fmt.Errorf("failed to check worktree support: %w", err)
+	}
+	if support.Submodule && !forceFlag {
+		return fmt.Errorf("this repository is a submodule, and git's worktree support for submodules is incomplete: the new worktree will not be registered in the superproject (use --force to create it anyway)")
+	}
+	if support.SharedCoreWorktree {
+		Log.Infof("Enabling extensions.worktreeConfig so core.worktree does not apply to the new worktree...\n")
+		if err := git.EnableWorktreeConfig(""); err != nil {
+			return fmt.Errorf("repository sets core.worktree, which would apply to every worktree, and enabling extensions.worktreeConfig to move it failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// updateGitHub reflects the new worktree at worktreePath on GitHub according
+// to config. Failures are reported as warnings since the worktree already
+// exists.
+func updateGitHub(cfg config.Config, worktreePath string, info *worktree.WorktreeInfo) {
+	if info.Type == worktree.Local || info.Provider != "" || cfg.GitOnly {
+		return
+	}
+
+	if info.Type == worktree.Issue && cfg.Issue.Assign {
+		Log.Infof("Assigning issue #%d to you...\n", info.Number)
+		if err := assignIssue(info); err != nil {
+			Log.Warnf("Failed to assign issue #%d: %v\n", info.Number, err)
+		}
+	}
+
+	if info.Type == worktree.Issue && cfg.Issue.Project.Number != 0 {
+		project := cfg.Issue.Project
+		if project.Owner == "" {
+			project.Owner = info.Owner
+		}
+		Log.Infof("Setting project %s/%d %s to '%s'...\n", project.Owner, project.Number, project.Field, project.Value)
+		if err := github.SetProjectStatus(context.Background(), github.ProjectStatusOptions{
+			Owner:         info.Owner,
+			Repo:          info.Repo,
+			Number:        info.Number,
+			ProjectOwner:  project.Owner,
+			ProjectNumber: project.Number,
+			Field:         project.Field,
+			Value:         project.Value,
+		}); err != nil {
+			Log.Warnf("Failed to update project status for issue #%d: %v\n", info.Number, err)
+		}
+	}
+
+	if cfg.StartComment != "" {
+		Log.Infof("Commenting on %s #%d...\n", info.Type, info.Number)
+		if err := postStartComment(cfg.StartComment, worktreePath, info); err != nil {
+			Log.Warnf("Failed to comment on %s #%d: %v\n", info.Type, info.Number, err)
+		}
+	}
+}
+
+// postStartComment renders the start_comment template and posts it to the
+// worktree's issue or pull request.
+func postStartComment(commentTemplate, worktreePath string, info *worktree.WorktreeInfo) error {
+	body, err := renderStartComment(commentTemplate, worktreePath, info)
+	if err != nil {
+		return err
+	}
+
+	kind := "issue"
+	if info.Type == worktree.PR {
+		kind = "pr"
+	}
+	_, stderr, err := ghExecOnce(kind, "comment", strconv.Itoa(info.Number),
+		"--repo", info.Owner+"/"+info.Repo, "--body", body)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// renderStartComment renders the start_comment template with the fields of
+// action templates for the worktree at worktreePath.
+func renderStartComment(commentTemplate, worktreePath string, info *worktree.WorktreeInfo) (string, error) {
+	data, err := action.NewTemplateData(worktreePath, info)
+	if err != nil {
+		return "", err
+	}
+	body, err := action.Render("start_comment", commentTemplate, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render start_comment template: %w", err)
+	}
+	return body, nil
+}
+
+// assignIssue assigns the authenticated user to the worktree's issue.
+func assignIssue(info *worktree.WorktreeInfo) error {
+	_, stderr, err := ghExecOnce("issue", "edit", strconv.Itoa(info.Number),
+		"--repo", info.Owner+"/"+info.Repo, "--add-assignee", "@me")
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
 
-	return executePostCreation(actionFlag, cliArgs, absPath, info)
+// openPullRequest returns the open pull request on GitHub whose head is the
+// branch of info, or 0 if there is none or it cannot be looked up.
+func openPullRequest(cfg config.Config, info *worktree.WorktreeInfo) int {
+	if cfg.GitOnly || info.Provider != "" || info.Owner == "" {
+		return 0
+	}
+	pr, err := branchPullRequest(info.Owner, info.Repo, info.BranchName, "open")
+	if err != nil {
+		Log.Debugf("Failed to look up open PRs of '%s': %v\n", info.BranchName, err)
+		return 0
+	}
+	return pr
 }
 
-func buildConflictMessage(info *worktree.WorktreeInfo, absPath, worktreePath string, worktreeDirExists, worktreeGitRegistered, branchExists bool) string {
+// buildConflictMessage describes the cleanup needed to create the worktree of
+// info, for the overwrite prompt. openPR is the open pull request whose head
+// is the existing branch, or 0.
+func buildConflictMessage(info *worktree.WorktreeInfo, absPath, worktreePath string, c lifecycle.Conflicts, openPR int) string {
 	var message strings.Builder
 
 	fmt.Fprintf(&message, "Target: create worktree for '%s'\n\nThis will:\n", info.BranchName)
 
 	currentBranch := ""
-	if worktreeGitRegistered {
+	if c.Registered {
 		currentBranch, _ = git.GetWorktreeBranch(worktreePath)
 	}
 
-	if worktreeDirExists && worktreeGitRegistered {
+	if c.DirExists && c.Registered {
 		if currentBranch != "" {
 			fmt.Fprintf(&message, "- Remove worktree at %s (currently on branch '%s')\n", absPath, currentBranch)
 		} else {
 			fmt.Fprintf(&message, "- Remove worktree at %s\n", absPath)
 		}
-	} else if worktreeGitRegistered {
+	} else if c.Registered {
 		fmt.Fprintf(&message, "- Remove stale worktree record at %s\n", absPath)
-	} else if worktreeDirExists {
+	} else if c.DirExists {
 		fmt.Fprintf(&message, "- Remove directory at %s\n", absPath)
 	}
 
-	if branchExists {
+	if c.BranchExists && openPR != 0 {
+		fmt.Fprintf(&message, "- Delete existing branch '%s' (branch has open PR #%d)\n", info.BranchName, openPR)
+	} else if c.BranchExists {
 		fmt.Fprintf(&message, "- Delete existing branch '%s'\n", info.BranchName)
 	}
 
 	fmt.Fprintf(&message, "- Create worktree and branch for '%s'\n", info.BranchName)
 
-	if worktreeDirExists && git.IsGitRepository(worktreePath) {
+	if c.DirExists && git.IsGitRepository(worktreePath) {
 		if git.HasUncommittedChanges(worktreePath) {
 			message.WriteString(fmt.Sprintf("\n⚠️  WARNING: Worktree at %s has uncommitted changes that will be PERMANENTLY DELETED. Consider committing or stashing changes first.\n", absPath))
 		}
 	}
 
-	if branchExists {
+	if c.BranchExists {
 		worktrees, err := git.GetWorktreeInfo()
 		if err == nil {
 			for _, wt := range worktrees {
@@ -338,76 +1009,113 @@ func buildConflictMessage(info *worktree.WorktreeInfo, absPath, worktreePath str
 	return message.String()
 }
 
-func performCleanup(worktreePath string, worktreeDirExists, worktreeGitRegistered, branchExists bool, branchName string) error {
-	if worktreeDirExists && worktreeGitRegistered {
-		if err := git.WorktreeRemove(worktreePath, true); err != nil {
-			return fmt.Errorf("failed to remove worktree: %w", err)
-		}
-	} else if worktreeDirExists {
-		if err := os.RemoveAll(worktreePath); err != nil {
-			return fmt.Errorf("failed to remove directory: %w", err)
-		}
-	} else if worktreeGitRegistered {
-		if err := git.WorktreePrune(); err != nil {
-			return fmt.Errorf("failed to prune worktree: %w", err)
-		}
-	}
-
-	if branchExists {
-		Log.Infof("Deleting existing branch '%s'...\n", branchName)
-		if err := git.BranchDelete(branchName, true); err != nil {
-			return fmt.Errorf("failed to delete branch: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func executePostCreation(actionFlag, cliArgs, absPath string, info *worktree.WorktreeInfo) error {
+// executePostCreation runs the action named actionFlag, or else the command
+// given by argv, in the new worktree.
+func executePostCreation(actionFlag string, argv []string, absPath string, info *worktree.WorktreeInfo) error {
 	if actionFlag != "" {
 		if err := action.Execute(context.Background(), &action.ExecuteOptions{
 			ActionName:   actionFlag,
 			WorktreePath: absPath,
 			Info:         info,
-			CLIArgs:      cliArgs,
+			CLIArgs:      strings.Join(argv, " "),
+			Args:         argv,
 			Logger:       Log,
 			Stdin:        os.Stdin,
-			Stdout:       os.Stdout,
+			Stdout:       Log.Stdout,
 			Stderr:       os.Stderr,
 			Env:          os.Environ(),
 		}); err != nil {
-			Log.Warnf("\n⚠️  Action '%s' failed: %v\n", actionFlag, err)
+			return err
 		}
-	} else if cliArgs != "" {
-		Log.Outf(logger.Magenta, "\nRunning in worktree: %s\n", cliArgs)
+	} else if len(argv) > 0 {
+		command := directCommand(argv)
+		Log.Outf(logger.Magenta, "\nRunning in worktree: %s\n", command)
 
 		if err := execext.RunCommand(context.Background(), &execext.RunCommandOptions{
-			Command: cliArgs,
+			Command: command,
 			Dir:     absPath,
-			Env:     os.Environ(),
+			Env:     worktreeEnv(absPath, info),
 			Stdin:   os.Stdin,
-			Stdout:  os.Stdout,
+			Stdout:  Log.Stdout,
 			Stderr:  os.Stderr,
 		}); err != nil {
-			Log.Warnf("\n⚠️  Command '%s' failed: %v\n", cliArgs, err)
+			return fmt.Errorf("command '%s' failed: %w", command, err)
 		}
 	}
 
 	return nil
 }
 
+// normalizeName returns the name given to gh wt add with its non-ASCII
+// characters handled as unicode_names says, telling the user when it changed.
+func normalizeName(name string) (string, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		return "", err
+	}
+	normalized := worktree.NormalizeName(name, cfg.UnicodeNames == config.NamesTransliterate)
+	if normalized != worktree.NormalizeName(name, false) {
+		Log.Infof("Using name '%s' (transliterated from '%s')\n", normalized, name)
+	}
+	return normalized, nil
+}
+
+// checkBranchFlag rejects a --branch that git would not accept as a branch
+// name, suggesting a name it would.
+func checkBranchFlag() error {
+	if err := git.CheckBranchName(branchFlag); err != nil {
+		return fmt.Errorf("%w (try --branch %q)", err, git.SanitizeBranchName(branchFlag))
+	}
+	return nil
+}
+
+// promptBranchName shows why original is not a valid branch name along with the
+// proposed fix, and lets the user edit it before creation. Without a terminal,
+// or with --force, the proposed name is used as-is.
+func promptBranchName(original string, reason error, worktreeName string) (string, error) {
+	proposed := git.SanitizeBranchName(original)
+	if forceFlag || !term.IsTerminal(os.Stdin) {
+		Log.Infof("Using branch name '%s' instead of '%s' (%v)\n", proposed, original, reason)
+		return proposed, nil
+	}
+
+	p := newPrompter(promptOut())
+	message := fmt.Sprintf("%v. Branch name for worktree '%s':", reason, worktreeName)
+	answer, err := p.Input(message, proposed)
+	if err != nil {
+		return "", fmt.Errorf("failed to read branch name: %w", err)
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return proposed, nil
+	}
+	if err := git.CheckBranchName(answer); err != nil {
+		sanitized := git.SanitizeBranchName(answer)
+		Log.Warnf("Using branch name '%s' instead of '%s' (%v)\n", sanitized, answer, err)
+		return sanitized, nil
+	}
+	return answer, nil
+}
+
+// promptOut returns where prompts are written: stderr when stdout is reserved
+// for a worktree path or report, as with add --print-path or --json and
+// switch, and stdout otherwise.
+func promptOut() *os.File {
+	if printPathFlag || addJSONFlag || stdoutReserved {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 // printSuccess prints the final success message.
 func printSuccess(path string) {
 	Log.Outf(logger.Green, "\nWorktree created successfully!\n")
 	Log.Outf(logger.Default, "Location: %s\n", path)
 	Log.Outf(logger.Default, "\nTo switch to the worktree:\n")
 	Log.Outf(logger.Cyan, "  cd %s\n", path)
-}
-
-// SanitizeBranchName is moved from types.go.
-func SanitizeBranchName(name string) string {
-	invalidChars := regexp.MustCompile(`[^a-zA-Z0-9_-]`)
-	return invalidChars.ReplaceAllString(name, "_")
+	Log.Outf(logger.Default, "or, with shell integration (gh wt init --help):\n")
+	Log.Outf(logger.Cyan, "  gh wt switch %s\n", getWorktreeDisplayName(path))
 }
 
 // DetermineWorktreeType determines the type of worktree based on the input
@@ -436,14 +1144,29 @@ func DetermineWorktreeType(input string) (worktree.WorktreeType, error) {
 		return worktree.Issue, nil
 	}
 
+	if ref, ok := gitlab.ParseURL(u); ok {
+		if ref.Kind == gitlab.KindMergeRequest {
+			return worktree.PR, nil
+		}
+		return worktree.Issue, nil
+	}
+
 	return worktree.Local, nil
 }
 
 var (
 	prFlag         string
 	issueFlag      string
+	prBranchFlag   string
+	forkRemoteFlag bool
+	withStackFlag  bool
 	branchFlag     string
 	actionFlag     string
 	startPointFlag string
 	nameFlag       string
+	assignFlag     bool
+	mergeRefFlag   bool
+	printPathFlag  bool
+	addJSONFlag    bool
+	noVerifyFlag   bool
 )
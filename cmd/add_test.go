@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/git/gittest"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConflictMessageOpenPR(t *testing.T) {
+	t.Cleanup(git.SetRunner(&gittest.Fake{}))
+	info := &worktree.WorktreeInfo{BranchName: "fix-login"}
+
+	message := buildConflictMessage(info, "/wt/repo/fix-login", "/wt/repo/fix-login", lifecycle.Conflicts{BranchExists: true}, 12)
+	assert.Contains(t, message, "- Delete existing branch 'fix-login' (branch has open PR #12)\n")
+
+	message = buildConflictMessage(info, "/wt/repo/fix-login", "/wt/repo/fix-login", lifecycle.Conflicts{BranchExists: true}, 0)
+	assert.Contains(t, message, "- Delete existing branch 'fix-login'\n")
+}
+
+func TestPickBranchPR(t *testing.T) {
+	Log = logger.NewLogger(false, false, false)
+	t.Cleanup(func() { Log = nil })
+	pr := func(number int, owner string) branchPR {
+		p := branchPR{Number: number, Title: "Fix login"}
+		p.HeadRepositoryOwner.Login = owner
+		return p
+	}
+	prs := []branchPR{pr(12, "alice"), pr(15, "bob")}
+
+	n, err := pickBranchPR(prs[:1], "", "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, 12, n)
+
+	n, err = pickBranchPR(prs, "Bob", "Bob:feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, 15, n)
+
+	_, err = pickBranchPR(nil, "", "feature/login")
+	assert.EqualError(t, err, "no open PR has head branch 'feature/login'")
+
+	// Without a terminal, several matches are listed instead of prompted for.
+	_, err = pickBranchPR(prs, "", "feature/login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "#15 Fix login (bob:feature/login)")
+}
+
+func TestForkRemoteURL(t *testing.T) {
+	assert.Equal(t, "git@github.com:alice/repo.git", forkRemoteURL("git@github.com:owner/repo.git", "alice/repo"))
+	assert.Equal(t, "https://github.com/alice/repo.git", forkRemoteURL("https://github.com/owner/repo", "alice/repo"))
+	assert.Equal(t, "ssh://git@ghe.example.com/alice/repo.git", forkRemoteURL("ssh://git@ghe.example.com/owner/repo.git", "alice/repo"))
+
+	assert.True(t, sameRepoURL("https://github.com/Alice/repo", "https://github.com/alice/repo.git"))
+	assert.False(t, sameRepoURL("https://github.com/bob/repo.git", "https://github.com/alice/repo.git"))
+}
+
+func TestRenderStartComment(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Cleanup(git.SetRunner(&gittest.Fake{Responses: map[string]gittest.Response{
+		"worktree list --porcelain": {Stdout: "worktree /src/repo\nHEAD 1111\nbranch refs/heads/main\n"},
+	}}))
+	path := t.TempDir()
+	_, err := ports.Assign(path, config.PortsConfig{})
+	require.NoError(t, err)
+	info := &worktree.WorktreeInfo{Type: worktree.Issue, Owner: "octo", Repo: "repo", Number: 7, BranchName: "issue_7"}
+
+	body, err := renderStartComment("Started `{{.BranchName}}` for #{{.Number}} in {{.WorktreeName}} of {{.ROOT_DIR}} on port {{.Port}}", path, info)
+	require.NoError(t, err)
+	assert.Equal(t, "Started `issue_7` for #7 in "+filepath.Base(path)+" of /src/repo on port 4000", body)
+
+	_, err = renderStartComment("{{.Missing}}", path, info)
+	assert.ErrorContains(t, err, "failed to render start_comment template")
+}
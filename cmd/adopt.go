@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptMoveFlag bool
+	adoptNameFlag string
+	adoptAllFlag  bool
+)
+
+// adoptCmd represents the adopt command.
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [path]",
+	Short: "Manage worktrees created with git worktree add",
+	Long: heredoc.Doc(`
+		Adopt worktrees of the current repository that were created outside
+		gh wt, e.g. with a raw git worktree add, so that gh wt list, run, and
+		rm work on them.
+
+		Without a path, the worktrees outside the worktree directory are
+		offered for selection (all of them with --all). Adopted worktrees are
+		recorded in the worktree metadata as local worktrees.
+
+		With --move the worktree is also moved into the worktree directory
+		(<worktree_dir>/<repo>/<name>), so it is included in gh wt list --all
+		and --tree.
+	`),
+	Example: heredoc.Doc(`
+		# Pick a worktree to adopt
+		gh wt adopt
+
+		# Adopt a worktree by path
+		gh wt adopt ../repo-hotfix
+
+		# Adopt it and move it into the worktree directory as "hotfix"
+		gh wt adopt ../repo-hotfix --move --name hotfix
+
+		# Adopt every worktree created outside gh wt
+		gh wt adopt --all
+	`),
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runAdopt,
+	GroupID: "worktrees",
+}
+
+func init() {
+	adoptCmd.Flags().BoolVar(&adoptMoveFlag, "move", false, "move the worktree into the worktree directory")
+	adoptCmd.Flags().StringVarP(&adoptNameFlag, "name", "n", "", "name of the worktree (default: its directory name)")
+	adoptCmd.Flags().BoolVar(&adoptAllFlag, "all", false, "adopt every worktree created outside gh wt")
+	adoptCmd.MarkFlagsMutuallyExclusive("all", "name")
+	rootCmd.AddCommand(adoptCmd)
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 && adoptAllFlag {
+		return fmt.Errorf("cannot use --all with a path")
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil {
+		return err
+	}
+	if len(worktrees) == 0 {
+		return git.ErrNotARepo
+	}
+	// The first worktree is the main one, named after the repository.
+	repo := filepath.Base(worktrees[0].Path)
+	store, err := metadata.Load()
+	if err != nil {
+		return fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+
+	var targets []git.WorktreeInfo
+	if len(args) > 0 {
+		wt, err := adoptTarget(worktrees, cfg.WorktreeBase, store, args[0])
+		if err != nil {
+			return err
+		}
+		targets = []git.WorktreeInfo{wt}
+	} else {
+		candidates := unmanagedWorktrees(worktrees, cfg.WorktreeBase, store)
+		switch {
+		case len(candidates) == 0:
+			Log.Warnf("No worktrees to adopt; every worktree of %s is managed by gh wt\n", repo)
+			return nil
+		case adoptAllFlag || len(candidates) == 1:
+			targets = candidates
+		default:
+			wt, err := selectWorktree("Select a worktree to adopt:", candidates)
+			if err != nil {
+				return err
+			}
+			targets = []git.WorktreeInfo{wt}
+		}
+	}
+
+	for _, wt := range targets {
+		if err := adoptWorktree(cfg, repo, wt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmanagedWorktrees returns the worktrees of the repository, other than the
+// main one, that gh wt does not manage.
+func unmanagedWorktrees(worktrees []git.WorktreeInfo, base string, store *metadata.Store) []git.WorktreeInfo {
+	if len(worktrees) == 0 {
+		return nil
+	}
+	managed := make(map[string]bool)
+	for _, wt := range filterManaged(worktrees, base, store) {
+		managed[wt.Path] = true
+	}
+	var unmanaged []git.WorktreeInfo
+	for _, wt := range worktrees[1:] {
+		if !managed[wt.Path] {
+			unmanaged = append(unmanaged, wt)
+		}
+	}
+	return unmanaged
+}
+
+// adoptTarget returns the worktree at path, which must be an unmanaged
+// worktree of the repository.
+func adoptTarget(worktrees []git.WorktreeInfo, base string, store *metadata.Store, path string) (git.WorktreeInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if len(worktrees) > 0 && worktrees[0].Path == abs {
+		return git.WorktreeInfo{}, fmt.Errorf("%s is the main worktree of the repository", abs)
+	}
+	for _, wt := range unmanagedWorktrees(worktrees, base, store) {
+		if wt.Path == abs {
+			return wt, nil
+		}
+	}
+	for _, wt := range worktrees {
+		if wt.Path == abs {
+			return git.WorktreeInfo{}, fmt.Errorf("%s is already managed by gh wt", abs)
+		}
+	}
+	return git.WorktreeInfo{}, fmt.Errorf("%s is not a worktree of this repository", abs)
+}
+
+// adoptWorktree records wt in the worktree metadata, first moving it into the
+// worktree directory with --move.
+func adoptWorktree(cfg config.Config, repo string, wt git.WorktreeInfo) error {
+	name := filepath.Base(wt.Path)
+	if adoptNameFlag != "" {
+		name = adoptNameFlag
+	}
+	path := wt.Path
+	Log.Worktree = path
+
+	if adoptMoveFlag {
+		dest := filepath.Join(cfg.WorktreeBase, repo, name)
+		repoLock, err := lockRepoDir(filepath.Dir(dest))
+		if err != nil {
+			return err
+		}
+		defer unlockRepoDir(repoLock)
+		if worktree.Exists(dest) {
+			return fmt.Errorf("cannot move %s: %s already exists", getTildePath(path), getTildePath(dest))
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := git.WorktreeMove(path, dest); err != nil {
+			return fmt.Errorf("failed to move worktree: %w", err)
+		}
+		path = dest
+		Log.Worktree = path
+	}
+
+	now := time.Now()
+	if err := metadata.Record(metadata.Entry{
+		Path:       path,
+		Name:       name,
+		Branch:     worktreeBranch(wt),
+		Type:       worktree.Local,
+		Repo:       repo,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		UseCount:   1,
+		Adopted:    true,
+	}); err != nil {
+		return fmt.Errorf("failed to record worktree metadata: %w", err)
+	}
+	recordHistory(history.Event{
+		Op:       history.OpAdopt,
+		Repo:     repo,
+		Worktree: path,
+		Branch:   worktreeBranch(wt),
+	})
+	clearCompletionCache()
+	if adoptMoveFlag {
+		syncWorkspace(cfg.WorktreeBase, path)
+	}
+
+	Log.Outf(logger.Green, "✓ Adopted %s\n", getTildePath(path))
+	return nil
+}
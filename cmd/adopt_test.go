@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmanagedWorktrees(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	require.NoError(t, metadata.Record(metadata.Entry{Path: "/src/repo-adopted", Type: worktree.Local, Adopted: true}))
+	require.NoError(t, metadata.Record(metadata.Entry{Path: "/src/repo-touched", Type: worktree.Local}))
+	store, err := metadata.Load()
+	require.NoError(t, err)
+
+	worktrees := []git.WorktreeInfo{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/wt/repo/pr_1", Branch: "fix"},
+		{Path: "/src/repo-adopted", Branch: "adopted"},
+		{Path: "/src/repo-touched", Branch: "touched"},
+		{Path: "/src/repo-hotfix", Branch: "hotfix"},
+	}
+
+	assert.Equal(t, []git.WorktreeInfo{worktrees[1], worktrees[2]}, filterManaged(worktrees, "/wt", store))
+	assert.Equal(t, []git.WorktreeInfo{worktrees[3], worktrees[4]}, unmanagedWorktrees(worktrees, "/wt", store))
+
+	_, err = adoptTarget(worktrees, "/wt", store, "/src/repo")
+	assert.ErrorContains(t, err, "main worktree")
+	_, err = adoptTarget(worktrees, "/wt", store, "/wt/repo/pr_1")
+	assert.ErrorContains(t, err, "already managed")
+	wt, err := adoptTarget(worktrees, "/wt", store, "/src/repo-hotfix")
+	require.NoError(t, err)
+	assert.Equal(t, "hotfix", wt.Branch)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// browseDirCmd represents the browse-dir command.
+var browseDirCmd = &cobra.Command{
+	Use:   "browse-dir [worktree|number|url]",
+	Short: "Open a worktree in the system file manager",
+	Long: heredoc.Doc(`
+		Open a worktree's directory in Finder, Explorer, or the file manager
+		xdg-open starts, e.g. to drag and drop files into a review branch.
+
+		Without an argument the worktree containing the current directory is
+		opened, or one of the repository's worktrees is selected.
+	`),
+	Example: heredoc.Doc(`
+		# Open a worktree in the file manager
+		gh wt browse-dir pr_123
+
+		# Open the current worktree
+		gh wt browse-dir
+	`),
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runBrowseDir,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(browseDirCmd)
+}
+
+func runBrowseDir(cmd *cobra.Command, args []string) error {
+	wt, err := browseDirTarget(args)
+	if err != nil {
+		return err
+	}
+	if !worktree.Exists(wt.Path) {
+		return fmt.Errorf("worktree does not exist at %s", wt.Path)
+	}
+	touchWorktree(wt)
+
+	opener := fileManagerCommand(runtime.GOOS)
+	if _, err := exec.LookPath(opener); err != nil {
+		return fmt.Errorf("%s not found on PATH; open %s by hand", opener, wt.Path)
+	}
+	Log.Infof("Opening %s in the file manager...\n", getWorktreeDisplayName(wt.Path))
+	// The file manager keeps running; don't wait for it.
+	c := exec.Command(opener, wt.Path)
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", opener, err)
+	}
+	return c.Process.Release()
+}
+
+// browseDirTarget returns the worktree named by args, the current worktree,
+// or one selected from the repository's managed worktrees.
+func browseDirTarget(args []string) (git.WorktreeInfo, error) {
+	if len(args) > 0 {
+		return findWorktree(args[0])
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	if wt, ok := currentWorktree(cfg.WorktreeBase); ok {
+		return wt, nil
+	}
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	store := loadListMetadata()
+	managed := filterManaged(worktrees, cfg.WorktreeBase, store)
+	switch len(managed) {
+	case 0:
+		return git.WorktreeInfo{}, fmt.Errorf("no worktrees found under %s", cfg.WorktreeBase)
+	case 1:
+		return managed[0], nil
+	}
+	return selectWorktree("Select a worktree to open:", managed)
+}
+
+// fileManagerCommand returns the command opening a directory in the file
+// manager on goos.
+func fileManagerCommand(goos string) string {
+	switch goos {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "explorer"
+	default:
+		return "xdg-open"
+	}
+}
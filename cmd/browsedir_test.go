@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileManagerCommand(t *testing.T) {
+	assert.Equal(t, "open", fileManagerCommand("darwin"))
+	assert.Equal(t, "explorer", fileManagerCommand("windows"))
+	assert.Equal(t, "xdg-open", fileManagerCommand("linux"))
+}
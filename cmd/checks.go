@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checksWatchFlag bool
+	checksJSONFlag  string
+)
+
+// checksCmd represents the checks command.
+var checksCmd = &cobra.Command{
+	Use:   "checks <worktree|number|url>",
+	Short: "Show CI status for a PR worktree",
+	Long: heredoc.Doc(`
+		Show CI status checks for the pull request linked to a worktree.
+
+		This runs gh pr checks for the worktree's PR, so there is no need to
+		remember the PR number. Worktrees that were not created from a PR are
+		looked up by their branch.
+	`),
+	Example: heredoc.Doc(`
+		# Show checks for a PR worktree
+		gh wt checks pr_123
+
+		# Watch checks until they finish
+		gh wt checks pr_123 --watch
+
+		# Output checks as JSON
+		gh wt checks pr_123 --json name,state,link
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runChecks,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(checksCmd)
+	checksCmd.Flags().BoolVarP(&checksWatchFlag, "watch", "w", false, "watch checks until they finish")
+	checksCmd.Flags().StringVar(&checksJSONFlag, "json", "", "output JSON with the specified fields (see gh pr checks --json)")
+}
+
+func runChecks(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+
+	selector, err := prSelector(wt)
+	if err != nil {
+		return err
+	}
+
+	ghArgs := append([]string{"pr", "checks"}, selector...)
+	if checksWatchFlag {
+		ghArgs = append(ghArgs, "--watch")
+	}
+	if checksJSONFlag != "" {
+		ghArgs = append(ghArgs, "--json", checksJSONFlag)
+	}
+
+	if err := ghExecInteractive(context.Background(), ghArgs...); err != nil {
+		return fmt.Errorf("checks for %s did not pass: %w", getWorktreeDisplayName(wt.Path), err)
+	}
+	return nil
+}
+
+// prSelector returns the gh arguments selecting the PR linked to a worktree: its
+// number and repository from metadata, or its branch otherwise.
+func prSelector(wt git.WorktreeInfo) ([]string, error) {
+	if store, err := metadata.Load(); err == nil {
+		if e, ok := store.Get(wt.Path); ok && e.Type == worktree.PR {
+			args := []string{strconv.Itoa(e.Number)}
+			if e.Owner != "" && e.Repo != "" {
+				args = append(args, "--repo", e.Owner+"/"+e.Repo)
+			}
+			return args, nil
+		}
+	}
+	if wt.Branch == "" {
+		return nil, fmt.Errorf("worktree %s is not linked to a PR and has no branch", getWorktreeDisplayName(wt.Path))
+	}
+	return []string{wt.Branch}, nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	codeReuseWindowFlag bool
+	codeRemoteFlag      string
+)
+
+// codeEditors are the VS Code compatible editors detected on PATH, in order.
+var codeEditors = []string{"code", "cursor", "codium"}
+
+// codeCmd represents the code command.
+var codeCmd = &cobra.Command{
+	Use:   "code <worktree|number|url>",
+	Short: "Open a worktree in VS Code",
+	Long: heredoc.Doc(`
+		Open a worktree in VS Code, in a new window by default.
+
+		The first of code, cursor, and codium found on PATH is used, unless
+		code_command is set in the config.
+	`),
+	Example: heredoc.Doc(`
+		# Open a worktree in a new VS Code window
+		gh wt code pr_123
+
+		# Open it in the current window instead
+		gh wt code pr_123 --reuse-window
+
+		# Open the worktree on a remote host over SSH
+		gh wt code pr_123 --remote ssh-remote+devbox
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runCode,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(codeCmd)
+	codeCmd.Flags().BoolVarP(&codeReuseWindowFlag, "reuse-window", "r", false, "open in the last active window instead of a new one")
+	codeCmd.Flags().StringVar(&codeRemoteFlag, "remote", "", "open through a VS Code remote, e.g. ssh-remote+host")
+}
+
+func runCode(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	if !worktree.Exists(wt.Path) {
+		return fmt.Errorf("worktree '%s' does not exist at %s", args[0], wt.Path)
+	}
+	touchWorktree(wt)
+
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	editor, err := codeEditor(cfg.CodeCommand)
+	if err != nil {
+		return err
+	}
+
+	codeArgs := codeArgs(wt.Path, codeReuseWindowFlag, codeRemoteFlag)
+	Log.Infof("Opening %s in %s...\n", getWorktreeDisplayName(wt.Path), editor)
+	c := exec.Command(editor, codeArgs...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", editor, err)
+	}
+	return nil
+}
+
+// codeEditor returns the editor command: configured, or the first of
+// codeEditors found on PATH.
+func codeEditor(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, name := range codeEditors {
+		if _, err := exec.LookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("none of code, cursor, or codium found on PATH; set code_command in the config")
+}
+
+// codeArgs returns the editor arguments opening path.
+func codeArgs(path string, reuseWindow bool, remote string) []string {
+	args := []string{"--new-window"}
+	if reuseWindow {
+		args[0] = "--reuse-window"
+	}
+	if remote != "" {
+		args = append(args, "--remote", remote)
+	}
+	return append(args, path)
+}
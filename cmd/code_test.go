@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeArgs(t *testing.T) {
+	assert.Equal(t, []string{"--new-window", "/wt/pr_1"}, codeArgs("/wt/pr_1", false, ""))
+	assert.Equal(t, []string{"--reuse-window", "/wt/pr_1"}, codeArgs("/wt/pr_1", true, ""))
+	assert.Equal(t, []string{"--new-window", "--remote", "ssh-remote+devbox", "/wt/pr_1"}, codeArgs("/wt/pr_1", false, "ssh-remote+devbox"))
+}
+
+func TestCodeEditor(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	editor, err := codeEditor("cursor")
+	assert.NoError(t, err)
+	assert.Equal(t, "cursor", editor)
+
+	_, err = codeEditor("")
+	assert.ErrorContains(t, err, "code_command")
+}
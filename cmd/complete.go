@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/cache"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// completionConfig returns the config for completion callbacks, which run
+// without the root command's PersistentPreRunE.
+func completionConfig() (config.Config, bool) {
+	if Log == nil {
+		Log = logger.NewLogger(false, false, false)
+	}
+	if cfg, err := config.Get(); err == nil {
+		return cfg, true
+	}
+	if _, err := config.Load(); err != nil {
+		return config.Config{}, false
+	}
+	cfg, err := config.Get()
+	return cfg, err == nil
+}
+
+// completeWorktrees completes the first argument with the names of the
+// current repository's worktrees, described by their branch and PR or issue.
+func completeWorktrees(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return worktreeNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunArgs completes the worktree, then the action, of gh wt run. With
+// --all the only argument is the action.
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch {
+	case runAllFlag && len(args) == 0, !runAllFlag && len(args) == 1:
+		return completeActions(cmd, nil, toComplete)
+	case len(args) == 0:
+		return worktreeNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeActions completes configured action names, described by their
+// first command.
+func completeActions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return actionCompletions(cfg.Actions), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeActionName completes the only argument with configured action
+// names.
+func completeActionName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeActions(cmd, args, toComplete)
+}
+
+// completeActionRunArgs completes the action, then the worktree, of gh wt
+// action run.
+func completeActionRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeActions(cmd, args, toComplete)
+	case 1:
+		return worktreeNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePullRequests completes --pr with the current repository's open
+// pull requests, described by their title.
+func completePullRequests(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok || cfg.GitOnly || providerFor("").name() != config.ProviderGitHub {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cachedCompletions(cfg, "pulls", func() []string {
+		owner, repo, err := currentRepo()
+		if err != nil || owner == "" {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		prs, err := github.ListOpenPullRequests(ctx, owner, repo)
+		if err != nil {
+			return nil
+		}
+		completions := make([]string, 0, len(prs))
+		for _, pr := range prs {
+			completions = append(completions, strconv.Itoa(pr.Number)+"\t"+pr.Title)
+		}
+		return completions
+	}), cobra.ShellCompDirectiveNoFileComp
+}
+
+func worktreeNameCompletions() []string {
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil
+	}
+	return cachedCompletions(cfg, "worktrees", func() []string {
+		worktrees, err := git.GetWorktreeInfo()
+		if err != nil {
+			return nil
+		}
+		store, _ := metadata.Load()
+		return worktreeCompletions(filterManaged(worktrees, cfg.WorktreeBase, store), store)
+	})
+}
+
+// cachedCompletions returns completions of kind for the current directory
+// from the completion cache, computing them when they are missing or older
+// than completion.cache_ttl.
+func cachedCompletions(cfg config.Config, kind string, compute func() []string) []string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return compute()
+	}
+	ttl := time.Duration(cfg.Completion.CacheTTL) * time.Second
+	return cache.Get(kind+":"+dir, ttl, compute)
+}
+
+// clearCompletionCache drops cached completions after worktrees change, so
+// the next Tab sees them.
+func clearCompletionCache() {
+	if err := cache.Clear(); err != nil {
+		Log.Debugf("Failed to clear completion cache: %v\n", err)
+	}
+}
+
+// worktreeCompletions returns "name\tdescription" completions for worktrees.
+// Shells without descriptions, such as bash, show only the name.
+func worktreeCompletions(worktrees []git.WorktreeInfo, store *metadata.Store) []string {
+	completions := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		var entry metadata.Entry
+		if store != nil {
+			entry, _ = store.Get(wt.Path)
+		}
+		completions = append(completions, filepath.Base(wt.Path)+"\t"+worktreeDescription(wt, entry))
+	}
+	return completions
+}
+
+// worktreeDescription describes a worktree by its branch and the PR or issue
+// it was created from, e.g. "fix-login · PR #12: Fix login".
+func worktreeDescription(wt git.WorktreeInfo, e metadata.Entry) string {
+	desc := wt.Branch
+	if desc == "" {
+		desc = "(detached)"
+	}
+	if e.Number == 0 {
+		return desc
+	}
+	kind := "Issue"
+	if e.Type == worktree.PR {
+		kind = "PR"
+	}
+	desc += fmt.Sprintf(" · %s #%d", kind, e.Number)
+	if e.Title != "" {
+		desc += ": " + e.Title
+	}
+	return desc
+}
+
+// actionCompletions returns "name\tdescription" completions for actions,
+// described by their first command or plugin.
+func actionCompletions(actions []config.Action) []string {
+	completions := make([]string, 0, len(actions))
+	for _, a := range actions {
+		desc := ""
+		switch {
+		case a.Type == config.ActionTypeExecPlugin:
+			plugin := a.Plugin
+			if plugin == "" {
+				plugin = a.Name
+			}
+			desc = "runs gh-wt-action-" + plugin
+		case len(a.Cmds) > 0:
+			desc = strings.TrimSpace(strings.SplitN(strings.TrimSpace(a.Cmds[0]), "\n", 2)[0])
+			if len(a.Cmds) > 1 {
+				desc += fmt.Sprintf(" (+%d more)", len(a.Cmds)-1)
+			}
+		}
+		completions = append(completions, a.Name+"\t"+desc)
+	}
+	return completions
+}
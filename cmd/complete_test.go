@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorktreeCompletions(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	require.NoError(t, metadata.Record(metadata.Entry{
+		Path: "/wt/repo/pr_12", Type: worktree.PR, Number: 12, Title: "Fix login",
+	}))
+	require.NoError(t, metadata.Record(metadata.Entry{
+		Path: "/wt/repo/issue_7", Type: worktree.Issue, Number: 7,
+	}))
+	store, err := metadata.Load()
+	require.NoError(t, err)
+
+	got := worktreeCompletions([]git.WorktreeInfo{
+		{Path: "/wt/repo/pr_12", Branch: "fix-login"},
+		{Path: "/wt/repo/issue_7", Branch: "issue_7"},
+		{Path: "/wt/repo/spike"},
+	}, store)
+	assert.Equal(t, []string{
+		"pr_12\tfix-login · PR #12: Fix login",
+		"issue_7\tissue_7 · Issue #7",
+		"spike\t(detached)",
+	}, got)
+}
+
+func TestActionCompletions(t *testing.T) {
+	got := actionCompletions([]config.Action{
+		{Name: "tmux", Cmds: []string{"tmux new-session -d", "tmux attach"}},
+		{Name: "setup", Cmds: []string{"\nnpm ci\nnpm run build\n"}},
+		{Name: "ide", Type: config.ActionTypeExecPlugin, Plugin: "vscode"},
+		{Name: "empty"},
+	})
+	assert.Equal(t, []string{
+		"tmux\ttmux new-session -d (+1 more)",
+		"setup\tnpm ci",
+		"ide\truns gh-wt-action-vscode",
+		"empty\t",
+	}, got)
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configMergeFlag    bool
+	configGitUnsetFlag bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the gh-wt config file",
+	Long: heredoc.Doc(`
+		Manage the gh-wt config file.
+	`),
+	GroupID: "utilities",
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export the config, including actions, as a single YAML document",
+	Long: heredoc.Doc(`
+		Export the config file, including actions, as a single YAML document.
+
+		The document is written to stdout, or to the given file. It can be
+		loaded on another machine with gh wt config import.
+	`),
+	Example: heredoc.Doc(`
+		# Print the config
+		gh wt config export
+
+		# Save the config to share a team baseline
+		gh wt config export team.yaml
+	`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigExport,
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Import a config document produced by export",
+	Long: heredoc.Doc(`
+		Import a config document produced by gh wt config export.
+
+		By default the document replaces the config file. With --merge, its
+		settings are merged over the existing config instead. Use - to read the
+		document from stdin. The previous config file is kept with a .bak suffix.
+	`),
+	Example: heredoc.Doc(`
+		# Replace the config with a team baseline
+		gh wt config import team.yaml
+
+		# Merge settings from another machine
+		ssh laptop gh wt config export | gh wt config import --merge -
+	`),
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigImport,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the config file against the config schema",
+	Long: heredoc.Doc(`
+		Check the config file against the gh-wt config schema.
+
+		Reports unknown keys, values of the wrong type, actions without
+		commands, and templates that fail to parse, with the line of each
+		problem. Validates the config file in use unless a file is given.
+
+		The schema is published at https://ffalor.github.io/gh-wt/schema/config.json
+		for use with editors that support YAML schemas.
+	`),
+	Example: heredoc.Doc(`
+		# Validate the config file in use
+		gh wt config validate
+
+		# Validate a shared config before importing it
+		gh wt config validate team.yaml
+	`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigValidate,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [file]",
+	Short: "Migrate a config file from gh-worktree",
+	Long: heredoc.Doc(`
+		Migrate the config file of gh-worktree, the previous name of this
+		extension, into the gh-wt config.
+
+		Settings such as worktree_dir and actions are merged into the gh-wt
+		config, overwriting existing values. Keys gh-wt does not know are
+		reported and skipped. Reads ~/.config/gh-worktree/config.yaml unless
+		a file is given.
+
+		On the first run without a gh-wt config, gh wt offers to do this
+		automatically.
+	`),
+	Example: heredoc.Doc(`
+		# Migrate ~/.config/gh-worktree/config.yaml
+		gh wt config migrate
+	`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigMigrate,
+}
+
+var configGitCmd = &cobra.Command{
+	Use:   "git <worktree> [key [value]]",
+	Short: "Get or set git config for one worktree only",
+	Long: heredoc.Doc(`
+		Get or set git config that applies to one worktree only, such as a
+		different push remote or sparse-checkout settings per worktree.
+
+		Values are written with git config --worktree, enabling
+		extensions.worktreeConfig in the repository first if needed.
+		core.worktree and core.bare are moved out of the shared config when
+		the extension is enabled, as git requires.
+
+		With only a worktree, its per-worktree settings are listed. With a
+		key, its per-worktree value is printed, or removed with --unset. With
+		a key and a value, the value is set.
+
+		The worktree can be given by name, or by the PR/issue URL or number it
+		was created from.
+	`),
+	Example: heredoc.Doc(`
+		# Push the worktree of PR #123 to a fork
+		gh wt config git 123 remote.pushDefault fork
+
+		# Show the per-worktree settings of a worktree
+		gh wt config git pr_123
+
+		# Remove a per-worktree setting
+		gh wt config git pr_123 remote.pushDefault --unset
+	`),
+	Args:              cobra.RangeArgs(1, 3),
+	RunE:              runConfigGit,
+	ValidArgsFunction: completeWorktrees,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configGitCmd)
+	configGitCmd.Flags().BoolVar(&configGitUnsetFlag, "unset", false, "remove the key from the worktree's config")
+	configImportCmd.Flags().BoolVar(&configMergeFlag, "merge", false, "merge the document over the existing config instead of replacing it")
+}
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return config.Export(os.Stdout)
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", args[0], err)
+	}
+	defer f.Close()
+
+	if err := config.Export(f); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "Exported config to %s\n", args[0])
+	return nil
+}
+
+func runConfigImport(cmd *cobra.Command, args []string) error {
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", args[0], err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	backup, err := config.Import(r, configMergeFlag)
+	if err != nil {
+		return err
+	}
+
+	Log.Outf(logger.Green, "Imported config into %s\n", config.ConfigFileUsed())
+	Log.Infof("Previous config saved to %s\n", backup)
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	path := config.ConfigFileUsed()
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	problems, err := config.Validate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(problems) == 0 {
+		Log.Outf(logger.Green, "%s is valid\n", path)
+		return nil
+	}
+
+	for _, p := range problems {
+		if p.Field == "" {
+			Log.Errorf("%s:%d:%d: %s\n", path, p.Line, p.Column, p.Message)
+			continue
+		}
+		Log.Errorf("%s:%d:%d: %s: %s\n", path, p.Line, p.Column, p.Location(), p.Message)
+	}
+	return fmt.Errorf("%s has %d problem(s)", path, len(problems))
+}
+
+func runConfigGit(cmd *cobra.Command, args []string) error {
+	if configGitUnsetFlag && len(args) != 2 {
+		return fmt.Errorf("--unset takes a worktree and a key")
+	}
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	name := getWorktreeDisplayName(wt.Path)
+
+	switch {
+	case len(args) == 1:
+		settings, err := git.ListWorktreeConfig(wt.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read config of %s: %w", name, err)
+		}
+		if len(settings) == 0 {
+			Log.Infof("%s has no per-worktree git config\n", name)
+			return nil
+		}
+		for _, setting := range settings {
+			Log.Plainf("%s\n", setting)
+		}
+	case configGitUnsetFlag:
+		if err := git.UnsetWorktreeConfig(wt.Path, args[1]); err != nil {
+			return fmt.Errorf("failed to unset %s: %w", args[1], err)
+		}
+		Log.Outf(logger.Green, "Unset %s in %s\n", args[1], name)
+	case len(args) == 2:
+		value, ok, err := git.WorktreeConfig(wt.Path, args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[1], err)
+		}
+		if !ok {
+			return silentExit(1)
+		}
+		Log.Plainf("%s\n", value)
+	default:
+		if err := git.SetWorktreeConfig(wt.Path, args[1], args[2]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", args[1], err)
+		}
+		Log.Outf(logger.Green, "Set %s to '%s' in %s\n", args[1], args[2], name)
+	}
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		var err error
+		if path, err = config.LegacyConfigFile(); err != nil {
+			return err
+		}
+	}
+	return migrateLegacyConfig(path)
+}
+
+// migrateLegacyConfig migrates a gh-worktree config file and reports the result.
+func migrateLegacyConfig(path string) error {
+	dropped, err := config.MigrateLegacy(path)
+	if err != nil {
+		return err
+	}
+	for _, key := range dropped {
+		Log.Warnf("Skipped unknown key '%s' from %s\n", key, path)
+	}
+	Log.Outf(logger.Green, "Migrated %s into the gh-wt config\n", path)
+	return nil
+}
+
+// offerLegacyMigration offers to migrate the gh-worktree config on the first
+// run of an interactive command without a gh-wt config. The prompt goes to
+// stderr so it never mixes with a command's output, and a declined migration
+// is not offered again; gh wt config migrate still runs it.
+func offerLegacyMigration(cmd *cobra.Command) error {
+	if cmd == configMigrateCmd || !interactiveCommand(cmd) {
+		return nil
+	}
+	legacy, ok := config.PendingLegacyMigration()
+	if !ok {
+		return nil
+	}
+
+	if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stderr) {
+		Log.Warnf("Found a gh-worktree config at %s; run 'gh wt config migrate' to import it\n", legacy)
+		return nil
+	}
+	p := newPrompter(os.Stderr)
+	migrate, err := p.Confirm(fmt.Sprintf("Found a gh-worktree config at %s. Migrate it to gh-wt?", legacy), true)
+	if err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
+	if !migrate {
+		if err := config.DeclineLegacyMigration(); err != nil {
+			return err
+		}
+		Log.Warnf("Not migrating %s; run 'gh wt config migrate' to import it later\n", legacy)
+		return nil
+	}
+	return migrateLegacyConfig(legacy)
+}
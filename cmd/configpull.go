@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// defaultSharedConfig is the file read from a repository by gh wt config pull.
+const defaultSharedConfig = "gh-wt.yaml"
+
+var (
+	configPullPathFlag   string
+	configPullRemoveFlag bool
+)
+
+var configPullCmd = &cobra.Command{
+	Use:   "pull [owner/repo[@ref]]",
+	Short: "Use a shared config from a GitHub repository",
+	Long: heredoc.Doc(`
+		Fetch a shared config, such as your organization's standard actions,
+		from a GitHub repository and keep it in the includes directory,
+		~/.config/gh-wt/includes.
+
+		Includes are merged under your config file: their settings replace the
+		built-in defaults, and their actions are added unless your config has
+		an action of the same name. Your own settings always win.
+
+		The include is pinned to the commit it was pulled from. Give a branch,
+		tag, or commit after @ to follow it instead of the default branch.
+		Without an argument, every pulled repository is checked and updates are
+		offered, with a link to review the changes first. --force applies them
+		without asking.
+	`),
+	Example: heredoc.Doc(`
+		# Use the shared config of your organization
+		gh wt config pull acme/gh-wt-config
+
+		# Follow a release tag, reading a file other than gh-wt.yaml
+		gh wt config pull acme/dev-tools@v2 --path config/gh-wt.yaml
+
+		# Check for and apply updates
+		gh wt config pull
+
+		# Stop using a shared config
+		gh wt config pull acme/gh-wt-config --remove
+	`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigPull,
+}
+
+func init() {
+	configCmd.AddCommand(configPullCmd)
+	configPullCmd.Flags().StringVar(&configPullPathFlag, "path", defaultSharedConfig, "file in the repository holding the shared config")
+	configPullCmd.Flags().BoolVarP(&configPullRemoveFlag, "remove", "d", false, "remove the shared config pulled from the repository")
+}
+
+func runConfigPull(cmd *cobra.Command, args []string) error {
+	sources, err := config.IncludeSources()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if configPullRemoveFlag {
+			return fmt.Errorf("--remove needs the repository to remove")
+		}
+		if len(sources) == 0 {
+			return fmt.Errorf("no shared configs; pull one with 'gh wt config pull <owner/repo>'")
+		}
+		for _, src := range sources {
+			if err := pullInclude(src, &src); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	repo, ref, err := parseConfigRepo(args[0])
+	if err != nil {
+		return err
+	}
+	if configPullRemoveFlag {
+		removed, err := config.RemoveInclude(repo)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("no shared config was pulled from %s", repo)
+		}
+		Log.Outf(logger.Green, "✓ Removed the shared config of %s\n", repo)
+		return nil
+	}
+
+	src := config.IncludeSource{Repo: repo, Ref: ref, Path: configPullPathFlag}
+	for _, prev := range sources {
+		if strings.EqualFold(prev.Repo, repo) {
+			return pullInclude(src, &prev)
+		}
+	}
+	return pullInclude(src, nil)
+}
+
+// parseConfigRepo splits "owner/repo[@ref]".
+func parseConfigRepo(arg string) (repo, ref string, err error) {
+	repo, ref, _ = strings.Cut(arg, "@")
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("expected owner/repo[@ref], got '%s'", arg)
+	}
+	return repo, ref, nil
+}
+
+// pullInclude fetches the shared config of src at the latest commit of its
+// ref. When prev, the include pulled before, is at another commit, the update
+// is confirmed first.
+func pullInclude(src config.IncludeSource, prev *config.IncludeSource) error {
+	label := src.Repo
+	if src.Ref != "" {
+		label += "@" + src.Ref
+	}
+	commit, err := upstreamCommit(src.Repo, src.Ref)
+	if err != nil {
+		return err
+	}
+
+	if prev != nil {
+		if prev.Commit == commit && prev.Path == src.Path && prev.Ref == src.Ref {
+			Log.Infof("%s is up to date (%s)\n", label, shortSHA(commit))
+			return nil
+		}
+		if prev.Commit != commit {
+			Log.Infof("Update available for %s: %s → %s\n", label, shortSHA(prev.Commit), shortSHA(commit))
+			Log.Infof("  https://github.com/%s/compare/%s...%s\n", src.Repo, prev.Commit, commit)
+			if !forceFlag {
+				if !term.IsTerminal(os.Stdin) {
+					Log.Infof("Run 'gh wt config pull --force' to apply it\n")
+					return nil
+				}
+				p := newPrompter(os.Stdout)
+				update, err := p.Confirm(fmt.Sprintf("Update the shared config of %s?", src.Repo), true)
+				if err != nil {
+					return fmt.Errorf("prompt failed: %w", err)
+				}
+				if !update {
+					return nil
+				}
+			}
+		}
+	}
+
+	data, err := fetchSharedConfig(src.Repo, src.Path, commit)
+	if err != nil {
+		return err
+	}
+	src.Commit = commit
+	src.PulledAt = time.Now()
+	if err := config.SaveInclude(src, data); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "✓ Pulled the shared config of %s (%s)\n", label, shortSHA(commit))
+	return nil
+}
+
+// upstreamCommit returns the commit ref of repo points to, or the head of its
+// default branch when ref is empty.
+func upstreamCommit(repo, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	stdout, stderr, err := ghExec("api", fmt.Sprintf("repos/%s/commits/%s", repo, url.PathEscape(ref)), "--jq", ".sha")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s of %s: %w: %s", ref, repo, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// fetchSharedConfig returns the content of path in repo at commit.
+func fetchSharedConfig(repo, path, commit string) ([]byte, error) {
+	endpoint := fmt.Sprintf("repos/%s/contents/%s?ref=%s", repo, strings.TrimPrefix(path, "/"), commit)
+	stdout, stderr, err := ghExec("api", "-H", "Accept: application/vnd.github.raw+json", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from %s: %w: %s", path, repo, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
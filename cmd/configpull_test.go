@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigRepo(t *testing.T) {
+	repo, ref, err := parseConfigRepo("acme/gh-wt-config")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/gh-wt-config", repo)
+	assert.Empty(t, ref)
+
+	repo, ref, err = parseConfigRepo("acme/dev-tools@release/v2")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/dev-tools", repo)
+	assert.Equal(t, "release/v2", ref)
+
+	for _, arg := range []string{"acme", "acme/", "/repo", "acme/tools/extra"} {
+		_, _, err := parseConfigRepo(arg)
+		assert.Error(t, err, arg)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var configTrustRevokeFlag bool
+
+var configTrustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Trust the .gh-wt.yaml of the current repository",
+	Long: heredoc.Doc(`
+		Trust the repository config, .gh-wt.yaml, of the current repository, so
+		that its actions are available inside the repository.
+
+		A repository config is only used once you trusted it, the way direnv
+		allow works: the first time gh wt finds one, or after it changes, it is
+		shown and you are asked whether to use it. Run this command to trust it
+		where gh wt cannot ask, e.g. in scripts. --revoke stops using it.
+	`),
+	Example: heredoc.Doc(`
+		# Review and trust the repository config
+		cat .gh-wt.yaml && gh wt config trust
+
+		# Stop using it
+		gh wt config trust --revoke
+	`),
+	Args: cobra.NoArgs,
+	RunE: runConfigTrust,
+}
+
+func init() {
+	configCmd.AddCommand(configTrustCmd)
+	configTrustCmd.Flags().BoolVar(&configTrustRevokeFlag, "revoke", false, "stop using the repository config")
+}
+
+func runConfigTrust(cmd *cobra.Command, args []string) error {
+	dir := mainWorktreePath()
+	if dir == "" {
+		return fmt.Errorf("not in a git repository")
+	}
+	path := filepath.Join(dir, config.RepoConfigName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("the repository has no %s", config.RepoConfigName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := config.TrustRepoConfig(path, data, !configTrustRevokeFlag); err != nil {
+		return err
+	}
+	if configTrustRevokeFlag {
+		Log.Outf(logger.Green, "✓ No longer using %s\n", getTildePath(path))
+		return nil
+	}
+	Log.Outf(logger.Green, "✓ Trusted %s\n", getTildePath(path))
+	return nil
+}
+
+// loadRepoConfig merges the repository config of the current repository. It
+// is read from the main worktree rather than the current one, so that a pull
+// request checked out in a worktree cannot change the actions you run. A
+// repository config you have not reviewed yet is shown, and interactive
+// commands ask whether to trust it; otherwise it is ignored. Keys it cannot
+// set are reported and ignored.
+func loadRepoConfig(cmd *cobra.Command) error {
+	dir := mainWorktreePath()
+	if dir == "" {
+		return nil
+	}
+	err := mergeRepoConfig(dir)
+	var untrusted *config.UntrustedRepoConfigError
+	if !errors.As(err, &untrusted) {
+		return err
+	}
+	if cmd == configTrustCmd || !interactiveCommand(cmd) {
+		return nil
+	}
+	if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stderr) {
+		Log.Warnf("Ignoring %v; review it and run 'gh wt config trust' to use it\n", untrusted)
+		return nil
+	}
+
+	Log.FOutf(os.Stderr, logger.Yellow, "%s is new or changed since you reviewed it. It can add actions that run commands on your machine:\n\n", getTildePath(untrusted.Path))
+	Log.FOutf(os.Stderr, logger.None, "%s\n", untrusted.Data)
+	trust, err := newPrompter(os.Stderr).Confirm(fmt.Sprintf("Trust %s?", getTildePath(untrusted.Path)), false)
+	if err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
+	if err := config.TrustRepoConfig(untrusted.Path, untrusted.Data, trust); err != nil {
+		return err
+	}
+	if !trust {
+		Log.Warnf("Ignoring %s; run 'gh wt config trust' to use it later\n", getTildePath(untrusted.Path))
+		return nil
+	}
+	return mergeRepoConfig(dir)
+}
+
+// mergeRepoConfig merges the repository config in dir and warns about the
+// keys it left out.
+func mergeRepoConfig(dir string) error {
+	ignored, err := config.LoadRepo(dir)
+	for _, key := range ignored {
+		Log.Warnf("Ignoring %s (line %d of %s): %s\n", key.Field, key.Line, getTildePath(filepath.Join(dir, config.RepoConfigName)), key.Message)
+	}
+	return err
+}
+
+// mainWorktreePath returns the path of the main worktree of the current
+// repository, or "" outside a repository.
+func mainWorktreePath() string {
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil || len(worktrees) == 0 {
+		return ""
+	}
+	return worktrees[0].Path
+}
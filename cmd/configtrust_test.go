@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractiveCommand(t *testing.T) {
+	assert.True(t, interactiveCommand(addCmd))
+	assert.True(t, interactiveCommand(configPullCmd))
+	assert.False(t, interactiveCommand(promptCmd), "prompt runs from PS1")
+	assert.False(t, interactiveCommand(initCmd), "init runs inside eval")
+
+	root := &cobra.Command{Use: "root"}
+	complete := &cobra.Command{Use: cobra.ShellCompRequestCmd, Hidden: true}
+	root.AddCommand(complete)
+	assert.False(t, interactiveCommand(complete))
+}
+
+func TestConfigTrust(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	Log = &logger.Logger{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	t.Cleanup(func() { Log = nil })
+
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	t.Chdir(repo)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, config.RepoConfigName), []byte("actions:\n  - name: build\n    cmds: [make]\n"), 0o600))
+
+	_, err := config.Load()
+	require.NoError(t, err)
+	require.NoError(t, loadRepoConfig(promptCmd), "commands that cannot ask ignore an untrusted config")
+	assert.Empty(t, config.RepoConfigUsed())
+
+	require.NoError(t, runConfigTrust(configTrustCmd, nil))
+	_, err = config.Load()
+	require.NoError(t, err)
+	require.NoError(t, loadRepoConfig(promptCmd))
+	assert.NotEmpty(t, config.RepoConfigUsed())
+	cfg, err := config.Get()
+	require.NoError(t, err)
+	require.Len(t, cfg.Actions, 1)
+	assert.Equal(t, "build", cfg.Actions[0].Name)
+}
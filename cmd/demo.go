@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var demoKeepFlag bool
+
+// demoRepo is the name of the sandbox repository.
+const demoRepo = "demo-app"
+
+// demoCmd represents the demo command.
+var demoCmd = &cobra.Command{
+	Use:   "demo [-- <command>]",
+	Short: "Try gh wt in a throwaway sandbox repository",
+	Long: heredoc.Doc(`
+		Create a throwaway repository with a few branches, pull request refs,
+		and worktrees in a temporary directory, and start your $SHELL in it.
+		gh wt commands run in that shell use the sandbox's worktree directory
+		and state and work without GitHub (git_only), so you can explore
+		adding, listing, running, and removing worktrees without touching
+		your real repositories.
+
+		The sandbox is deleted when you exit the shell, unless --keep is set.
+		Pass a command after -- to run it in the sandbox instead of a shell.
+	`),
+	Example: heredoc.Doc(`
+		# Explore gh wt in a sandbox shell
+		gh wt demo
+
+		# Run a single command in a sandbox
+		gh wt demo -- gh wt list
+
+		# Keep the sandbox after exiting
+		gh wt demo --keep
+	`),
+	Args:    cobra.NoArgs,
+	RunE:    runDemo,
+	GroupID: "utilities",
+}
+
+func init() {
+	demoCmd.Flags().BoolVar(&demoKeepFlag, "keep", false, "keep the sandbox directory after exiting")
+	rootCmd.AddCommand(demoCmd)
+}
+
+func runDemo(cmd *cobra.Command, args []string) error {
+	root, err := os.MkdirTemp("", "gh-wt-demo-")
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	if demoKeepFlag {
+		defer Log.Infof("Sandbox kept at %s\n", root)
+	} else {
+		defer os.RemoveAll(root)
+	}
+
+	Log.Infof("Creating sandbox in %s...\n", root)
+	repoDir, err := createSandbox(root)
+	if err != nil {
+		return err
+	}
+
+	var c *exec.Cmd
+	if len(cliArgv) > 0 {
+		c = exec.Command(userShell(), "-c", directCommand(cliArgv))
+	} else {
+		Log.Outf(logger.Green, "\nSandbox ready. Try:\n")
+		Log.Outf(logger.Cyan, heredoc.Doc(`
+			  gh wt list
+			  gh wt add my-feature
+			  gh wt add --pr 1
+			  gh wt run my-feature -- git log --oneline
+			  gh wt rm my-feature
+		`))
+		exitHint := "delete the sandbox"
+		if demoKeepFlag {
+			exitHint = "leave it"
+		}
+		Log.Outf(logger.Default, "Exit the shell to %s.\n\n", exitHint)
+		c = exec.Command(userShell())
+	}
+	c.Dir = repoDir
+	c.Env = append(os.Environ(), demoEnv(root)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to start sandbox shell: %w", err)
+	}
+	return nil
+}
+
+// demoEnv points gh wt at the sandbox under root.
+func demoEnv(root string) []string {
+	return []string{
+		"GH_WT_DEMO=" + root,
+		"GH_WT_WORKTREE_DIR=" + filepath.Join(root, "worktrees"),
+		"GH_WT_GIT_ONLY=true",
+		"XDG_STATE_HOME=" + filepath.Join(root, "state"),
+	}
+}
+
+// createSandbox creates the demo repository under root: a main branch, two
+// feature branches published as pull requests 1 and 2 on a local origin, and
+// one existing worktree. It returns the repository directory.
+func createSandbox(root string) (string, error) {
+	repoDir := filepath.Join(root, demoRepo)
+	origin := filepath.Join(root, "origin.git")
+	worktreeDir := filepath.Join(root, "worktrees", demoRepo)
+
+	files := map[string]string{
+		"README.md": "# demo-app\n\nA sandbox repository created by gh wt demo.\n",
+		"main.go":   "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
+	}
+	steps := []struct {
+		dir   string
+		args  []string
+		files map[string]string
+	}{
+		{root, []string{"init", "-q", "--bare", origin}, nil},
+		{root, []string{"init", "-q", repoDir}, nil},
+		{repoDir, []string{"checkout", "-q", "-b", "main"}, files},
+		{repoDir, []string{"add", "."}, nil},
+		{repoDir, []string{"commit", "-q", "-m", "Initial commit"}, nil},
+		{repoDir, []string{"checkout", "-q", "-b", "feature/login"}, map[string]string{"login.go": "package main\n\nfunc login() {}\n"}},
+		{repoDir, []string{"add", "."}, nil},
+		{repoDir, []string{"commit", "-q", "-m", "Add login"}, nil},
+		{repoDir, []string{"checkout", "-q", "-b", "fix/typo", "main"}, map[string]string{"README.md": "# demo-app\n\nA sandbox repository created by `gh wt demo`.\n"}},
+		{repoDir, []string{"commit", "-q", "-am", "Fix typo in README"}, nil},
+		{repoDir, []string{"checkout", "-q", "main"}, nil},
+		{repoDir, []string{"remote", "add", "origin", origin}, nil},
+		{repoDir, []string{"push", "-q", "origin", "main", "feature/login", "fix/typo",
+			"feature/login:refs/pull/1/head", "fix/typo:refs/pull/2/head"}, nil},
+		{repoDir, []string{"fetch", "-q", "origin"}, nil},
+		{repoDir, []string{"worktree", "add", "-q", "-b", "spike", filepath.Join(worktreeDir, "spike")}, nil},
+	}
+
+	// Files are written after the step's git command runs.
+	for _, step := range steps {
+		args := append([]string{"-c", "user.name=gh wt demo", "-c", "user.email=demo@example.com", "-c", "commit.gpgsign=false"}, step.args...)
+		if _, err := git.CommandOutputAt(step.dir, args...); err != nil {
+			return "", fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		for name, content := range step.files {
+			if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+				return "", fmt.Errorf("failed to create sandbox: %w", err)
+			}
+		}
+	}
+	return repoDir, nil
+}
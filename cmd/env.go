@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var envJSONFlag bool
+
+// envCmd represents the env command.
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List config keys, their defaults, and environment variables",
+	Long: heredoc.Doc(`
+		List every config key with its default and the GH_WT_* environment
+		variable that overrides it, followed by the environment variables gh wt
+		sets for actions and commands run in a worktree.
+
+		Lists and objects, such as actions, can only be set in the config file.
+	`),
+	Example: heredoc.Doc(`
+		# List config keys and environment variables
+		gh wt env
+
+		# Print them as JSON, including descriptions
+		gh wt env --json
+	`),
+	Args:    cobra.NoArgs,
+	RunE:    runEnv,
+	GroupID: "utilities",
+}
+
+func init() {
+	envCmd.Flags().BoolVar(&envJSONFlag, "json", false, "print config keys and environment variables as JSON")
+	rootCmd.AddCommand(envCmd)
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	keys, err := config.Keys()
+	if err != nil {
+		return err
+	}
+	if envJSONFlag {
+		return writeEnvJSON(cmd.OutOrStdout(), keys)
+	}
+
+	keyWidth, envWidth := len("KEY"), len("ENV")
+	for _, k := range keys {
+		keyWidth = max(keyWidth, len(k.Name))
+		envWidth = max(envWidth, len(k.Env))
+	}
+	Log.Outf(logger.Default, "%-*s%-*s%s\n", keyWidth+4, "KEY", envWidth+4, "ENV", "DEFAULT")
+	for _, k := range keys {
+		Log.Outf(logger.Green, "%-*s", keyWidth+4, k.Name)
+		Log.Outf(logger.Cyan, "%-*s", envWidth+4, k.Env)
+		Log.Outf(logger.Default, "%s\n", formatDefault(k.Default))
+	}
+
+	nameWidth := len("NAME")
+	for _, e := range config.WorktreeEnv {
+		nameWidth = max(nameWidth, len(e.Name))
+	}
+	Log.Outf(logger.Default, "\nSet in worktree commands:\n")
+	Log.Outf(logger.Default, "%-*s%s\n", nameWidth+4, "NAME", "DESCRIPTION")
+	for _, e := range config.WorktreeEnv {
+		Log.Outf(logger.Cyan, "%-*s", nameWidth+4, e.Name)
+		Log.Outf(logger.Default, "%s\n", e.Description)
+	}
+	return nil
+}
+
+// formatDefault formats a config default for display; keys without one show
+// nothing.
+func formatDefault(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// writeEnvJSON writes the config keys and worktree environment as JSON.
+func writeEnvJSON(w io.Writer, keys []config.Key) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Keys        []config.Key    `json:"keys"`
+		WorktreeEnv []config.EnvDoc `json:"worktreeEnv"`
+	}{keys, config.WorktreeEnv})
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// writeEnvFile writes the configured env file into a new worktree. A file
+// already at the path, e.g. one checked into the repo, is left alone.
+func writeEnvFile(cfg config.EnvFileConfig, worktreePath string, info *worktree.WorktreeInfo) error {
+	if cfg.Template == "" {
+		return nil
+	}
+
+	path, err := envFilePath(worktreePath, cfg.Path)
+	if err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		Log.Infof("Keeping existing %s in %s\n", cfg.Path, getTildePath(worktreePath))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	if err := renderWorktreeFile("env_file.template", cfg.Template, path, worktreePath, info); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	Log.Infof("Wrote %s\n", getTildePath(path))
+	return nil
+}
+
+// envFilePath returns where the env file named name is written in the
+// worktree. name must be relative and stay inside the worktree.
+func envFilePath(worktreePath, name string) (string, error) {
+	if name == "" {
+		name = config.DefaultEnvFile
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("env_file.path %q must be a relative path inside the worktree", name)
+	}
+	return filepath.Join(worktreePath, name), nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvFilePath(t *testing.T) {
+	path, err := envFilePath("/wt", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/wt/.env", path)
+
+	path, err = envFilePath("/wt", "config/.env.local")
+	require.NoError(t, err)
+	assert.Equal(t, "/wt/config/.env.local", path)
+
+	_, err = envFilePath("/wt", "../.env")
+	assert.Error(t, err)
+	_, err = envFilePath("/wt", "/etc/env")
+	assert.Error(t, err)
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	t.Chdir(dir)
+	Log = logger.NewLogger(false, false, false)
+	t.Cleanup(func() { Log = nil })
+	_, err := ports.Assign(dir, config.PortsConfig{})
+	require.NoError(t, err)
+
+	info := &worktree.WorktreeInfo{Type: worktree.PR, Number: 12, BranchName: "fix"}
+	cfg := config.EnvFileConfig{
+		Path:     "app/.env",
+		Template: "PORT={{.Port}}\nBRANCH={{.BranchName}}\n",
+	}
+	require.NoError(t, writeEnvFile(cfg, dir, info))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app", ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=4000\nBRANCH=fix\n", string(data))
+
+	cfg.Template = "changed"
+	require.NoError(t, writeEnvFile(cfg, dir, info))
+	data, err = os.ReadFile(filepath.Join(dir, "app", ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=4000\nBRANCH=fix\n", string(data), "existing file is kept")
+}
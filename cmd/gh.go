@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	gh "github.com/cli/go-gh/v2"
+	"github.com/ffalor/gh-wt/internal/github"
+)
+
+// ghExec runs a gh command that only reads, such as gh pr view, and traces
+// the command line and duration in debug mode. Server errors and rate limits
+// reported by gh are retried with backoff.
+func ghExec(args ...string) (stdout, stderr bytes.Buffer, err error) {
+	return github.RetryExec(context.Background(), func() (stdout, stderr bytes.Buffer, err error) {
+		return ghExecOnce(args...)
+	})
+}
+
+// ghExecOnce runs a gh command once and traces it in debug mode. Use it for
+// commands that change anything, such as gh issue comment, which must not be
+// repeated when gh reports an error.
+func ghExecOnce(args ...string) (stdout, stderr bytes.Buffer, err error) {
+	start := time.Now()
+	stdout, stderr, err = gh.Exec(args...)
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	Log.Debugf("gh %s took %s: %s\n", strings.Join(args, " "), time.Since(start).Round(time.Millisecond), status)
+	return stdout, stderr, err
+}
+
+// ghExecInteractive runs a gh command connected to the terminal and traces it in debug mode.
+func ghExecInteractive(ctx context.Context, args ...string) error {
+	start := time.Now()
+	err := gh.ExecInteractive(ctx, args...)
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	Log.Debugf("gh %s took %s: %s\n", strings.Join(args, " "), time.Since(start).Round(time.Millisecond), status)
+	return err
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// applyIdentity sets user.name, user.email, and commit signing in the config
+// of the new worktree from the first identity rule matching it.
+func applyIdentity(rules []config.Identity, worktreePath string, info *worktree.WorktreeInfo) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	owner := info.Owner
+	if owner == "" {
+		owner = remoteOwner(worktreePath)
+	}
+	id, ok := matchIdentity(rules, owner, info.Repo, info.Type)
+	if !ok {
+		return nil
+	}
+
+	sign := ""
+	if id.Sign {
+		sign = "true"
+	}
+	for _, c := range []struct{ key, value string }{
+		{"user.name", id.Name},
+		{"user.email", id.Email},
+		{"user.signingkey", id.SigningKey},
+		{"gpg.format", id.SigningFormat},
+		{"commit.gpgsign", sign},
+	} {
+		if c.value == "" {
+			continue
+		}
+		if err := git.SetWorktreeConfig(worktreePath, c.key, c.value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", c.key, err)
+		}
+	}
+	if id.Email != "" {
+		Log.Infof("Using git identity %s\n", strings.TrimSpace(id.Name+" <"+id.Email+">"))
+	}
+	if id.Sign {
+		Log.Infof("Signing commits%s\n", signingDescription(id))
+	}
+	return nil
+}
+
+// matchIdentity returns the first rule whose repo glob matches owner/repo and
+// whose type matches typ. A glob without a slash is matched against the repo
+// name alone.
+func matchIdentity(rules []config.Identity, owner, repo string, typ worktree.WorktreeType) (config.Identity, bool) {
+	for _, rule := range rules {
+		if rule.Type != "" && rule.Type != string(typ) {
+			continue
+		}
+		if rule.Repo != "" {
+			target := repo
+			if strings.Contains(rule.Repo, "/") {
+				target = owner + "/" + repo
+			}
+			if ok, err := path.Match(rule.Repo, target); err != nil {
+				Log.Warnf("Invalid identity repo pattern %q: %v\n", rule.Repo, err)
+				continue
+			} else if !ok {
+				continue
+			}
+		}
+		return rule, true
+	}
+	return config.Identity{}, false
+}
+
+// signingDescription describes the format and key id signs with, e.g.
+// " (ssh, key ~/.ssh/id_ed25519.pub)", or "" when git's defaults are used.
+func signingDescription(id config.Identity) string {
+	var parts []string
+	if id.SigningFormat != "" {
+		parts = append(parts, id.SigningFormat)
+	}
+	if id.SigningKey != "" {
+		parts = append(parts, "key "+id.SigningKey)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
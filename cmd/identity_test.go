@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchIdentity(t *testing.T) {
+	rules := []config.Identity{
+		{Repo: "acme/*", Type: "pr", Email: "me@acme.example"},
+		{Repo: "acme/*", Email: "dev@acme.example"},
+		{Repo: "dotfiles", Email: "home@example.com"},
+		{Email: "oss@example.com"},
+	}
+	tests := []struct {
+		owner, repo string
+		typ         worktree.WorktreeType
+		want        string
+	}{
+		{"acme", "api", worktree.PR, "me@acme.example"},
+		{"acme", "api", worktree.Local, "dev@acme.example"},
+		{"me", "dotfiles", worktree.Issue, "home@example.com"},
+		{"ffalor", "gh-wt", worktree.PR, "oss@example.com"},
+	}
+	for _, tt := range tests {
+		id, ok := matchIdentity(rules, tt.owner, tt.repo, tt.typ)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, id.Email, "%s/%s %s", tt.owner, tt.repo, tt.typ)
+	}
+
+	_, ok := matchIdentity(rules[:1], "acme", "api", worktree.Issue)
+	assert.False(t, ok)
+}
+
+func TestSigningDescription(t *testing.T) {
+	assert.Equal(t, "", signingDescription(config.Identity{Sign: true}))
+	assert.Equal(t, " (ssh, key ~/.ssh/id_ed25519.pub)", signingDescription(config.Identity{
+		Sign: true, SigningFormat: "ssh", SigningKey: "~/.ssh/id_ed25519.pub",
+	}))
+}
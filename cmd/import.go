@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importDepthFlag  int
+	importDryRunFlag bool
+)
+
+// importNamePattern matches worktree names gh wt gives PR and issue
+// worktrees, e.g. pr_12, pr_12_merge, or issue_7.
+var importNamePattern = regexp.MustCompile(`^(pr|issue)_(\d+)(_merge)?$`)
+
+// importCmd represents the import command.
+var importCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Register existing checkouts and worktrees under a directory",
+	Long: heredoc.Doc(`
+		Scan a directory for git checkouts and worktrees and record every linked
+		worktree of the repositories found in the worktree metadata, as if it
+		had been adopted with gh wt adopt. Worktrees already known to gh wt are
+		left alone.
+
+		Worktrees named like gh wt's own (pr_<number>, issue_<number>) are
+		recorded as PR and issue worktrees; everything else is recorded as a
+		local worktree. Nothing is moved.
+
+		The scan descends --depth directories below dir (default 3) and does
+		not descend into checkouts or hidden directories.
+	`),
+	Example: heredoc.Doc(`
+		# Preview what would be imported
+		gh wt import ~/src --dry-run
+
+		# Import everything under ~/src
+		gh wt import ~/src
+	`),
+	Args:    cobra.ExactArgs(1),
+	RunE:    runImport,
+	GroupID: "worktrees",
+}
+
+func init() {
+	importCmd.Flags().IntVar(&importDepthFlag, "depth", 3, "how many directories deep to scan")
+	importCmd.Flags().BoolVar(&importDryRunFlag, "dry-run", false, "show what would be imported without recording it")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	root, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	checkouts, err := findCheckouts(root, importDepthFlag)
+	if err != nil {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		return fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+
+	// Checkouts of the same repository list the same worktrees; list each
+	// repository once, keyed by its main worktree.
+	seen := make(map[string]bool)
+	imported, repos := 0, 0
+	for _, dir := range checkouts {
+		worktrees, err := git.GetWorktreeInfoAt(dir)
+		if err != nil || len(worktrees) == 0 {
+			Log.Warnf("Skipping %s: %v\n", getTildePath(dir), err)
+			continue
+		}
+		main := worktrees[0].Path
+		if seen[main] {
+			continue
+		}
+		seen[main] = true
+
+		entries := importEntries(worktrees, remoteOwner(main), store)
+		if len(entries) > 0 {
+			repos++
+		}
+		for _, e := range entries {
+			label := getWorktreeDisplayName(e.Path)
+			if detail := historyDetail(e.Type, e.Number, ""); detail != "" {
+				label += " (" + detail + ")"
+			}
+			if importDryRunFlag {
+				Log.Outf(logger.Default, "Would import %s\n", label)
+				imported++
+				continue
+			}
+			if err := metadata.Record(e); err != nil {
+				return fmt.Errorf("failed to record worktree metadata: %w", err)
+			}
+			recordHistory(history.Event{
+				Op:       history.OpAdopt,
+				Repo:     e.Repo,
+				Worktree: e.Path,
+				Branch:   e.Branch,
+				Detail:   historyDetail(e.Type, e.Number, ""),
+			})
+			Log.Outf(logger.Green, "✓ Imported %s\n", label)
+			imported++
+		}
+	}
+
+	if imported == 0 {
+		Log.Warnf("No worktrees to import under %s\n", getTildePath(root))
+		return nil
+	}
+	verb := "Imported"
+	if importDryRunFlag {
+		verb = "Would import"
+	} else {
+		clearCompletionCache()
+	}
+	worktrees, repositories := "worktrees", "repositories"
+	if imported == 1 {
+		worktrees = "worktree"
+	}
+	if repos == 1 {
+		repositories = "repository"
+	}
+	Log.Infof("%s %d %s from %d %s\n", verb, imported, worktrees, repos, repositories)
+	return nil
+}
+
+// findCheckouts returns the directories below root, at most depth levels
+// down, that contain a .git file or directory. It does not descend into
+// checkouts or hidden directories.
+func findCheckouts(root string, depth int) ([]string, error) {
+	var checkouts []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			checkouts = append(checkouts, path)
+			return fs.SkipDir
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && strings.Count(rel, string(os.PathSeparator))+1 >= depth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return checkouts, nil
+}
+
+// importEntries returns metadata entries for the linked worktrees of a
+// repository that store does not already know. worktrees starts with the main
+// worktree, which names the repository.
+func importEntries(worktrees []git.WorktreeInfo, owner string, store *metadata.Store) []metadata.Entry {
+	if len(worktrees) == 0 {
+		return nil
+	}
+	repo := filepath.Base(worktrees[0].Path)
+	now := time.Now()
+	var entries []metadata.Entry
+	for _, wt := range worktrees[1:] {
+		if _, ok := store.Get(wt.Path); ok {
+			continue
+		}
+		e := metadata.Entry{
+			Path:      wt.Path,
+			Name:      filepath.Base(wt.Path),
+			Branch:    worktreeBranch(wt),
+			Type:      worktree.Local,
+			Repo:      repo,
+			CreatedAt: now,
+			Adopted:   true,
+		}
+		if m := importNamePattern.FindStringSubmatch(e.Name); m != nil {
+			e.Type = worktree.PR
+			if m[1] == "issue" {
+				e.Type = worktree.Issue
+			}
+			e.Number, _ = strconv.Atoi(m[2])
+			e.Owner = owner
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// remoteOwner returns the owner of the origin remote of the repository at
+// dir, parsed from its URL, or "" if it has none.
+func remoteOwner(dir string) string {
+	out, err := git.CommandOutputAt(dir, "config", "--get", "remote.origin.url")
+	if err != nil {
+		return ""
+	}
+	return ownerFromRemoteURL(strings.TrimSpace(out))
+}
+
+// ownerFromRemoteURL returns the owner in a remote URL such as
+// https://github.com/owner/repo.git or git@github.com:owner/repo.git.
+func ownerFromRemoteURL(remote string) string {
+	owner, _, _ := strings.Cut(repoFromRemoteURL(remote), "/")
+	return owner
+}
+
+// repoFromRemoteURL returns the "owner/repo" in a remote URL such as
+// https://github.com/owner/repo.git or git@github.com:owner/repo.git, or ""
+// if it has none.
+func repoFromRemoteURL(remote string) string {
+	var path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" {
+		path = u.Path
+	} else if _, after, ok := strings.Cut(remote, ":"); ok {
+		path = after
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + strings.TrimSuffix(parts[len(parts)-1], ".git")
+}
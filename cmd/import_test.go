@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCheckouts(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"app/.git", "app/sub/.git", "team/lib/.git", "a/b/c/deep/.git", ".hidden/x/.git"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "wts", "pr_1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "wts", "pr_1", ".git"), []byte("gitdir: /x\n"), 0o644))
+
+	got, err := findCheckouts(root, 3)
+	require.NoError(t, err)
+	for i := range got {
+		got[i], _ = filepath.Rel(root, got[i])
+	}
+	assert.ElementsMatch(t, []string{"app", filepath.Join("team", "lib"), filepath.Join("wts", "pr_1")}, got)
+}
+
+func TestImportEntries(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	require.NoError(t, metadata.Record(metadata.Entry{Path: "/src/app-known"}))
+	store, err := metadata.Load()
+	require.NoError(t, err)
+
+	entries := importEntries([]git.WorktreeInfo{
+		{Path: "/src/app", Branch: "main"},
+		{Path: "/src/app-known", Branch: "known"},
+		{Path: "/wts/pr_12", Branch: "fix"},
+		{Path: "/wts/issue_7", Branch: "issue_7"},
+		{Path: "/wts/spike", Detached: true},
+	}, "acme", store)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, worktree.PR, entries[0].Type)
+	assert.Equal(t, 12, entries[0].Number)
+	assert.Equal(t, "acme", entries[0].Owner)
+	assert.Equal(t, "app", entries[0].Repo)
+	assert.Equal(t, worktree.Issue, entries[1].Type)
+	assert.Equal(t, worktree.Local, entries[2].Type)
+	assert.Empty(t, entries[2].Owner)
+	assert.True(t, entries[2].Adopted)
+}
+
+func TestOwnerFromRemoteURL(t *testing.T) {
+	assert.Equal(t, "acme", ownerFromRemoteURL("https://github.com/acme/app.git"))
+	assert.Equal(t, "acme", ownerFromRemoteURL("git@github.com:acme/app.git"))
+	assert.Equal(t, "acme", ownerFromRemoteURL("ssh://git@github.com/acme/app"))
+	assert.Empty(t, ownerFromRemoteURL("/srv/git/app.git"))
+}
+
+func TestRepoFromRemoteURL(t *testing.T) {
+	assert.Equal(t, "acme/app", repoFromRemoteURL("https://github.com/acme/app.git"))
+	assert.Equal(t, "acme/app", repoFromRemoteURL("git@github.com:acme/app.git"))
+	assert.Empty(t, repoFromRemoteURL("/srv/git/app.git"))
+}
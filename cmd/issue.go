@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	issueCloseCommentFlag string
+	issueCloseReasonFlag  string
+	issueCloseLinkPRFlag  bool
+)
+
+// issueCmd groups commands acting on the issue linked to a worktree.
+var issueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "Manage the issue linked to a worktree",
+	Long: heredoc.Doc(`
+		Manage the GitHub issue an issue worktree was created from.
+	`),
+	GroupID: "worktrees",
+}
+
+// issueCloseCmd represents the issue close command.
+var issueCloseCmd = &cobra.Command{
+	Use:   "close <worktree|number|url>",
+	Short: "Close the issue linked to a worktree",
+	Long: heredoc.Doc(`
+		Close the issue an issue worktree was created from, without looking up
+		its number.
+
+		With --link-pr, the merged pull request whose head is the worktree's
+		branch is looked up and referenced in a closing comment.
+	`),
+	Example: heredoc.Doc(`
+		# Close the issue of a worktree
+		gh wt issue close issue_456
+
+		# Close it with a comment referencing the merged PR
+		gh wt issue close issue_456 --link-pr
+
+		# Close it as not planned
+		gh wt issue close issue_456 --reason "not planned" --comment "Superseded by #500"
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runIssueClose,
+	ValidArgsFunction: completeWorktrees,
+}
+
+func init() {
+	issueCloseCmd.Flags().StringVarP(&issueCloseCommentFlag, "comment", "c", "", "leave a closing comment")
+	issueCloseCmd.Flags().StringVarP(&issueCloseReasonFlag, "reason", "r", "", "reason for closing: completed or \"not planned\"")
+	issueCloseCmd.Flags().BoolVar(&issueCloseLinkPRFlag, "link-pr", false, "reference the merged PR of the worktree's branch in the closing comment")
+	issueCmd.AddCommand(issueCloseCmd)
+	rootCmd.AddCommand(issueCmd)
+}
+
+func runIssueClose(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	e, err := linkedIssue(wt)
+	if err != nil {
+		return err
+	}
+
+	pr := 0
+	if issueCloseLinkPRFlag {
+		pr, err = mergedPullRequest(e.Owner, e.Repo, worktreeBranch(wt))
+		if err != nil {
+			return err
+		}
+		if pr == 0 {
+			Log.Warnf("No merged PR found for branch '%s'\n", worktreeBranch(wt))
+		}
+	}
+	return closeIssue(e, issueCloseBody(issueCloseCommentFlag, pr), issueCloseReasonFlag)
+}
+
+// linkedIssue returns the metadata of the GitHub issue worktree wt was
+// created from.
+func linkedIssue(wt git.WorktreeInfo) (metadata.Entry, error) {
+	store, err := metadata.Load()
+	if err != nil {
+		return metadata.Entry{}, fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+	e, ok := store.Get(wt.Path)
+	if !ok || e.Type != worktree.Issue || e.Number == 0 {
+		return metadata.Entry{}, fmt.Errorf("worktree %s is not linked to an issue", getWorktreeDisplayName(wt.Path))
+	}
+	if e.Provider != "" {
+		return metadata.Entry{}, fmt.Errorf("closing %s issues is not supported", e.Provider)
+	}
+	if e.Owner == "" {
+		owner, _, err := currentRepo()
+		if err != nil || owner == "" {
+			return metadata.Entry{}, fmt.Errorf("failed to determine the owner of issue #%d", e.Number)
+		}
+		e.Owner = owner
+	}
+	return e, nil
+}
+
+// mergedPullRequest returns the number of the most recently merged pull
+// request whose head is branch, or 0 if there is none.
+func mergedPullRequest(owner, repo, branch string) (int, error) {
+	return branchPullRequest(owner, repo, branch, "merged")
+}
+
+// branchPullRequest returns the number of the most recent pull request in
+// state (open, closed, merged) whose head is branch, or 0 if there is none.
+func branchPullRequest(owner, repo, branch, state string) (int, error) {
+	if branch == "" {
+		return 0, nil
+	}
+	stdout, stderr, err := ghExec("pr", "list", "--repo", owner+"/"+repo, "--head", branch,
+		"--state", state, "--limit", "1", "--json", "number")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the %s PR of '%s': %w: %s", state, branch, err, strings.TrimSpace(stderr.String()))
+	}
+	var prs []struct{ Number int }
+	if err := json.Unmarshal(stdout.Bytes(), &prs); err != nil {
+		return 0, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	if len(prs) == 0 {
+		return 0, nil
+	}
+	return prs[0].Number, nil
+}
+
+// issueCloseBody returns the closing comment: a reference to the merged pull
+// request pr, if any, followed by comment.
+func issueCloseBody(comment string, pr int) string {
+	var parts []string
+	if pr != 0 {
+		parts = append(parts, fmt.Sprintf("Fixed by #%d.", pr))
+	}
+	if c := strings.TrimSpace(comment); c != "" {
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// closeIssue closes the issue of e with an optional comment and reason.
+func closeIssue(e metadata.Entry, comment, reason string) error {
+	args := []string{"issue", "close", strconv.Itoa(e.Number), "--repo", e.Owner + "/" + e.Repo}
+	if comment != "" {
+		args = append(args, "--comment", comment)
+	}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	Log.Infof("Closing issue #%d...\n", e.Number)
+	if _, stderr, err := ghExecOnce(args...); err != nil {
+		return fmt.Errorf("failed to close issue #%d: %w: %s", e.Number, err, strings.TrimSpace(stderr.String()))
+	}
+	Log.Outf(logger.Green, "✓ Closed issue #%d\n", e.Number)
+	return nil
+}
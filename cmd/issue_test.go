@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueCloseBody(t *testing.T) {
+	assert.Equal(t, "", issueCloseBody("  ", 0))
+	assert.Equal(t, "Fixed by #12.", issueCloseBody("", 12))
+	assert.Equal(t, "Done", issueCloseBody("Done\n", 0))
+	assert.Equal(t, "Fixed by #12.\n\nThanks for the report!", issueCloseBody("Thanks for the report!", 12))
+}
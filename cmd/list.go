@@ -1,19 +1,36 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
 	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
-var allFlag bool
+var (
+	allFlag      bool
+	listJSONFlag bool
+	listTagFlag  []string
+	listSortFlag string
+	listTreeFlag bool
+	listCurrent  bool
+	listDetached bool
+)
 
 // listCmd represents the list command.
 var listCmd = &cobra.Command{
@@ -21,7 +38,7 @@ var listCmd = &cobra.Command{
 	Short: "List managed worktrees",
 	Long: heredoc.Doc(`
 		List all worktrees managed by gh-wt (those under the configured worktree directory).
-		Displays the worktree name and associated branch.
+		Displays the worktree name, associated branch, and tags.
 	`),
 	Example: heredoc.Doc(`
 		# List all worktrees
@@ -30,6 +47,24 @@ var listCmd = &cobra.Command{
 		# List worktrees across all repos
 		gh wt list --all
 
+		# List worktrees tagged urgent
+		gh wt list --tag urgent
+
+		# List the most recently used worktrees first
+		gh wt list --sort last-used
+
+		# Print worktrees with PR state, review decision, and checks as JSON
+		gh wt list --json
+
+		# Show worktrees across all repos as a tree, with counts per repo
+		gh wt list --all --tree
+
+		# Show the worktree you are in; exits 1 outside a managed worktree
+		gh wt list --current
+
+		# List worktrees with no branch checked out
+		gh wt list --detached
+
 		# Using the alias
 		gh wt ls
 	`),
@@ -42,6 +77,16 @@ var listCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "list worktrees for all repos")
+	listCmd.Flags().BoolVar(&listJSONFlag, "json", false, "print worktrees as JSON, including the status of PR worktrees")
+	listCmd.Flags().StringVar(&listSortFlag, "sort", "", "sort by name, created, or last-used (most recent first)")
+	listCmd.Flags().StringSliceVarP(&listTagFlag, "tag", "t", nil, "only list worktrees with all of these tags")
+	listCmd.Flags().BoolVar(&listTreeFlag, "tree", false, "show worktrees as a tree under their repo, with counts per repo")
+	listCmd.Flags().BoolVar(&listCurrent, "current", false, "only show the worktree containing the current directory; exit 1 if there is none")
+	listCmd.Flags().BoolVar(&listDetached, "detached", false, "only list worktrees with a detached HEAD")
+	listCmd.MarkFlagsMutuallyExclusive("tree", "json")
+	listCmd.MarkFlagsMutuallyExclusive("current", "all")
+	listCmd.MarkFlagsMutuallyExclusive("current", "tree")
+	listCmd.MarkFlagsMutuallyExclusive("current", "detached")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -50,6 +95,21 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	switch listSortFlag {
+	case "", "name", "created", "last-used":
+	default:
+		return fmt.Errorf("invalid --sort %q (expected name, created, or last-used)", listSortFlag)
+	}
+
+	if listCurrent {
+		return runListCurrent(cmd.OutOrStdout(), cfg)
+	}
+	if listJSONFlag {
+		return runListJSON(cmd.OutOrStdout(), cfg)
+	}
+	if listTreeFlag {
+		return runListTree(cfg)
+	}
 	if allFlag {
 		return runListAll(cfg)
 	}
@@ -59,47 +119,79 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
-	filtered := filterWorktreesByBase(worktrees, cfg.WorktreeBase)
+	store := loadListMetadata()
+	filtered := filterWorktreesByTags(filterManaged(worktrees, cfg.WorktreeBase, store), store, listTagFlag)
+	filtered = filterDetached(filtered, listDetached)
+	sortWorktrees(filtered, store, listSortFlag)
 
 	if len(filtered) == 0 {
 		Log.Warnf("No worktrees found under %s\n", cfg.WorktreeBase)
 		return nil
 	}
 
-	// Build entries and compute max name width
-	type entry struct{ name, branch string }
+	// Build entries and compute column widths
+	type entry struct{ name, branch, tags string }
 	entries := make([]entry, 0, len(filtered))
 	maxWidth := len("NAME")
+	branchWidth := len("BRANCH")
+	hasTags := false
 	for _, wt := range filtered {
-		name := getWorktreeDisplayName(wt.Path)
+		name := getWorktreeDisplayName(wt.Path) + worktreeMarks(store, wt.Path)
 		branch := wt.Branch
 		if branch == "" {
 			branch = "(detached)"
 		}
+		tags := strings.Join(worktreeTags(store, wt.Path), ", ")
 		if len(name) > maxWidth {
 			maxWidth = len(name)
 		}
-		entries = append(entries, entry{name, branch})
+		branchWidth = max(branchWidth, len(branch))
+		hasTags = hasTags || tags != ""
+		entries = append(entries, entry{name, branch, tags})
 	}
 
 	// Header
-	Log.Outf(logger.Default, "%-*s%s\n", maxWidth+4, "NAME", "BRANCH")
+	if hasTags {
+		Log.Outf(logger.Default, "%-*s%-*s%s\n", maxWidth+4, "NAME", branchWidth+4, "BRANCH", "TAGS")
+	} else {
+		Log.Outf(logger.Default, "%-*s%s\n", maxWidth+4, "NAME", "BRANCH")
+	}
 
 	// Rows
 	for _, e := range entries {
 		Log.Outf(logger.Green, "%-*s", maxWidth+4, e.name)
-		Log.Outf(logger.Default, "%s\n", e.branch)
+		if hasTags {
+			Log.Outf(logger.Default, "%-*s", branchWidth+4, e.branch)
+			Log.Outf(logger.Cyan, "%s\n", e.tags)
+		} else {
+			Log.Outf(logger.Default, "%s\n", e.branch)
+		}
 	}
 
 	return nil
 }
 
+// loadListMetadata loads the metadata store for list, warning and returning
+// nil when it cannot be read.
+func loadListMetadata() *metadata.Store {
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+		return nil
+	}
+	return store
+}
+
 func runListAll(cfg config.Config) error {
 	worktrees, err := git.ListAllWorktrees(cfg.WorktreeBase)
 	if err != nil {
 		return fmt.Errorf("failed to list all worktrees: %w", err)
 	}
 
+	store := loadListMetadata()
+	worktrees = filterDetached(filterWorktreesByTags(worktrees, store, listTagFlag), listDetached)
+	sortWorktrees(worktrees, store, listSortFlag)
+
 	if len(worktrees) == 0 {
 		Log.Warnf("No worktrees found under %s\n", cfg.WorktreeBase)
 		return nil
@@ -107,13 +199,17 @@ func runListAll(cfg config.Config) error {
 
 	groups := groupWorktreesByRepo(worktrees, cfg.WorktreeBase)
 
-	// Compute a global max name width across all groups for consistent column alignment
+	// Compute global column widths across all groups for consistent alignment
 	maxWidth := len("NAME")
+	branchWidth := len("BRANCH")
+	hasTags := false
 	for _, wt := range worktrees {
-		name := filepath.Base(wt.Path)
+		name := filepath.Base(wt.Path) + worktreeMarks(store, wt.Path)
 		if len(name) > maxWidth {
 			maxWidth = len(name)
 		}
+		branchWidth = max(branchWidth, len(wt.Branch), len("(detached)"))
+		hasTags = hasTags || len(worktreeTags(store, wt.Path)) > 0
 	}
 
 	for i, group := range groups {
@@ -125,24 +221,302 @@ func runListAll(cfg config.Config) error {
 		Log.Outf(logger.Default, "%s\n", group.repo)
 
 		// Indented header
-		Log.Outf(logger.Default, "  %-*s%s\n", maxWidth+4, "NAME", "BRANCH")
+		if hasTags {
+			Log.Outf(logger.Default, "  %-*s%-*s%s\n", maxWidth+4, "NAME", branchWidth+4, "BRANCH", "TAGS")
+		} else {
+			Log.Outf(logger.Default, "  %-*s%s\n", maxWidth+4, "NAME", "BRANCH")
+		}
 
 		// Indented rows
 		for _, wt := range group.worktrees {
-			name := filepath.Base(wt.Path)
+			name := filepath.Base(wt.Path) + worktreeMarks(store, wt.Path)
 			branch := wt.Branch
 			if branch == "" {
 				branch = "(detached)"
 			}
 			Log.Plainf("  ")
 			Log.Outf(logger.Green, "%-*s", maxWidth+4, name)
-			Log.Outf(logger.Default, "%s\n", branch)
+			if hasTags {
+				Log.Outf(logger.Default, "%-*s", branchWidth+4, branch)
+				Log.Outf(logger.Cyan, "%s\n", strings.Join(worktreeTags(store, wt.Path), ", "))
+			} else {
+				Log.Outf(logger.Default, "%s\n", branch)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runListTree prints the worktrees of the current repo, or of all repos with
+// --all, nested under their repo with a summary of each repo.
+func runListTree(cfg config.Config) error {
+	var worktrees []git.WorktreeInfo
+	if allFlag {
+		all, err := git.ListAllWorktrees(cfg.WorktreeBase)
+		if err != nil {
+			return fmt.Errorf("failed to list all worktrees: %w", err)
+		}
+		worktrees = all
+	} else {
+		current, err := git.GetWorktreeInfo()
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		worktrees = filterWorktreesByBase(current, cfg.WorktreeBase)
+	}
+
+	store := loadListMetadata()
+	worktrees = filterDetached(filterWorktreesByTags(worktrees, store, listTagFlag), listDetached)
+	sortWorktrees(worktrees, store, listSortFlag)
+
+	if len(worktrees) == 0 {
+		Log.Warnf("No worktrees found under %s\n", cfg.WorktreeBase)
+		return nil
+	}
+
+	nameWidth, branchWidth := 0, 0
+	for _, wt := range worktrees {
+		nameWidth = max(nameWidth, len(filepath.Base(wt.Path)+worktreeMarks(store, wt.Path)))
+		branchWidth = max(branchWidth, len(wt.Branch), len("(detached)"))
+	}
+
+	for i, group := range groupWorktreesByRepo(worktrees, cfg.WorktreeBase) {
+		if i > 0 {
+			Log.Plainf("\n")
+		}
+		Log.Outf(logger.Default, "%s (%s)\n", group.repo, repoSummary(group.worktrees, store))
+
+		for j, wt := range group.worktrees {
+			branch := wt.Branch
+			if branch == "" {
+				branch = "(detached)"
+			}
+			connector := "├── "
+			if j == len(group.worktrees)-1 {
+				connector = "└── "
+			}
+			Log.Plainf("%s", connector)
+			Log.Outf(logger.Green, "%-*s  ", nameWidth, filepath.Base(wt.Path)+worktreeMarks(store, wt.Path))
+			tags := strings.Join(worktreeTags(store, wt.Path), ", ")
+			if tags == "" {
+				Log.Outf(logger.Default, "%s\n", branch)
+				continue
+			}
+			Log.Outf(logger.Default, "%-*s  ", branchWidth, branch)
+			Log.Outf(logger.Cyan, "%s\n", tags)
+		}
+	}
+	return nil
+}
+
+// repoSummary counts a repo's worktrees by type, e.g.
+// "3 worktrees: 2 PRs, 1 issue".
+func repoSummary(worktrees []git.WorktreeInfo, store *metadata.Store) string {
+	counts := make(map[worktree.WorktreeType]int)
+	for _, wt := range worktrees {
+		typ := worktree.Local
+		if store != nil {
+			if e, ok := store.Get(wt.Path); ok && e.Type != "" {
+				typ = e.Type
+			}
+		}
+		counts[typ]++
+	}
+
+	count := func(n int, one, many string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, many)
+	}
+	var parts []string
+	for _, c := range []struct {
+		typ       worktree.WorktreeType
+		one, many string
+	}{{worktree.PR, "PR", "PRs"}, {worktree.Issue, "issue", "issues"}, {worktree.Local, "local", "local"}} {
+		if counts[c.typ] > 0 {
+			parts = append(parts, count(counts[c.typ], c.one, c.many))
+		}
+	}
+	return count(len(worktrees), "worktree", "worktrees") + ": " + strings.Join(parts, ", ")
+}
+
+// listEntry is a worktree as printed by list --json.
+type listEntry struct {
+	Name        string                    `json:"name"`
+	Path        string                    `json:"path"`
+	Branch      string                    `json:"branch"`
+	Detached    bool                      `json:"detached,omitempty"`
+	Repo        string                    `json:"repo"`
+	Type        worktree.WorktreeType     `json:"type"`
+	Owner       string                    `json:"owner,omitempty"`
+	Number      int                       `json:"number,omitempty"`
+	Title       string                    `json:"title,omitempty"`
+	Provider    string                    `json:"provider,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Pinned      bool                      `json:"pinned,omitempty"`
+	BaseBranch  string                    `json:"baseBranch,omitempty"`
+	StackedOn   int                       `json:"stackedOn,omitempty"`
+	CreatedAt   time.Time                 `json:"createdAt,omitzero"`
+	LastUsedAt  time.Time                 `json:"lastUsedAt,omitzero"`
+	PullRequest *github.PullRequestStatus `json:"pullRequest,omitempty"`
+}
+
+// runListJSON prints the worktrees as JSON. The status of all PR worktrees is
+// fetched with a single batched query.
+func runListJSON(w io.Writer, cfg config.Config) error {
+	store := loadListMetadata()
+	var worktrees []git.WorktreeInfo
+	if allFlag {
+		all, err := git.ListAllWorktrees(cfg.WorktreeBase)
+		if err != nil && !os.IsNotExist(errors.Unwrap(err)) {
+			return fmt.Errorf("failed to list all worktrees: %w", err)
 		}
+		worktrees = all
+	} else {
+		current, err := git.GetWorktreeInfo()
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		worktrees = filterManaged(current, cfg.WorktreeBase, store)
+	}
+
+	worktrees = filterDetached(filterWorktreesByTags(worktrees, store, listTagFlag), listDetached)
+	sortWorktrees(worktrees, store, listSortFlag)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(listEntries(worktrees, cfg, store))
+}
+
+// runListCurrent prints the managed worktree containing the current
+// directory: its name, branch, and linked PR or issue, or with --json its list
+// entry. Outside a managed worktree it exits with status 1.
+func runListCurrent(w io.Writer, cfg config.Config) error {
+	wt, ok := currentWorktree(cfg.WorktreeBase)
+	if !ok {
+		Log.Warnf("Not inside a worktree under %s\n", cfg.WorktreeBase)
+		return silentExit(1)
 	}
+	store := loadListMetadata()
 
+	if listJSONFlag {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listEntries([]git.WorktreeInfo{wt}, cfg, store)[0])
+	}
+
+	var entry metadata.Entry
+	if store != nil {
+		entry, _ = store.Get(wt.Path)
+	}
+	Log.Outf(logger.Green, "%s", getWorktreeDisplayName(wt.Path))
+	Log.Outf(logger.Default, "  %s\n", worktreeDescription(wt, entry))
 	return nil
 }
 
+// currentWorktree returns the worktree under baseDir containing the current
+// directory.
+func currentWorktree(baseDir string) (git.WorktreeInfo, bool) {
+	root, err := git.GetGitRoot()
+	if err != nil {
+		return git.WorktreeInfo{}, false
+	}
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil {
+		return git.WorktreeInfo{}, false
+	}
+	store, _ := metadata.Load()
+	for _, wt := range filterManaged(worktrees, baseDir, store) {
+		if wt.Path == root {
+			return wt, true
+		}
+	}
+	return git.WorktreeInfo{}, false
+}
+
+// newListEntry describes wt, a worktree under base or adopted, from its
+// metadata in store, which may be nil.
+func newListEntry(wt git.WorktreeInfo, base string, store *metadata.Store) listEntry {
+	e := listEntry{
+		Name:     filepath.Base(wt.Path),
+		Path:     wt.Path,
+		Branch:   wt.Branch,
+		Detached: wt.Detached,
+		Type:     worktree.Local,
+	}
+	if rel, err := filepath.Rel(base, wt.Path); err == nil {
+		e.Repo, _, _ = strings.Cut(filepath.ToSlash(rel), "/")
+	}
+	if store != nil {
+		if m, ok := store.Get(wt.Path); ok {
+			e.Type, e.Owner, e.Number, e.Provider, e.Tags = m.Type, m.Owner, m.Number, m.Provider, m.Tags
+			e.Title, e.Pinned = m.Title, m.Pinned
+			e.BaseBranch, e.StackedOn = m.BaseBranch, m.StackedOn
+			e.CreatedAt, e.LastUsedAt = m.CreatedAt, m.LastUsedAt
+			if m.Repo != "" {
+				e.Repo = m.Repo
+			}
+		}
+	}
+	return e
+}
+
+// listEntries describes worktrees for list --json. The status of all PR
+// worktrees is fetched with a single batched query.
+func listEntries(worktrees []git.WorktreeInfo, cfg config.Config, store *metadata.Store) []listEntry {
+	entries := make([]listEntry, 0, len(worktrees))
+	var refs []github.PullRequestRef
+	for _, wt := range worktrees {
+		e := newListEntry(wt, cfg.WorktreeBase, store)
+		if e.Type == worktree.PR && e.Owner != "" && e.Provider == "" {
+			refs = append(refs, github.PullRequestRef{Owner: e.Owner, Repo: e.Repo, Number: e.Number})
+		}
+		entries = append(entries, e)
+	}
+
+	if len(refs) > 0 && !gitOnly() {
+		statuses, err := github.GetPullRequestStatuses(context.Background(), refs)
+		if err != nil {
+			Log.Warnf("%v\n", err)
+		}
+		for i := range entries {
+			e := &entries[i]
+			if s, ok := statuses[github.PullRequestRef{Owner: e.Owner, Repo: e.Repo, Number: e.Number}]; ok && e.Type == worktree.PR && e.Provider == "" {
+				e.PullRequest = &s
+			}
+		}
+	}
+
+	return entries
+}
+
+// sortWorktrees sorts worktrees in place by name, or by their created or
+// last-used time with the most recent first. Worktrees without a recorded time
+// sort last. An empty key keeps git's order.
+func sortWorktrees(worktrees []git.WorktreeInfo, store *metadata.Store, by string) {
+	if by == "" {
+		return
+	}
+	timeOf := func(wt git.WorktreeInfo) time.Time {
+		if store == nil {
+			return time.Time{}
+		}
+		e, _ := store.Get(wt.Path)
+		if by == "created" {
+			return e.CreatedAt
+		}
+		return e.LastUsedAt
+	}
+	slices.SortStableFunc(worktrees, func(a, b git.WorktreeInfo) int {
+		if by == "name" {
+			return strings.Compare(filepath.Base(a.Path), filepath.Base(b.Path))
+		}
+		return timeOf(b).Compare(timeOf(a))
+	})
+}
+
 type repoGroup struct {
 	repo      string
 	worktrees []git.WorktreeInfo
@@ -175,6 +549,38 @@ func groupWorktreesByRepo(worktrees []git.WorktreeInfo, baseDir string) []repoGr
 	return groups
 }
 
+// filterDetached returns the detached worktrees when detached is set, and all
+// worktrees otherwise.
+func filterDetached(worktrees []git.WorktreeInfo, detached bool) []git.WorktreeInfo {
+	if !detached {
+		return worktrees
+	}
+	var filtered []git.WorktreeInfo
+	for _, wt := range worktrees {
+		if wt.Detached {
+			filtered = append(filtered, wt)
+		}
+	}
+	return filtered
+}
+
+// filterManaged returns the worktrees managed by gh-wt: those under base and
+// those adopted from elsewhere with gh wt adopt.
+func filterManaged(worktrees []git.WorktreeInfo, base string, store *metadata.Store) []git.WorktreeInfo {
+	var filtered []git.WorktreeInfo
+	prefix := base + string(os.PathSeparator)
+	for _, wt := range worktrees {
+		if strings.HasPrefix(wt.Path, prefix) {
+			filtered = append(filtered, wt)
+		} else if store != nil {
+			if e, ok := store.Get(wt.Path); ok && e.Adopted {
+				filtered = append(filtered, wt)
+			}
+		}
+	}
+	return filtered
+}
+
 // filterWorktreesByBase returns only worktrees under the configured base directory.
 func filterWorktreesByBase(worktrees []git.WorktreeInfo, base string) []git.WorktreeInfo {
 	var filtered []git.WorktreeInfo
@@ -145,6 +145,30 @@ func TestListWithWorktreesIntegration(t *testing.T) {
 		assert.Contains(t, outputStr, "feature-2")
 	})
 
+	t.Run("list --current shows the worktree containing the cwd", func(t *testing.T) {
+		cmd := exec.Command(setup.binaryPath, "list", "--current", "--no-color")
+		cmd.Dir = wt2Path
+		cmd.Env = append(os.Environ(), "GH_WT_WORKTREE_DIR="+worktreeBase)
+		output, err := cmd.CombinedOutput()
+		outputStr := string(output)
+
+		require.NoError(t, err, "list --current should succeed: %s", outputStr)
+		assert.Contains(t, outputStr, "feature-2")
+		assert.NotContains(t, outputStr, "feature-1")
+	})
+
+	t.Run("list --current exits 1 outside a managed worktree", func(t *testing.T) {
+		cmd := exec.Command(setup.binaryPath, "list", "--current", "--no-color")
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GH_WT_WORKTREE_DIR="+worktreeBase)
+		output, err := cmd.CombinedOutput()
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+		assert.NotContains(t, string(output), "Usage:")
+	})
+
 	// Cleanup worktrees
 	gitWtRm1 := exec.Command("git", "worktree", "remove", wt1Path, "--force")
 	gitWtRm1.Dir = repoDir
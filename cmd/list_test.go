@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
 )
 
 func TestListCmd_Structure(t *testing.T) {
@@ -208,3 +211,77 @@ func TestGroupWorktreesByRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestSortWorktrees(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	store, err := metadata.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Put(metadata.Entry{Path: "/wt/repo/a", CreatedAt: base, LastUsedAt: base.Add(2 * time.Hour)})
+	store.Put(metadata.Entry{Path: "/wt/repo/b", CreatedAt: base.Add(time.Hour), LastUsedAt: base.Add(time.Hour)})
+
+	tests := []struct {
+		by       string
+		expected []string
+	}{
+		{by: "", expected: []string{"/wt/repo/c", "/wt/repo/b", "/wt/repo/a"}},
+		{by: "name", expected: []string{"/wt/repo/a", "/wt/repo/b", "/wt/repo/c"}},
+		{by: "created", expected: []string{"/wt/repo/b", "/wt/repo/a", "/wt/repo/c"}},
+		{by: "last-used", expected: []string{"/wt/repo/a", "/wt/repo/b", "/wt/repo/c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			worktrees := []git.WorktreeInfo{{Path: "/wt/repo/c"}, {Path: "/wt/repo/b"}, {Path: "/wt/repo/a"}}
+			sortWorktrees(worktrees, store, tt.by)
+			for i, wt := range worktrees {
+				if wt.Path != tt.expected[i] {
+					t.Errorf("position %d: expected %s, got %s", i, tt.expected[i], wt.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestRepoSummary(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	for _, e := range []metadata.Entry{
+		{Path: "/wt/repo/pr_1", Type: worktree.PR, Number: 1},
+		{Path: "/wt/repo/pr_2", Type: worktree.PR, Number: 2},
+		{Path: "/wt/repo/issue_3", Type: worktree.Issue, Number: 3},
+	} {
+		if err := metadata.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees := []git.WorktreeInfo{
+		{Path: "/wt/repo/pr_1"}, {Path: "/wt/repo/pr_2"}, {Path: "/wt/repo/issue_3"}, {Path: "/wt/repo/spike"},
+	}
+	if got, want := repoSummary(worktrees, store), "4 worktrees: 2 PRs, 1 issue, 1 local"; got != want {
+		t.Errorf("repoSummary() = %q, want %q", got, want)
+	}
+	if got, want := repoSummary(worktrees[3:], nil), "1 worktree: 1 local"; got != want {
+		t.Errorf("repoSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestFilterDetached(t *testing.T) {
+	worktrees := []git.WorktreeInfo{
+		{Path: "/wt/repo/pr_1", Branch: "fix"},
+		{Path: "/wt/repo/bisect", Detached: true},
+	}
+	if got := filterDetached(worktrees, false); len(got) != 2 {
+		t.Errorf("filterDetached(false) returned %d worktrees, want 2", len(got))
+	}
+	got := filterDetached(worktrees, true)
+	if len(got) != 1 || got[0].Path != "/wt/repo/bisect" {
+		t.Errorf("filterDetached(true) = %v, want only /wt/repo/bisect", got)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/lock"
+)
+
+// lockRepoDir takes the lock of a repository's worktree directory, so two gh
+// wt processes don't run git worktree commands on it at once. While another
+// process holds it, lockRepoDir says who and waits up to lock_timeout.
+func lockRepoDir(dir string) (*lock.Lock, error) {
+	timeout := 0
+	if cfg, err := config.Get(); err == nil {
+		timeout = cfg.LockTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	command := "gh wt " + strings.Join(os.Args[1:], " ")
+	l, err := lock.Acquire(ctx, filepath.Join(dir, lock.FileName), command, func(h lock.Holder) {
+		Log.Warnf("Waiting for %s to finish...\n", h)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", getTildePath(dir), err)
+	}
+	return l, nil
+}
+
+// unlockRepoDir releases a lock taken by lockRepoDir.
+func unlockRepoDir(l *lock.Lock) {
+	if err := l.Release(); err != nil {
+		Log.Warnf("%v\n", err)
+	}
+}
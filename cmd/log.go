@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logRepoFlag     string
+	logWorktreeFlag string
+	logSinceFlag    string
+	logLimitFlag    int
+	logJSONFlag     bool
+)
+
+// logCmd represents the log command.
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the history of worktrees added and removed",
+	Long: heredoc.Doc(`
+		Show the operations gh wt has performed, most recent first: when each
+		worktree was added or removed, its branch, and the PR or issue it was
+		created from.
+
+		The history is kept in history.jsonl in the state directory
+		($XDG_STATE_HOME/gh-wt, or ~/.local/state/gh-wt).
+	`),
+	Example: heredoc.Doc(`
+		# Show recent operations
+		gh wt log
+
+		# Show operations on one repo in the last week
+		gh wt log --repo gh-wt --since 7d
+
+		# Show everything that happened to a worktree
+		gh wt log --worktree pr_123
+
+		# Print the history as JSON
+		gh wt log --json
+	`),
+	Args:    cobra.NoArgs,
+	RunE:    runLog,
+	GroupID: "utilities",
+}
+
+func init() {
+	logCmd.Flags().StringVar(&logRepoFlag, "repo", "", "only show operations on worktrees of this repo")
+	logCmd.Flags().StringVarP(&logWorktreeFlag, "worktree", "w", "", "only show operations on this worktree (name or path)")
+	logCmd.Flags().StringVar(&logSinceFlag, "since", "", "only show operations since a date (2006-01-02) or for a duration (e.g. 7d, 12h)")
+	logCmd.Flags().IntVarP(&logLimitFlag, "limit", "n", 20, "maximum number of operations to show (0 for all)")
+	logCmd.Flags().BoolVar(&logJSONFlag, "json", false, "print operations as JSON")
+	rootCmd.AddCommand(logCmd)
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	var since time.Time
+	if logSinceFlag != "" {
+		t, err := parseSince(logSinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+		since = t
+	}
+
+	events, err := history.Read()
+	if err != nil {
+		return err
+	}
+	events = filterHistory(events, logRepoFlag, logWorktreeFlag, since)
+	if logLimitFlag > 0 && len(events) > logLimitFlag {
+		events = events[:logLimitFlag]
+	}
+
+	if logJSONFlag {
+		if events == nil {
+			events = []history.Event{}
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(events)
+	}
+
+	if len(events) == 0 {
+		Log.Warnf("No operations recorded\n")
+		return nil
+	}
+
+	nameWidth, branchWidth := len("WORKTREE"), len("BRANCH")
+	for _, e := range events {
+		nameWidth = max(nameWidth, len(getWorktreeDisplayName(e.Worktree)))
+		branchWidth = max(branchWidth, len(e.Branch))
+	}
+	Log.Outf(logger.Default, "%-18s%-6s%-*s%-*s%s\n", "TIME", "OP", nameWidth+2, "WORKTREE", branchWidth+2, "BRANCH", "DETAIL")
+	for _, e := range events {
+		color := logger.Green
+		if e.Op == history.OpRemove {
+			color = logger.Red
+		}
+		Log.Outf(logger.Default, "%-18s", e.Time.Local().Format("2006-01-02 15:04"))
+		Log.Outf(color, "%-6s", e.Op)
+		Log.Outf(logger.Default, "%-*s%-*s%s\n", nameWidth+2, getWorktreeDisplayName(e.Worktree), branchWidth+2, e.Branch, e.Detail)
+	}
+	return nil
+}
+
+// filterHistory returns the events matching repo, worktree, and since, most
+// recent first. Empty filters match everything. worktree matches the
+// worktree's name, repo/name, or path.
+func filterHistory(events []history.Event, repo, wt string, since time.Time) []history.Event {
+	var filtered []history.Event
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if repo != "" && !strings.EqualFold(e.Repo, repo) {
+			continue
+		}
+		if wt != "" && wt != filepath.Base(e.Worktree) && wt != getWorktreeDisplayName(e.Worktree) && filepath.Clean(wt) != e.Worktree {
+			continue
+		}
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// parseSince parses a --since value: a date (2006-01-02), a number of days
+// (7d), or a Go duration (12h) before now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a date like 2006-01-02 or a duration like 7d or 12h)", value)
+}
+
+// recordHistory appends e to the operation history, warning on failure.
+func recordHistory(e history.Event) {
+	if err := history.Append(e); err != nil {
+		Log.Warnf("Failed to record history: %v\n", err)
+	}
+}
+
+// historyDetail describes the PR or issue a worktree was created from, e.g.
+// "PR #12: Fix login", or "" for local worktrees.
+func historyDetail(typ worktree.WorktreeType, number int, title string) string {
+	if number == 0 {
+		return ""
+	}
+	kind := "issue"
+	if typ == worktree.PR {
+		kind = "PR"
+	}
+	detail := fmt.Sprintf("%s #%d", kind, number)
+	if title != "" {
+		detail += ": " + title
+	}
+	return detail
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC) }
+	events := []history.Event{
+		{Time: day(1), Op: history.OpAdd, Repo: "app", Worktree: "/wt/app/pr_1"},
+		{Time: day(2), Op: history.OpAdd, Repo: "lib", Worktree: "/wt/lib/spike"},
+		{Time: day(3), Op: history.OpRemove, Repo: "app", Worktree: "/wt/app/pr_1"},
+	}
+
+	got := filterHistory(events, "", "", time.Time{})
+	require.Len(t, got, 3)
+	assert.Equal(t, day(3), got[0].Time, "most recent first")
+
+	assert.Len(t, filterHistory(events, "app", "", time.Time{}), 2)
+	assert.Len(t, filterHistory(events, "", "pr_1", time.Time{}), 2)
+	assert.Len(t, filterHistory(events, "", "lib/spike", time.Time{}), 1)
+	assert.Len(t, filterHistory(events, "", "/wt/lib/spike/", time.Time{}), 1)
+	assert.Len(t, filterHistory(events, "app", "", day(2)), 1)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	got, err := parseSince("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), got)
+
+	got, err = parseSince("90m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), got)
+
+	got, err = parseSince("2026-03-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), got)
+
+	_, err = parseSince("last week", now)
+	assert.ErrorContains(t, err, "invalid --since")
+}
+
+func TestHistoryDetail(t *testing.T) {
+	assert.Equal(t, "PR #12: Fix login", historyDetail(worktree.PR, 12, "Fix login"))
+	assert.Equal(t, "issue #7", historyDetail(worktree.Issue, 7, ""))
+	assert.Equal(t, "", historyDetail(worktree.Local, 0, ""))
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var migrateBaseDryRunFlag bool
+
+// migrateBaseCmd represents the migrate-base command.
+var migrateBaseCmd = &cobra.Command{
+	Use:   "migrate-base <old-dir>",
+	Short: "Move worktrees from a previous worktree directory",
+	Long: heredoc.Doc(`
+		Move every worktree under a previous worktree directory to the current
+		worktree_dir, keeping the <repo>/<name> layout, after worktree_dir was
+		changed in the config.
+
+		Worktrees are moved with git worktree move. When that fails, the
+		directory is renamed and git worktree repair fixes the links to its
+		repository. Worktrees that were already moved by hand (e.g. with mv)
+		are repaired in place. Metadata, port blocks, and VS Code workspaces
+		follow the worktrees.
+	`),
+	Example: heredoc.Doc(`
+		# After changing worktree_dir from ~/github/worktree to ~/wt
+		gh wt migrate-base ~/github/worktree
+
+		# Show what would be moved
+		gh wt migrate-base ~/github/worktree --dry-run
+	`),
+	Args:    cobra.ExactArgs(1),
+	RunE:    runMigrateBase,
+	GroupID: "utilities",
+}
+
+func init() {
+	migrateBaseCmd.Flags().BoolVar(&migrateBaseDryRunFlag, "dry-run", false, "show what would be moved without moving anything")
+	rootCmd.AddCommand(migrateBaseCmd)
+}
+
+// baseMove is a worktree to move from the old worktree directory to the new.
+type baseMove struct {
+	From, To string
+	// Repair is set when the worktree was already moved to To by hand and
+	// only its links need repairing.
+	Repair bool
+}
+
+func runMigrateBase(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	oldBase, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	newBase := filepath.Clean(cfg.WorktreeBase)
+	if oldBase == newBase {
+		return fmt.Errorf("%s is already the worktree directory; change worktree_dir first", getTildePath(oldBase))
+	}
+
+	worktrees, err := git.ListAllWorktrees(oldBase)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		return fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+	moves := planBaseMoves(oldBase, newBase, worktrees, store.List())
+	if len(moves) == 0 {
+		Log.Warnf("No worktrees found under %s\n", getTildePath(oldBase))
+		return nil
+	}
+
+	failed := 0
+	for _, m := range moves {
+		name := getWorktreeDisplayName(m.To)
+		if migrateBaseDryRunFlag {
+			verb := "Would move"
+			if m.Repair {
+				verb = "Would repair"
+			}
+			Log.Outf(logger.Default, "%s %s -> %s\n", verb, getTildePath(m.From), getTildePath(m.To))
+			continue
+		}
+		Log.Worktree = m.To
+		if err := moveWorktree(m); err != nil {
+			Log.Warnf("Failed to move %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		if err := metadata.Move(m.From, m.To); err != nil {
+			Log.Warnf("Failed to update worktree metadata: %v\n", err)
+		}
+		if err := ports.Move(m.From, m.To); err != nil {
+			Log.Warnf("Failed to update worktree ports: %v\n", err)
+		}
+		if m.Repair {
+			Log.Outf(logger.Green, "✓ Repaired %s at %s\n", name, getTildePath(m.To))
+		} else {
+			Log.Outf(logger.Green, "✓ Moved %s to %s\n", name, getTildePath(m.To))
+		}
+	}
+	if migrateBaseDryRunFlag {
+		return nil
+	}
+
+	moveWorkspaces(oldBase, newBase, moves)
+	clearCompletionCache()
+	if failed > 0 {
+		return fmt.Errorf("failed to move %d of %d worktrees; move them by hand and run gh wt migrate-base again to repair them", failed, len(moves))
+	}
+	Log.Infof("Moved %d worktrees to %s\n", len(moves), getTildePath(newBase))
+	return nil
+}
+
+// planBaseMoves returns the moves from oldBase to newBase: one for each
+// worktree still under oldBase, and a repair for each worktree recorded under
+// oldBase that already exists under newBase.
+func planBaseMoves(oldBase, newBase string, worktrees []git.WorktreeInfo, entries []metadata.Entry) []baseMove {
+	var moves []baseMove
+	seen := make(map[string]bool)
+	for _, wt := range worktrees {
+		rel, err := filepath.Rel(oldBase, wt.Path)
+		if err != nil {
+			continue
+		}
+		moves = append(moves, baseMove{From: wt.Path, To: filepath.Join(newBase, rel)})
+		seen[wt.Path] = true
+	}
+	for _, e := range entries {
+		rel, err := filepath.Rel(oldBase, e.Path)
+		if err != nil || seen[e.Path] || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		to := filepath.Join(newBase, rel)
+		if _, err := os.Stat(e.Path); err == nil {
+			continue
+		}
+		if _, err := os.Stat(to); err != nil {
+			continue
+		}
+		moves = append(moves, baseMove{From: e.Path, To: to, Repair: true})
+	}
+	return moves
+}
+
+// moveWorktree moves m.From to m.To with git worktree move, falling back to
+// renaming the directory and repairing its links.
+func moveWorktree(m baseMove) error {
+	if m.Repair {
+		return git.WorktreeRepair(m.To)
+	}
+	if _, err := os.Stat(m.To); err == nil {
+		return fmt.Errorf("%s already exists", getTildePath(m.To))
+	}
+	if err := os.MkdirAll(filepath.Dir(m.To), 0o755); err != nil {
+		return err
+	}
+	repoLock, err := lockRepoDir(filepath.Dir(m.From))
+	if err != nil {
+		return err
+	}
+	defer unlockRepoDir(repoLock)
+
+	worktrees, err := git.GetWorktreeInfoAt(m.From)
+	if err == nil && len(worktrees) > 0 {
+		if err = git.WorktreeMoveAt(worktrees[0].Path, m.From, m.To); err == nil {
+			return nil
+		}
+	}
+	Log.Warnf("git worktree move failed (%v); renaming and repairing instead\n", err)
+	if err := os.Rename(m.From, m.To); err != nil {
+		return err
+	}
+	return git.WorktreeRepair(m.To)
+}
+
+// moveWorkspaces moves the VS Code workspaces of the repos in moves to
+// newBase, updates their folders, and removes repo directories left empty
+// under oldBase.
+func moveWorkspaces(oldBase, newBase string, moves []baseMove) {
+	repos := make(map[string]string)
+	for _, m := range moves {
+		repos[filepath.Base(filepath.Dir(m.To))] = m.To
+	}
+	for repo, worktreePath := range repos {
+		from, to := workspacePath(oldBase, repo), workspacePath(newBase, repo)
+		if _, err := os.Stat(from); err == nil {
+			if _, err := os.Stat(to); errors.Is(err, fs.ErrNotExist) {
+				if err := os.Rename(from, to); err != nil {
+					Log.Warnf("Failed to move workspace %s: %v\n", getTildePath(from), err)
+				}
+			}
+		}
+		syncWorkspace(newBase, worktreePath)
+		// Only removes the directory when it is empty.
+		_ = os.Remove(filepath.Join(oldBase, repo))
+	}
+	_ = os.Remove(oldBase)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBaseMoves(t *testing.T) {
+	oldBase, newBase := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(newBase, "repo", "moved"), 0o755))
+
+	worktrees := []git.WorktreeInfo{{Path: filepath.Join(oldBase, "repo", "pr_1"), Branch: "fix"}}
+	entries := []metadata.Entry{
+		{Path: filepath.Join(oldBase, "repo", "pr_1")},
+		{Path: filepath.Join(oldBase, "repo", "moved")},
+		{Path: filepath.Join(oldBase, "repo", "gone")},
+		{Path: "/elsewhere/repo/adopted"},
+	}
+
+	assert.Equal(t, []baseMove{
+		{From: filepath.Join(oldBase, "repo", "pr_1"), To: filepath.Join(newBase, "repo", "pr_1")},
+		{From: filepath.Join(oldBase, "repo", "moved"), To: filepath.Join(newBase, "repo", "moved"), Repair: true},
+	}, planBaseMoves(oldBase, newBase, worktrees, entries))
+}
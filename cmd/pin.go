@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/spf13/cobra"
+)
+
+var pinRemoveFlag bool
+
+// pinCmd represents the pin command.
+var pinCmd = &cobra.Command{
+	Use:   "pin <worktree|number|url>",
+	Short: "Protect a worktree from bulk operations",
+	Long: heredoc.Doc(`
+		Pin a worktree you want to keep around, such as a long-running
+		experiment.
+
+		Pinned worktrees are marked "(pinned)" by gh wt list and skipped by
+		gh wt run --all unless --include-pinned is given. gh wt rm, and gh wt
+		add replacing an existing worktree, refuse to remove them without
+		--force.
+	`),
+	Example: heredoc.Doc(`
+		# Pin a worktree
+		gh wt pin spike-new-parser
+
+		# Unpin it
+		gh wt pin spike-new-parser --remove
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runPin,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	pinCmd.Flags().BoolVarP(&pinRemoveFlag, "remove", "d", false, "unpin the worktree")
+}
+
+func runPin(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+
+	var entry metadata.Entry
+	if err := metadata.Update(func(store *metadata.Store) (bool, error) {
+		var ok bool
+		if entry, ok = store.Get(wt.Path); !ok {
+			// Worktrees created outside gh-wt have no metadata yet.
+			entry = defaultEntry(wt)
+		}
+		entry.Pinned = !pinRemoveFlag
+		store.Put(entry)
+		return true, nil
+	}); err != nil {
+		return err
+	}
+
+	name := getWorktreeDisplayName(wt.Path)
+	if entry.Pinned {
+		Log.Outf(logger.Green, "✓ Pinned %s\n", name)
+	} else {
+		Log.Outf(logger.Green, "✓ Unpinned %s\n", name)
+	}
+	return nil
+}
+
+// isPinned reports whether the worktree at path is pinned. store may be nil.
+func isPinned(store *metadata.Store, path string) bool {
+	if store == nil {
+		return false
+	}
+	e, _ := store.Get(path)
+	return e.Pinned
+}
+
+// pinMark returns the suffix list shows after the name of a pinned worktree.
+func pinMark(store *metadata.Store, path string) string {
+	if isPinned(store, path) {
+		return " (pinned)"
+	}
+	return ""
+}
+
+// worktreeMarks returns the suffixes list shows after the name of a worktree.
+func worktreeMarks(store *metadata.Store, path string) string {
+	return pinMark(store, path) + stackMark(store, path)
+}
+
+// checkNotPinned refuses to remove the pinned worktree at path without
+// --force.
+func checkNotPinned(path string) error {
+	if forceFlag {
+		return nil
+	}
+	return pinnedError(lifecycle.CheckNotPinned(path), path)
+}
+
+// pinnedError explains lifecycle.ErrPinned for the worktree at path. Other
+// errors are returned unchanged.
+func pinnedError(err error, path string) error {
+	if !errors.Is(err, lifecycle.ErrPinned) {
+		return err
+	}
+	name := getWorktreeDisplayName(path)
+	return fmt.Errorf("worktree %s is pinned; unpin it with 'gh wt pin %s --remove' or use --force", name, name)
+}
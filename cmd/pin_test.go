@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckNotPinned(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	pinned := filepath.Join(t.TempDir(), "repo", "spike")
+	other := filepath.Join(t.TempDir(), "repo", "fix")
+	require.NoError(t, metadata.Record(metadata.Entry{Path: pinned, Type: worktree.Local, Pinned: true}))
+	require.NoError(t, metadata.Record(metadata.Entry{Path: other, Type: worktree.Local}))
+
+	err := checkNotPinned(pinned)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "repo/spike is pinned")
+	assert.NoError(t, checkNotPinned(other))
+
+	forceFlag = true
+	t.Cleanup(func() { forceFlag = false })
+	assert.NoError(t, checkNotPinned(pinned))
+
+	store, err := metadata.Load()
+	require.NoError(t, err)
+	assert.Equal(t, " (pinned)", pinMark(store, pinned))
+	assert.Empty(t, pinMark(store, other))
+	assert.Empty(t, pinMark(nil, pinned))
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+)
+
+// Steps of gh wt add, in the order they run.
+const (
+	stepFetch       = "fetch"
+	stepCleanup     = "cleanup"
+	stepWorktreeAdd = "worktree-add"
+	stepPostCreate  = "post-create"
+	stepAction      = "action"
+)
+
+// addSteps are the steps of gh wt add with the titles shown as they start.
+var addSteps = []struct{ name, title string }{
+	{stepFetch, "Fetch"},
+	{stepCleanup, "Clean up existing worktree and branch"},
+	{stepWorktreeAdd, "Add worktree"},
+	{stepPostCreate, "Run post-create hooks"},
+	{stepAction, "Run action"},
+}
+
+// Step statuses.
+const (
+	stepOK      = "ok"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+// StepReport is the outcome of one step of gh wt add.
+type StepReport struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AddReport is the outcome of gh wt add, printed with --json.
+type AddReport struct {
+	Worktree string `json:"worktree,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Number   int    `json:"number,omitempty"`
+	Success  bool   `json:"success"`
+	// RolledBack is set when the worktree was removed after a post-create
+	// step failed.
+	RolledBack bool         `json:"rolledBack,omitempty"`
+	Steps      []StepReport `json:"steps"`
+}
+
+// progress reports the steps of gh wt add as they run and records them in a
+// report.
+type progress struct {
+	log    *logger.Logger
+	report AddReport
+}
+
+func newProgress(log *logger.Logger) *progress {
+	return &progress{log: log, report: AddReport{Steps: []StepReport{}}}
+}
+
+// run prints the step's title, runs fn, and records its outcome. A failed step
+// is reported with its name, and its error is returned wrapped with it.
+func (p *progress) run(name string, fn func() error) error {
+	n, title := stepIndex(name)
+	p.log.Outf(logger.Blue, "[%d/%d] %s\n", n, len(addSteps), title)
+	start := time.Now()
+	err := fn()
+	step := StepReport{Name: name, Status: stepOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		step.Status = stepFailed
+		step.Error = err.Error()
+		p.log.Outf(logger.Red, "✗ Step '%s' failed after %s\n", name, ms(step.DurationMs))
+		err = fmt.Errorf("%s: %w", name, err)
+	}
+	p.report.Steps = append(p.report.Steps, step)
+	return err
+}
+
+// skip records that a step did not need to run.
+func (p *progress) skip(name string) {
+	p.report.Steps = append(p.report.Steps, StepReport{Name: name, Status: stepSkipped})
+}
+
+// finish records whether gh wt add succeeded: no step failed and err is nil.
+func (p *progress) finish(err error) {
+	p.report.Success = err == nil
+	for _, s := range p.report.Steps {
+		if s.Status == stepFailed {
+			p.report.Success = false
+		}
+	}
+}
+
+// stepIndex returns the 1-based position and title of the named step.
+func stepIndex(name string) (int, string) {
+	for i, s := range addSteps {
+		if s.name == name {
+			return i + 1, s.title
+		}
+	}
+	return 0, name
+}
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&logger.Logger{Stdout: &out})
+
+	p.skip(stepFetch)
+	assert.NoError(t, p.run(stepWorktreeAdd, func() error { return nil }))
+	err := p.run(stepAction, func() error { return errors.New("exit status 1") })
+	assert.EqualError(t, err, "action: exit status 1")
+	p.finish(nil)
+
+	assert.Contains(t, out.String(), "[3/5] Add worktree\n")
+	assert.Contains(t, out.String(), "[5/5] Run action\n")
+	assert.Contains(t, out.String(), "✗ Step 'action' failed")
+
+	assert.False(t, p.report.Success)
+	steps := p.report.Steps
+	assert.Len(t, steps, 3)
+	assert.Equal(t, StepReport{Name: stepFetch, Status: stepSkipped}, steps[0])
+	assert.Equal(t, stepOK, steps[1].Status)
+	assert.Equal(t, stepFailed, steps[2].Status)
+	assert.Equal(t, "exit status 1", steps[2].Error)
+}
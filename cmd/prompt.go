@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/cache"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptFormatFlag   string
+	promptJSONFlag     bool
+	promptStarshipFlag bool
+)
+
+// prStatusTTL is how long the PR state shown by gh wt prompt is cached.
+const prStatusTTL = time.Minute
+
+// promptCmd represents the prompt command.
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a short status of the current worktree for shell prompts",
+	Long: heredoc.Doc(`
+		Print a compact status of the worktree containing the current directory,
+		for embedding in PS1 or a zsh prompt: the PR or issue it was created
+		from (or its branch), * when it has uncommitted changes, and the PR's
+		state and checks.
+
+		Outside a managed worktree nothing is printed. PR states are cached for
+		a minute so the prompt stays fast; only the dirty check runs every time.
+
+		--format takes a Go template with the fields .Name, .Branch, .Type,
+		.Number, .Dirty, .State (open, closed, or merged), and .Checks (success,
+		failure, or pending). --json prints the same fields as a JSON object.
+
+		--starship is for a Starship custom module: it prints the status
+		without a newline, shows the worktree name instead of the branch for
+		local worktrees (Starship's git_branch module already shows it), and
+		exits with status 1 outside a managed worktree.
+	`),
+	Example: heredoc.Doc(`
+		# bash
+		PS1='$(gh wt prompt) \$ '
+
+		# zsh
+		setopt PROMPT_SUBST
+		PROMPT='$(gh wt prompt) %# '
+
+		# Custom format
+		gh wt prompt --format '{{.Name}}{{if .Dirty}}!{{end}}'
+
+		# Starship (~/.config/starship.toml)
+		[custom.gh_wt]
+		command = "gh wt prompt --starship"
+		when = true
+		require_repo = true
+		symbol = "wt "
+		format = "[$symbol$output]($style) "
+	`),
+	Args:        cobra.NoArgs,
+	RunE:        runPrompt,
+	GroupID:     "utilities",
+	Annotations: map[string]string{nonInteractiveAnnotation: "true"},
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormatFlag, "format", "", "Go template for the status")
+	promptCmd.Flags().BoolVar(&promptJSONFlag, "json", false, "print the status as JSON")
+	promptCmd.Flags().BoolVar(&promptStarshipFlag, "starship", false, "print the status for a Starship custom module; exit 1 outside a managed worktree")
+	promptCmd.MarkFlagsMutuallyExclusive("format", "json", "starship")
+	rootCmd.AddCommand(promptCmd)
+}
+
+// promptStatus is the status of a worktree shown by gh wt prompt.
+type promptStatus struct {
+	Name   string                `json:"name"`
+	Branch string                `json:"branch"`
+	Type   worktree.WorktreeType `json:"type"`
+	Number int                   `json:"number,omitempty"`
+	Dirty  bool                  `json:"dirty"`
+	// State is open, closed, or merged for PR worktrees.
+	State string `json:"state,omitempty"`
+	// Checks is success, failure, or pending for PR worktrees with checks.
+	Checks string `json:"checks,omitempty"`
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	var tmpl *template.Template
+	if promptFormatFlag != "" {
+		t, err := template.New("format").Parse(promptFormatFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --format: %w", err)
+		}
+		tmpl = t
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	wt, ok := currentWorktree(cfg.WorktreeBase)
+	if !ok {
+		if promptStarshipFlag {
+			return silentExit(1)
+		}
+		return nil
+	}
+	status := currentPromptStatus(cfg, wt)
+
+	w := cmd.OutOrStdout()
+	switch {
+	case promptJSONFlag:
+		return json.NewEncoder(w).Encode(status)
+	case promptStarshipFlag:
+		if status.Type == worktree.Local {
+			status.Name, status.Branch = filepath.Base(wt.Path), ""
+		}
+		_, err := io.WriteString(w, formatPromptStatus(status))
+		return err
+	}
+	return writePromptStatus(w, status, tmpl)
+}
+
+// currentPromptStatus returns the status of wt, with the state of its PR
+// looked up through the cache.
+func currentPromptStatus(cfg config.Config, wt git.WorktreeInfo) promptStatus {
+	status := promptStatus{
+		Name:   getWorktreeDisplayName(wt.Path),
+		Branch: wt.Branch,
+		Type:   worktree.Local,
+		Dirty:  git.HasUncommittedChanges(wt.Path),
+	}
+	store, _ := metadata.Load()
+	if store == nil {
+		return status
+	}
+	e, ok := store.Get(wt.Path)
+	if !ok {
+		return status
+	}
+	status.Type, status.Number = e.Type, e.Number
+	if e.Type == worktree.PR && e.Owner != "" && e.Provider == "" && !cfg.GitOnly {
+		status.State, status.Checks = prStatus(e.Owner, e.Repo, e.Number)
+	}
+	return status
+}
+
+// prStatus returns the lowercased state and checks of a pull request, cached
+// for prStatusTTL. Lookup failures are cached as unknown so an offline prompt
+// doesn't retry on every command.
+func prStatus(owner, repo string, number int) (state, checks string) {
+	key := fmt.Sprintf("pr-status:%s/%s#%d", owner, repo, number)
+	values := cache.Get(key, prStatusTTL, func() []string {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		s, err := github.GetPullRequestStatus(ctx, owner, repo, number)
+		if err != nil {
+			return []string{"", ""}
+		}
+		return []string{strings.ToLower(s.State), strings.ToLower(s.Checks)}
+	})
+	if len(values) != 2 {
+		return "", ""
+	}
+	checks = values[1]
+	if checks == "error" {
+		checks = "failure"
+	} else if checks == "expected" {
+		checks = "pending"
+	}
+	return values[0], checks
+}
+
+// writePromptStatus writes s using tmpl, or the default format when tmpl is
+// nil, e.g. "PR #12* ✓".
+func writePromptStatus(w io.Writer, s promptStatus, tmpl *template.Template) error {
+	if tmpl != nil {
+		return tmpl.Execute(w, s)
+	}
+	_, err := io.WriteString(w, formatPromptStatus(s)+"\n")
+	return err
+}
+
+// formatPromptStatus formats s compactly: the PR or issue, or the branch for
+// local worktrees, followed by * when dirty, the PR state unless open, and a
+// check symbol.
+func formatPromptStatus(s promptStatus) string {
+	var b strings.Builder
+	switch {
+	case s.Type == worktree.PR && s.Number != 0:
+		b.WriteString("PR #" + strconv.Itoa(s.Number))
+	case s.Type == worktree.Issue && s.Number != 0:
+		b.WriteString("issue #" + strconv.Itoa(s.Number))
+	case s.Branch != "":
+		b.WriteString(s.Branch)
+	default:
+		b.WriteString(s.Name)
+	}
+	if s.Dirty {
+		b.WriteString("*")
+	}
+	if s.State != "" && s.State != "open" {
+		b.WriteString(" " + s.State)
+	}
+	switch s.Checks {
+	case "success":
+		b.WriteString(" ✓")
+	case "failure":
+		b.WriteString(" ✗")
+	case "pending":
+		b.WriteString(" …")
+	}
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPromptStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status promptStatus
+		want   string
+	}{
+		{"open PR with passing checks", promptStatus{Type: worktree.PR, Number: 12, State: "open", Checks: "success"}, "PR #12 ✓"},
+		{"dirty merged PR", promptStatus{Type: worktree.PR, Number: 12, Dirty: true, State: "merged"}, "PR #12* merged"},
+		{"issue", promptStatus{Type: worktree.Issue, Number: 7, Checks: "pending"}, "issue #7 …"},
+		{"local branch", promptStatus{Type: worktree.Local, Name: "repo/spike", Branch: "spike", Dirty: true}, "spike*"},
+		{"detached", promptStatus{Type: worktree.Local, Name: "repo/spike"}, "repo/spike"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatPromptStatus(tt.status))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	ghprompter "github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/ffalor/gh-wt/internal/config"
+)
+
+var promptFlag string
+
+// exitCancelled is the exit code used when the user interrupts a prompt,
+// matching a shell's status for a command killed by Ctrl-C.
+const exitCancelled = 130
+
+// errCancelled is returned when the user interrupts a prompt with Ctrl-C.
+var errCancelled = errors.New("cancelled")
+
+// prompter is the subset of go-gh's prompter used by gh wt.
+type prompter interface {
+	Select(prompt, defaultValue string, options []string) (int, error)
+	Confirm(prompt string, defaultValue bool) (bool, error)
+	Input(prompt, defaultValue string) (string, error)
+}
+
+// newPrompter returns a prompter reading from stdin and writing prompts to
+// out, in the style set by --prompt or the prompt config key. Interrupting a
+// prompt returns errCancelled.
+func newPrompter(out *os.File) prompter {
+	if promptStyle() == config.PromptPlain {
+		return newPlainPrompter(os.Stdin, out)
+	}
+	return cancelPrompter{ghprompter.New(os.Stdin, out, os.Stderr)}
+}
+
+// promptStyle returns the configured prompt style. Plain prompts are used on
+// dumb terminals, which cannot draw the interactive ones.
+func promptStyle() string {
+	if os.Getenv("TERM") == "dumb" {
+		return config.PromptPlain
+	}
+	if cfg, err := config.Get(); err == nil {
+		return cfg.Prompt
+	}
+	return promptFlag
+}
+
+// cancelPrompter maps prompt interrupts to errCancelled.
+type cancelPrompter struct {
+	p prompter
+}
+
+func (c cancelPrompter) Select(prompt, defaultValue string, options []string) (int, error) {
+	idx, err := c.p.Select(prompt, defaultValue, options)
+	return idx, promptError(err)
+}
+
+func (c cancelPrompter) Confirm(prompt string, defaultValue bool) (bool, error) {
+	ok, err := c.p.Confirm(prompt, defaultValue)
+	return ok, promptError(err)
+}
+
+func (c cancelPrompter) Input(prompt, defaultValue string) (string, error) {
+	answer, err := c.p.Input(prompt, defaultValue)
+	return answer, promptError(err)
+}
+
+// promptError returns errCancelled for interrupts and err otherwise.
+func promptError(err error) error {
+	if !errors.Is(err, terminal.InterruptErr) {
+		return err
+	}
+	return cancelled()
+}
+
+// cancelled returns errCancelled. A cancelled prompt isn't a usage error, so
+// cobra is told not to print the error and usage; Execute reports the
+// cancellation instead.
+func cancelled() error {
+	silenceErrorOutput()
+	return errCancelled
+}
+
+// plainPrompter asks questions one line at a time and lists options as a
+// numbered list, so it works with screen readers and without raw mode.
+// End of input returns errCancelled.
+type plainPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPlainPrompter(in io.Reader, out io.Writer) *plainPrompter {
+	return &plainPrompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask prints prompt and returns the trimmed line typed in reply.
+func (p *plainPrompter) ask(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		fmt.Fprintln(p.out)
+		if errors.Is(err, io.EOF) {
+			return "", cancelled()
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (p *plainPrompter) Select(prompt, defaultValue string, options []string) (int, error) {
+	fmt.Fprintln(p.out, prompt)
+	def := -1
+	for i, o := range options {
+		fmt.Fprintf(p.out, "  %d. %s\n", i+1, o)
+		if o == defaultValue && def < 0 {
+			def = i
+		}
+	}
+	question := fmt.Sprintf("Enter a number from 1 to %d: ", len(options))
+	if def >= 0 {
+		question = fmt.Sprintf("Enter a number from 1 to %d (default %d): ", len(options), def+1)
+	}
+	for {
+		answer, err := p.ask(question)
+		if err != nil {
+			return 0, err
+		}
+		if answer == "" && def >= 0 {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(p.out, "%q is not a number from 1 to %d.\n", answer, len(options))
+	}
+}
+
+func (p *plainPrompter) Confirm(prompt string, defaultValue bool) (bool, error) {
+	question := prompt + " (y/N): "
+	if defaultValue {
+		question = prompt + " (Y/n): "
+	}
+	for {
+		answer, err := p.ask(question)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Answer yes or no.")
+	}
+}
+
+func (p *plainPrompter) Input(prompt, defaultValue string) (string, error) {
+	question := prompt + " "
+	if defaultValue != "" {
+		question = fmt.Sprintf("%s (default %s) ", prompt, defaultValue)
+	}
+	answer, err := p.ask(question)
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
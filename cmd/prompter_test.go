@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptError(t *testing.T) {
+	t.Cleanup(func() { rootCmd.SilenceErrors, rootCmd.SilenceUsage = false, false })
+
+	other := errors.New("boom")
+	assert.Equal(t, other, promptError(other))
+	assert.NoError(t, promptError(nil))
+	assert.False(t, rootCmd.SilenceUsage)
+
+	err := promptError(fmt.Errorf("could not prompt: %w", terminal.InterruptErr))
+	assert.ErrorIs(t, err, errCancelled)
+	assert.True(t, rootCmd.SilenceUsage, "an interrupt is not a usage error")
+}
+
+func TestPlainPrompterSelect(t *testing.T) {
+	var out bytes.Buffer
+	p := newPlainPrompter(strings.NewReader("7\n2\n"), &out)
+
+	idx, err := p.Select("Select a worktree:", "", []string{"pr_1", "issue_2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	assert.Contains(t, out.String(), "  1. pr_1\n  2. issue_2\n")
+	assert.Contains(t, out.String(), `"7" is not a number from 1 to 2.`)
+
+	p = newPlainPrompter(strings.NewReader("\n"), &out)
+	idx, err = p.Select("Select a worktree:", "issue_2", []string{"pr_1", "issue_2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx, "empty answer picks the default")
+}
+
+func TestPlainPrompterConfirmAndInput(t *testing.T) {
+	var out bytes.Buffer
+	p := newPlainPrompter(strings.NewReader("maybe\nyes\n\nmy-branch"), &out)
+
+	ok, err := p.Confirm("Remove anyway?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "Remove anyway? (y/N): Answer yes or no.")
+
+	answer, err := p.Input("Branch name:", "proposed")
+	require.NoError(t, err)
+	assert.Equal(t, "proposed", answer)
+
+	answer, err = p.Input("Branch name:", "proposed")
+	require.NoError(t, err)
+	assert.Equal(t, "my-branch", answer, "a last line without newline is read")
+
+	_, err = p.Confirm("Remove anyway?", false)
+	assert.ErrorIs(t, err, errCancelled, "end of input cancels")
+	rootCmd.SilenceErrors, rootCmd.SilenceUsage = false, false
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/gitlab"
+	"github.com/ffalor/gh-wt/pkg/wt"
+)
+
+// provider looks up pull requests and issues on the forge hosting them.
+type provider interface {
+	// name is the provider type, e.g. "github".
+	name() string
+	// lookupPR returns the pull request for a URL or number and the
+	// repository it belongs to.
+	lookupPR(value string) (wt.PullRequest, repository.Repository, error)
+	// lookupIssue returns the issue for a URL or number and the repository
+	// it belongs to.
+	lookupIssue(value string) (wt.Issue, repository.Repository, error)
+}
+
+// providerFor returns the provider for a PR or issue URL or number. URLs are
+// matched by host; numbers by the host of the origin remote.
+func providerFor(value string) provider {
+	var host string
+	if u, err := url.Parse(value); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		host = u.Hostname()
+	} else if remote, err := git.RemoteURL("origin"); err == nil {
+		host, _, _ = gitlab.ParseRemote(remote)
+	}
+
+	var hosts []config.ProviderHost
+	if cfg, err := config.Get(); err == nil {
+		hosts = cfg.Providers
+	}
+	if providerType(hosts, host) == config.ProviderGitLab {
+		return gitlabProvider{host: host}
+	}
+	return githubProvider{}
+}
+
+// providerType returns the provider configured for host. github.com and
+// gitlab.com are known; other hosts default to GitHub.
+func providerType(hosts []config.ProviderHost, host string) string {
+	for _, h := range hosts {
+		if strings.EqualFold(h.Host, host) {
+			return h.Type
+		}
+	}
+	if strings.EqualFold(host, "gitlab.com") {
+		return config.ProviderGitLab
+	}
+	return config.ProviderGitHub
+}
+
+// recordedProvider returns the provider recorded for worktrees from p, which
+// is empty for GitHub.
+func recordedProvider(p provider) string {
+	if p.name() == config.ProviderGitHub {
+		return ""
+	}
+	return p.name()
+}
+
+// githubProvider looks up pull requests and issues with gh.
+type githubProvider struct{}
+
+func (githubProvider) name() string { return config.ProviderGitHub }
+
+func (githubProvider) lookupPR(value string) (wt.PullRequest, repository.Repository, error) {
+	return lookupPR(value)
+}
+
+func (githubProvider) lookupIssue(value string) (wt.Issue, repository.Repository, error) {
+	return lookupIssue(value)
+}
+
+// gitlabProvider looks up merge requests and issues with the GitLab API.
+// Merge requests are fetched from refs/merge-requests/<number>/head on origin.
+type gitlabProvider struct {
+	host string
+}
+
+func (gitlabProvider) name() string { return config.ProviderGitLab }
+
+func (p gitlabProvider) lookupPR(value string) (wt.PullRequest, repository.Repository, error) {
+	ref, err := p.ref(value, gitlab.KindMergeRequest)
+	if err != nil {
+		return wt.PullRequest{}, repository.Repository{}, err
+	}
+	pr := wt.PullRequest{Number: ref.Number, Refs: wt.GitLabRefs}
+	if gitOnly() {
+		return pr, gitlabRepo(ref), nil
+	}
+
+	Log.Infof("Fetching Merge Request info...\n")
+	mr, err := gitlab.NewClient(ref.Host).MergeRequest(context.Background(), ref.Project, ref.Number)
+	if err != nil {
+		return wt.PullRequest{}, repository.Repository{}, fmt.Errorf("failed to fetch merge request info (use --git-only to skip the GitLab API): %w", err)
+	}
+	pr.Title = mr.Title
+	pr.HeadRefName = mr.SourceBranch
+	pr.BaseRefName = mr.TargetBranch
+	pr.IsCrossRepository = mr.IsCrossProject()
+	return pr, gitlabRepo(ref), nil
+}
+
+func (p gitlabProvider) lookupIssue(value string) (wt.Issue, repository.Repository, error) {
+	ref, err := p.ref(value, gitlab.KindIssue)
+	if err != nil {
+		return wt.Issue{}, repository.Repository{}, err
+	}
+	if gitOnly() {
+		return wt.Issue{Number: ref.Number}, gitlabRepo(ref), nil
+	}
+
+	Log.Infof("Fetching Issue info...\n")
+	issue, err := gitlab.NewClient(ref.Host).Issue(context.Background(), ref.Project, ref.Number)
+	if err != nil {
+		return wt.Issue{}, repository.Repository{}, fmt.Errorf("failed to fetch issue info (use --git-only to skip the GitLab API): %w", err)
+	}
+	return wt.Issue{Number: ref.Number, Title: issue.Title}, gitlabRepo(ref), nil
+}
+
+// ref parses a GitLab URL or number of the given kind. The project comes from
+// the origin remote and must match the URL, since merge requests are fetched
+// from origin.
+func (p gitlabProvider) ref(value, kind string) (gitlab.Ref, error) {
+	var project string
+	if remote, err := git.RemoteURL("origin"); err == nil {
+		_, project, _ = gitlab.ParseRemote(remote)
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+		ref, ok := gitlab.ParseURL(u)
+		if !ok || ref.Kind != kind {
+			return gitlab.Ref{}, fmt.Errorf("'%s' is not a GitLab %s URL", value, strings.ReplaceAll(kind, "_", " "))
+		}
+		if project != "" && !strings.EqualFold(ref.Project, project) {
+			return gitlab.Ref{}, fmt.Errorf("%s belongs to %s, but this repository is %s", value, ref.Project, project)
+		}
+		return ref, nil
+	}
+
+	number, err := strconv.Atoi(strings.TrimLeft(value, "!#"))
+	if err != nil || number <= 0 {
+		return gitlab.Ref{}, fmt.Errorf("expected a GitLab %s number or URL, got '%s'", strings.ReplaceAll(kind, "_", " "), value)
+	}
+	if project == "" {
+		return gitlab.Ref{}, fmt.Errorf("failed to determine the GitLab project from the origin remote")
+	}
+	return gitlab.Ref{Host: p.host, Project: project, Kind: kind, Number: number}, nil
+}
+
+// gitlabRepo returns the repository of a GitLab ref. Owner is the project's
+// namespace, which may contain subgroups.
+func gitlabRepo(ref gitlab.Ref) repository.Repository {
+	return repository.Repository{Host: ref.Host, Owner: path.Dir(ref.Project), Name: path.Base(ref.Project)}
+}
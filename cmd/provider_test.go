@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderType(t *testing.T) {
+	hosts := []config.ProviderHost{{Host: "git.example.com", Type: config.ProviderGitLab}}
+
+	assert.Equal(t, config.ProviderGitHub, providerType(hosts, "github.com"))
+	assert.Equal(t, config.ProviderGitLab, providerType(hosts, "gitlab.com"))
+	assert.Equal(t, config.ProviderGitLab, providerType(hosts, "Git.Example.com"))
+	assert.Equal(t, config.ProviderGitHub, providerType(hosts, "ghe.example.com"))
+	assert.Equal(t, config.ProviderGitHub, providerType(nil, ""))
+}
+
+func TestDetermineWorktreeTypeGitLab(t *testing.T) {
+	typ, err := DetermineWorktreeType("https://gitlab.com/group/sub/project/-/merge_requests/12")
+	require.NoError(t, err)
+	assert.Equal(t, worktree.PR, typ)
+
+	typ, err = DetermineWorktreeType("https://gitlab.com/group/project/-/issues/3")
+	require.NoError(t, err)
+	assert.Equal(t, worktree.Issue, typ)
+}
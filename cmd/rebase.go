@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebaseOntoFlag    string
+	rebaseNoShellFlag bool
+)
+
+// rebaseCmd represents the rebase command.
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase <worktree|number|url>",
+	Short: "Rebase a worktree's branch onto its latest base",
+	Long: heredoc.Doc(`
+		Fetch the base branch of a worktree and rebase the worktree's branch
+		onto it, without leaving the directory you are in.
+
+		The base is the branch the worktree's PR merges into, the PR it is
+		stacked on for worktrees created with gh wt add --with-stack, or else
+		the default branch of origin. --onto picks another branch.
+
+		The worktree must have no uncommitted changes. Git never opens an
+		editor during the rebase. When it stops on conflicts, the conflicting
+		files are listed and, in a terminal, a shell is started in the worktree
+		to resolve them and run git rebase --continue, or git rebase --abort.
+	`),
+	Example: heredoc.Doc(`
+		# Rebase the worktree of PR #123 onto its base branch
+		gh wt rebase 123
+
+		# Rebase a local worktree onto develop
+		gh wt rebase my-feature --onto develop
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runRebase,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseCmd)
+	rebaseCmd.Flags().StringVar(&rebaseOntoFlag, "onto", "", "branch of origin to rebase onto instead of the worktree's base")
+	rebaseCmd.Flags().BoolVar(&rebaseNoShellFlag, "no-shell", false, "do not start a shell in the worktree when the rebase stops on conflicts")
+}
+
+func runRebase(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	name := getWorktreeDisplayName(wt.Path)
+	switch {
+	case !worktree.Exists(wt.Path):
+		return fmt.Errorf("worktree '%s' does not exist at %s", args[0], wt.Path)
+	case wt.Detached:
+		return fmt.Errorf("worktree %s has a detached HEAD; check out a branch to rebase", name)
+	case git.RebaseInProgress(wt.Path):
+		return fmt.Errorf("a rebase is already in progress in %s; finish it with 'git rebase --continue' or 'git rebase --abort'", name)
+	case git.HasUncommittedChanges(wt.Path):
+		return fmt.Errorf("worktree %s has uncommitted changes; commit or stash them first", name)
+	}
+	touchWorktree(wt)
+
+	base, err := rebaseBase(wt)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	upstream := "origin/" + base
+	Log.Infof("Fetching %s...\n", upstream)
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", base, base)
+	if err := git.FetchAt(wt.Path, "origin", git.FetchOptions{Prune: cfg.Fetch.Prune}, refspec); err != nil {
+		return fmt.Errorf("failed to fetch %s (pass --onto to rebase onto another branch): %w", upstream, err)
+	}
+	if git.IsAncestor(wt.Path, upstream) {
+		Log.Outf(logger.Green, "✓ %s is already up to date with %s\n", name, upstream)
+		return nil
+	}
+
+	Log.Infof("Rebasing %s onto %s...\n", name, upstream)
+	conflicts, err := git.Rebase(wt.Path, upstream)
+	if err == nil {
+		Log.Outf(logger.Green, "✓ Rebased %s onto %s\n", name, upstream)
+		if _, err := git.CommandOutputAt(wt.Path, "rev-parse", "--abbrev-ref", "@{upstream}"); err == nil {
+			Log.Infof("Update the pushed branch with: git -C %s push --force-with-lease\n", getTildePath(wt.Path))
+		}
+		return nil
+	}
+	if !errors.Is(err, git.ErrRebaseConflict) {
+		return fmt.Errorf("failed to rebase %s onto %s: %w", name, upstream, err)
+	}
+
+	Log.Warnf("Rebasing %s onto %s stopped on conflicts\n", name, upstream)
+	for _, file := range conflicts {
+		Log.Plainf("  %s\n", file)
+	}
+	Log.Infof("Resolve them, git add the files, and run 'git rebase --continue', or 'git rebase --abort' to give up\n")
+	if rebaseNoShellFlag || !term.IsTerminal(os.Stdin) {
+		Log.Infof("Run: cd %s\n", getTildePath(wt.Path))
+		return silentExit(1)
+	}
+
+	if err := startShell(wt); err != nil {
+		return err
+	}
+	if git.RebaseInProgress(wt.Path) {
+		Log.Warnf("The rebase of %s is still in progress\n", name)
+		return silentExit(1)
+	}
+	Log.Outf(logger.Green, "✓ Finished rebasing %s\n", name)
+	return nil
+}
+
+// rebaseBase returns the branch of origin to rebase wt onto: --onto, the
+// current base branch of its PR, which GitHub changes when the PR it is
+// stacked on merges, the base branch recorded when the worktree was created,
+// or the default branch.
+func rebaseBase(wt git.WorktreeInfo) (string, error) {
+	if rebaseOntoFlag != "" {
+		return strings.TrimPrefix(rebaseOntoFlag, "origin/"), nil
+	}
+
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+	var entry metadata.Entry
+	if store != nil {
+		entry, _ = store.Get(wt.Path)
+	}
+	if entry.Type == worktree.PR && entry.Number != 0 && entry.Provider == "" && !gitOnly() {
+		base, err := prBaseBranch(entry)
+		if err == nil && base != "" {
+			return base, nil
+		}
+		if err != nil {
+			Log.Warnf("%v\n", err)
+		}
+	}
+	if entry.BaseBranch != "" {
+		return entry.BaseBranch, nil
+	}
+
+	if base := git.DefaultBranchAt(wt.Path); base != "" {
+		return base, nil
+	}
+	return "", fmt.Errorf("cannot determine the default branch of origin; pass --onto <branch>")
+}
+
+// prBaseBranch returns the branch the PR of entry merges into.
+func prBaseBranch(entry metadata.Entry) (string, error) {
+	args := []string{"pr", "view", strconv.Itoa(entry.Number), "--json", "baseRefName"}
+	if entry.Owner != "" && entry.Repo != "" {
+		args = append(args, "--repo", entry.Owner+"/"+entry.Repo)
+	}
+	stdout, stderr, err := ghExec(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the base branch of PR #%d: %w: %s", entry.Number, err, strings.TrimSpace(stderr.String()))
+	}
+	var pr struct {
+		BaseRefName string `json:"baseRefName"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &pr); err != nil {
+		return "", fmt.Errorf("failed to parse PR info: %w", err)
+	}
+	return pr.BaseRefName, nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebaseBase(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	stacked := filepath.Join(t.TempDir(), "repo", "part-2")
+	require.NoError(t, metadata.Record(metadata.Entry{Path: stacked, Type: worktree.Local, BaseBranch: "part-1"}))
+
+	base, err := rebaseBase(git.WorktreeInfo{Path: stacked})
+	require.NoError(t, err)
+	assert.Equal(t, "part-1", base, "the recorded base branch is used")
+
+	rebaseOntoFlag = "origin/develop"
+	t.Cleanup(func() { rebaseOntoFlag = "" })
+	base, err = rebaseBase(git.WorktreeInfo{Path: stacked})
+	require.NoError(t, err)
+	assert.Equal(t, "develop", base, "--onto wins")
+
+	rebaseOntoFlag = ""
+	_, err = rebaseBase(git.WorktreeInfo{Path: t.TempDir()})
+	assert.ErrorContains(t, err, "--onto", "without a base or default branch, --onto is needed")
+}
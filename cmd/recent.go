@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recentLimitFlag  int
+	recentSelectFlag bool
+)
+
+// recentCmd represents the recent command.
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "List recently used worktrees across all repos",
+	Long: heredoc.Doc(`
+		List the most recently used worktrees across all repos, newest first.
+
+		A worktree is used when it is created or targeted by a command such as
+		gh wt run. With --select, pick one of them and print its path, which
+		makes it easy to jump back to what you were doing.
+	`),
+	Example: heredoc.Doc(`
+		# List the 10 most recently used worktrees
+		gh wt recent
+
+		# List the 3 most recently used worktrees
+		gh wt recent -n 3
+
+		# Pick a recent worktree and cd into it
+		cd "$(gh wt recent --select)"
+	`),
+	Args:    cobra.NoArgs,
+	RunE:    runRecent,
+	GroupID: "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(recentCmd)
+	recentCmd.Flags().IntVarP(&recentLimitFlag, "limit", "n", 10, "maximum number of worktrees to show")
+	recentCmd.Flags().BoolVarP(&recentSelectFlag, "select", "s", false, "select a worktree and print its path")
+}
+
+func runRecent(cmd *cobra.Command, args []string) error {
+	if recentLimitFlag < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+
+	store, err := metadata.Load()
+	if err != nil {
+		return err
+	}
+	entries := recentEntries(store.List(), recentLimitFlag)
+	if len(entries) == 0 {
+		Log.Warnf("No recently used worktrees\n")
+		return nil
+	}
+
+	if recentSelectFlag {
+		if !term.IsTerminal(os.Stdin) {
+			return fmt.Errorf("--select needs an interactive terminal")
+		}
+		options := make([]string, len(entries))
+		for i, e := range entries {
+			options[i] = fmt.Sprintf("%s (%s)", getWorktreeDisplayName(e.Path), since(lastUsed(e)))
+		}
+		// Prompt on stderr so stdout only carries the path, e.g. for cd "$(...)".
+		p := newPrompter(os.Stderr)
+		idx, err := p.Select("Select a worktree:", "", options)
+		if err != nil {
+			return fmt.Errorf("prompt failed: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), entries[idx].Path)
+		return nil
+	}
+
+	maxWidth := len("NAME")
+	for _, e := range entries {
+		maxWidth = max(maxWidth, len(getWorktreeDisplayName(e.Path)))
+	}
+	Log.Outf(logger.Default, "%-*s%s\n", maxWidth+4, "NAME", "LAST USED")
+	for _, e := range entries {
+		Log.Outf(logger.Green, "%-*s", maxWidth+4, getWorktreeDisplayName(e.Path))
+		Log.Outf(logger.Default, "%s\n", since(lastUsed(e)))
+	}
+	return nil
+}
+
+// recentEntries returns up to limit entries of worktrees that still exist,
+// most recently used first. Entries that were never used are left out.
+func recentEntries(entries []metadata.Entry, limit int) []metadata.Entry {
+	var recent []metadata.Entry
+	for _, e := range entries {
+		if !lastUsed(e).IsZero() && worktree.Exists(e.Path) {
+			recent = append(recent, e)
+		}
+	}
+	slices.SortStableFunc(recent, func(a, b metadata.Entry) int {
+		return lastUsed(b).Compare(lastUsed(a))
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+// lastUsed returns when a worktree was last used, falling back to when it
+// was created.
+func lastUsed(e metadata.Entry) time.Time {
+	if e.LastUsedAt.IsZero() {
+		return e.CreatedAt
+	}
+	return e.LastUsedAt
+}
+
+// since describes how long ago t was, e.g. "5m ago" or "3d ago".
+func since(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentEntries(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+	}
+	now := time.Now()
+	entries := []metadata.Entry{
+		{Path: filepath.Join(dir, "a"), LastUsedAt: now.Add(-time.Hour)},
+		{Path: filepath.Join(dir, "missing"), LastUsedAt: now},
+		{Path: filepath.Join(dir, "b"), CreatedAt: now.Add(-time.Minute)},
+		{Path: filepath.Join(dir, "c"), LastUsedAt: now.Add(-2 * time.Hour)},
+		{Path: filepath.Join(dir, "d")},
+	}
+
+	got := recentEntries(entries, 2)
+	require.Len(t, got, 2)
+	assert.Equal(t, entries[2].Path, got[0].Path)
+	assert.Equal(t, entries[0].Path, got[1].Path)
+}
+
+func TestSince(t *testing.T) {
+	assert.Equal(t, "just now", since(time.Now()))
+	assert.Equal(t, "5m ago", since(time.Now().Add(-5*time.Minute)))
+	assert.Equal(t, "3h ago", since(time.Now().Add(-3*time.Hour-time.Minute)))
+	assert.Equal(t, "2d ago", since(time.Now().Add(-49*time.Hour)))
+}
@@ -1,26 +1,52 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
 	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
 	"github.com/ffalor/gh-wt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 // rmCmd represents the rm command.
 var rmCmd = &cobra.Command{
-	Use:   "rm [worktree-name]",
+	Use:   "rm [worktree-name|number|url]",
 	Short: "Remove a worktree and its associated branch",
 	Long: heredoc.Doc(`
-		Remove a worktree and its associated branch. Will prompt if there are
-		uncommitted changes (unless --force is used).
+		Remove a worktree and its associated branch. Unless --force is used,
+		asks for confirmation, listing what will be removed and how many
+		commits on the branch are not on any remote, when there are
+		uncommitted changes, or when the branch has commits that are not on
+		any remote or is not fully merged. Without a terminal such a removal
+		fails instead.
+
+		The branches main and master, and the default branch of the origin
+		remote, are kept unless --force is used.
+
+		The worktree can be given by name, or by the PR/issue URL or number it
+		was created from.
+
+		With --done, the worktree is only removed once its PR is merged: the
+		PR of a PR worktree, or the merged PR whose head is the branch of any
+		other worktree. When the branch tracks the PR's head branch, that
+		remote branch is then also deleted, unless it is protected, and for
+		issue worktrees closing the issue is offered (done without asking with
+		--force).
 	`),
 	Example: heredoc.Doc(`
 		# Remove a worktree by name
@@ -28,27 +54,47 @@ var rmCmd = &cobra.Command{
 
 		# Remove a worktree with force
 		gh wt rm issue_456 --force
+
+		# Remove the worktree created from a PR
+		gh wt rm https://github.com/owner/repo/pull/123
+
+		# Clean up after the PR was merged, including the remote branch
+		gh wt rm pr_123 --done
+
+		# Remove a worktree without running git hooks
+		gh wt rm scratch --no-verify
 	`),
-	Aliases: []string{"remove"},
-	Args:    cobra.ExactArgs(1),
-	RunE:    runRm,
-	GroupID: "worktrees",
+	Aliases:           []string{"remove"},
+	Args:              cobra.ExactArgs(1),
+	RunE:              runRm,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
 }
 
+var (
+	rmDoneFlag     bool
+	rmNoVerifyFlag bool
+)
+
 func init() {
+	rmCmd.Flags().BoolVar(&rmDoneFlag, "done", false, "require the PR to be merged, then also delete the remote branch")
+	rmCmd.Flags().BoolVar(&rmNoVerifyFlag, "no-verify", false, "skip git hooks, such as reference-transaction, while removing the worktree and branch")
 	rootCmd.AddCommand(rmCmd)
 }
 
 func runRm(cmd *cobra.Command, args []string) error {
 	worktreeName := args[0]
+	if rmNoVerifyFlag {
+		git.SetSkipHooks(true)
+	}
 
 	// Require being in a git repository (consistent with create command)
 	if !git.IsGitRepository(".") {
-		return fmt.Errorf("not in a git repository")
+		return git.ErrNotARepo
 	}
 
-	// Find the worktree by name using the shared helper
-	matches, err := worktree.FindByName(worktreeName)
+	// Find the worktree by name, number, or URL using the shared helper
+	matches, err := findWorktreeMatches(worktreeName)
 	if err != nil {
 		return err
 	}
@@ -63,29 +109,38 @@ func runRm(cmd *cobra.Command, args []string) error {
 	if len(matches) == 1 {
 		targetWorktree = matches[0]
 	} else {
-		options := make([]string, len(matches))
-		for i, wt := range matches {
-			options[i] = wt.Path
-		}
-		p := prompter.New(os.Stdin, os.Stdout, os.Stderr)
-		idx, err := p.Select("Multiple worktrees match '"+worktreeName+"'. Select one:", "", options)
+		targetWorktree, err = selectWorktree("Multiple worktrees match '"+worktreeName+"'. Select one:", matches)
 		if err != nil {
-			return fmt.Errorf("prompt failed: %w", err)
+			return err
 		}
-		targetWorktree = matches[idx]
+	}
+	Log.Worktree = targetWorktree.Path
+	if err := checkNotPinned(targetWorktree.Path); err != nil {
+		return err
 	}
 
+	repoLock, err := lockRepoDir(filepath.Dir(targetWorktree.Path))
+	if err != nil {
+		return err
+	}
+	defer unlockRepoDir(repoLock)
+
 	// Handle uncommitted changes prompt.
 	force := forceFlag
-	if !force && git.HasUncommittedChanges(targetWorktree.Path) {
-		p := prompter.New(os.Stdin, os.Stdout, os.Stderr)
-		confirm, err := p.Confirm("Worktree has uncommitted changes. Remove anyway?", false)
-		if err != nil {
-			return fmt.Errorf("prompt failed: %w", err)
+	branch := worktreeBranch(targetWorktree)
+	deleteBranch := lifecycle.DeletableBranch(context.Background(), git.Default(), branch, forceFlag)
+
+	var done *doneCheck
+	if rmDoneFlag {
+		if done, err = checkDone(targetWorktree, branch); err != nil {
+			return err
 		}
-		if !confirm {
-			Log.Warnf("Cancelled - no changes made\n")
-			return nil
+		Log.Infof("PR #%d is merged\n", done.PR)
+	}
+	if !force {
+		confirmed, err := confirmRemove(targetWorktree, deleteBranch)
+		if err != nil || !confirmed {
+			return err
 		}
 		force = true // User confirmed.
 	}
@@ -98,26 +153,58 @@ func runRm(cmd *cobra.Command, args []string) error {
 	Log.Infof("Removing worktree %s...\n", worktreeDisplayName)
 
 	// 1. Remove the worktree directory and git metadata.
-	if err := worktree.Remove(targetWorktree.Path, force); err != nil {
+	if err := worktree.Remove(context.Background(), git.Default(), targetWorktree.Path, force); err != nil {
+		if errors.Is(err, git.ErrWorktreeLocked) {
+			return fmt.Errorf("failed to remove worktree: %w; run 'git worktree unlock %s' first", err, targetWorktree.Path)
+		}
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
+	event := history.Event{
+		Op:       history.OpRemove,
+		Repo:     filepath.Base(filepath.Dir(targetWorktree.Path)),
+		Worktree: targetWorktree.Path,
+		Branch:   branch,
+	}
+	if store, err := metadata.Load(); err == nil {
+		if e, ok := store.Get(targetWorktree.Path); ok {
+			event.Detail = historyDetail(e.Type, e.Number, e.Title)
+			if e.Repo != "" {
+				event.Repo = e.Repo
+			}
+		}
+	}
+	recordHistory(event)
+
+	if err := lifecycle.Forget(targetWorktree.Path); err != nil {
+		Log.Warnf("%v\n", err)
+	}
+	clearCompletionCache()
+	if cfg, err := config.Get(); err == nil {
+		syncWorkspace(cfg.WorktreeBase, targetWorktree.Path)
+	}
+
 	Log.Outf(logger.Default, "Worktree: %s\n", worktreePathDisplay)
 
-	if targetWorktree.Branch != "" {
-		Log.Outf(logger.Default, "Branch: %s\n", targetWorktree.Branch)
+	if branch != "" {
+		Log.Outf(logger.Default, "Branch: %s\n", branch)
 	} else {
-		Log.Outf(logger.Default, "Branch: <none>\n")
+		Log.Outf(logger.Default, "Branch: <none> (detached HEAD)\n")
 	}
 
 	// 2. Delete the associated branch if we found one.
-	if targetWorktree.Branch != "" {
-		if err := git.BranchDelete(targetWorktree.Branch, true); err != nil {
+	if branch != "" && !deleteBranch {
+		Log.Infof("Kept branch '%s': it is a default branch; delete it with git branch -D or use --force\n", branch)
+	} else if branch != "" {
+		if err := git.BranchDelete(branch, true); err != nil {
 			// This is not a fatal error, as the primary goal (removing the worktree) succeeded.
 			// The branch might be the main branch or have other worktrees, so git will prevent its deletion.
-			Log.Warnf("Failed to delete branch '%s': %v. You may need to remove it manually.\n", targetWorktree.Branch, err)
+			Log.Warnf("Failed to delete branch '%s': %v. You may need to remove it manually.\n", branch, err)
 		}
 	}
+	if done != nil {
+		finishDone(done, deleteBranch)
+	}
 
 	// Print the details and success message
 
@@ -126,6 +213,161 @@ func runRm(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// doneCheck is what gh wt rm --done found out before removing a worktree.
+type doneCheck struct {
+	Entry metadata.Entry
+	// PR is the merged pull request of the worktree.
+	PR int
+	// Remote and RemoteBranch are the remote branch to delete: the one the
+	// worktree's branch tracks, when it is the head branch of PR. They are
+	// empty when it tracks none, e.g. refs/pull/N/head, or another branch.
+	Remote, RemoteBranch string
+	// Kept says why the remote branch the worktree's branch tracks is kept.
+	Kept string
+}
+
+// prHead is the head branch of a pull request.
+type prHead struct {
+	// Repo is the "owner/name" of the repository of the head branch, empty
+	// when it was deleted.
+	Repo string
+	Ref  string
+}
+
+// checkDone verifies that the work in wt is merged: the PR of a PR worktree,
+// or a PR whose head is branch for other worktrees.
+func checkDone(wt git.WorktreeInfo, branch string) (*doneCheck, error) {
+	store, err := metadata.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+	e, ok := store.Get(wt.Path)
+	if !ok {
+		e = metadata.Entry{Type: worktree.Local}
+	}
+	if e.Provider != "" {
+		return nil, fmt.Errorf("--done is not supported for %s worktrees", e.Provider)
+	}
+	owner, repo, _ := currentRepo()
+	if e.Owner != "" {
+		owner = e.Owner
+	}
+	if e.Repo != "" {
+		repo = e.Repo
+	}
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("--done needs the GitHub repository of the worktree")
+	}
+	e.Owner, e.Repo = owner, repo
+
+	done := &doneCheck{Entry: e}
+	if e.Type == worktree.PR {
+		status, err := github.GetPullRequestStatus(context.Background(), owner, repo, e.Number)
+		if err != nil {
+			return nil, err
+		}
+		if status.State != "MERGED" {
+			return nil, fmt.Errorf("PR #%d is %s, not merged; remove the worktree without --done to discard it", e.Number, strings.ToLower(status.State))
+		}
+		done.PR = e.Number
+	} else {
+		if branch == "" {
+			return nil, fmt.Errorf("worktree %s has no branch to find a merged PR for", getWorktreeDisplayName(wt.Path))
+		}
+		if done.PR, err = mergedPullRequest(owner, repo, branch); err != nil {
+			return nil, err
+		}
+		if done.PR == 0 {
+			return nil, fmt.Errorf("no merged PR found for branch '%s'; remove the worktree without --done to discard it", branch)
+		}
+	}
+	if branch == "" {
+		return done, nil
+	}
+	remote, name := git.BranchUpstream(branch)
+	if name == "" {
+		return done, nil
+	}
+	head, err := pullRequestHead(owner, repo, done.PR)
+	remoteURL, _ := git.RemoteURL(remote)
+	switch {
+	case err != nil:
+		done.Kept = fmt.Sprintf("Keeping remote branch '%s/%s': %v", remote, name, err)
+	case git.IsProtectedBranch(name):
+		done.Kept = fmt.Sprintf("Keeping remote branch '%s/%s': it is protected", remote, name)
+	case !isPullRequestHead(remoteURL, name, head):
+		done.Kept = fmt.Sprintf("Keeping remote branch '%s/%s': it is not the head branch of PR #%d", remote, name, done.PR)
+	default:
+		done.Remote, done.RemoteBranch = remote, name
+	}
+	return done, nil
+}
+
+// pullRequestHead returns the head branch of pull request number of
+// owner/repo.
+func pullRequestHead(owner, repo string, number int) (prHead, error) {
+	stdout, stderr, err := ghExec("pr", "view", strconv.Itoa(number), "--repo", owner+"/"+repo,
+		"--json", "headRefName,headRepository,headRepositoryOwner")
+	if err != nil {
+		return prHead{}, fmt.Errorf("failed to fetch the head branch of PR #%d: %w: %s", number, err, strings.TrimSpace(stderr.String()))
+	}
+	var pr struct {
+		HeadRefName    string `json:"headRefName"`
+		HeadRepository *struct {
+			Name string `json:"name"`
+		} `json:"headRepository"`
+		HeadRepositoryOwner *struct {
+			Login string `json:"login"`
+		} `json:"headRepositoryOwner"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &pr); err != nil {
+		return prHead{}, fmt.Errorf("failed to parse PR info: %w", err)
+	}
+	head := prHead{Ref: pr.HeadRefName}
+	if pr.HeadRepository != nil && pr.HeadRepositoryOwner != nil {
+		head.Repo = pr.HeadRepositoryOwner.Login + "/" + pr.HeadRepository.Name
+	}
+	return head, nil
+}
+
+// isPullRequestHead reports whether branch name of the remote at remoteURL is
+// head, the head branch of a pull request, so that --done only ever deletes
+// the branch the PR was merged from.
+func isPullRequestHead(remoteURL, name string, head prHead) bool {
+	if head.Repo == "" || head.Ref == "" || name != head.Ref {
+		return false
+	}
+	return strings.EqualFold(repoFromRemoteURL(remoteURL), head.Repo)
+}
+
+// finishDone completes gh wt rm --done after the worktree was removed: it
+// deletes the PR's head branch from its remote, unless the local branch was
+// kept, and offers to close the issue of an issue worktree.
+func finishDone(done *doneCheck, deleteBranch bool) {
+	if done.Kept != "" && deleteBranch {
+		Log.Infof("%s\n", done.Kept)
+	}
+	if done.RemoteBranch != "" && deleteBranch {
+		Log.Infof("Deleting remote branch '%s/%s'...\n", done.Remote, done.RemoteBranch)
+		if err := git.DeleteRemoteBranch(done.Remote, done.RemoteBranch); err != nil {
+			Log.Warnf("Failed to delete remote branch '%s/%s': %v\n", done.Remote, done.RemoteBranch, err)
+		}
+	}
+	if done.Entry.Type != worktree.Issue || done.Entry.Number == 0 {
+		return
+	}
+	if !forceFlag {
+		p := newPrompter(os.Stdout)
+		confirm, err := p.Confirm(fmt.Sprintf("Close issue #%d?", done.Entry.Number), true)
+		if err != nil || !confirm {
+			return
+		}
+	}
+	if err := closeIssue(done.Entry, issueCloseBody("", done.PR), ""); err != nil {
+		Log.Warnf("%v\n", err)
+	}
+}
+
 // getWorktreeDisplayName extracts a short name from the worktree path for display.
 func getWorktreeDisplayName(path string) string {
 	// Get the last two components of the path (repo/worktree-name)
@@ -144,3 +386,85 @@ func getTildePath(path string) string {
 	}
 	return path
 }
+
+// confirmRemove shows what removing wt will do and asks for confirmation when
+// it loses work: uncommitted changes, or, when deleteBranch is set, commits of
+// the branch not on any remote, or a branch that is not merged and so is
+// force-deleted. Without a terminal such a removal is refused. It reports
+// whether wt can be removed, forcing it when it has uncommitted changes.
+func confirmRemove(wt git.WorktreeInfo, deleteBranch bool) (bool, error) {
+	branch := worktreeBranch(wt)
+	unpushed := -1
+	if branch != "" {
+		if n, err := git.UnpushedCommits(branch); err == nil {
+			unpushed = n
+		}
+	}
+
+	var reason string
+	switch {
+	case git.HasUncommittedChanges(wt.Path):
+		reason = "worktree has uncommitted changes"
+	case deleteBranch && unpushed > 0:
+		reason = fmt.Sprintf("branch '%s' has commits that are not on any remote", branch)
+	case deleteBranch && !git.BranchMerged(branch):
+		reason = fmt.Sprintf("branch '%s' is not fully merged and will be force-deleted", branch)
+	default:
+		return true, nil
+	}
+
+	if !term.IsTerminal(os.Stdin) {
+		return false, fmt.Errorf("%s; use --force to remove it without confirmation", reason)
+	}
+	question := strings.ToUpper(reason[:1]) + reason[1:] + ". Remove anyway?"
+	confirm, err := newPrompter(os.Stdout).Confirm(buildRemovePlan(wt, unpushed, deleteBranch)+"\n"+question, false)
+	if err != nil {
+		return false, fmt.Errorf("prompt failed: %w", err)
+	}
+	if !confirm {
+		Log.Warnf("Cancelled - no changes made\n")
+	}
+	return confirm, nil
+}
+
+// buildRemovePlan describes what removing wt will do, for the confirmation
+// prompt. unpushed is the number of commits on its branch not on any remote,
+// or -1 if unknown. deleteBranch is false when the branch is protected and
+// will be kept.
+func buildRemovePlan(wt git.WorktreeInfo, unpushed int, deleteBranch bool) string {
+	var plan strings.Builder
+	fmt.Fprintf(&plan, "Target: remove worktree '%s'\n\nThis will:\n", getWorktreeDisplayName(wt.Path))
+
+	branch := worktreeBranch(wt)
+	if branch == "" {
+		fmt.Fprintf(&plan, "- Remove worktree at %s (detached HEAD)\n", getTildePath(wt.Path))
+		return plan.String()
+	}
+
+	commits := ""
+	switch {
+	case unpushed == 1:
+		commits = ", 1 unpushed commit"
+	case unpushed > 1:
+		commits = fmt.Sprintf(", %d unpushed commits", unpushed)
+	}
+	fmt.Fprintf(&plan, "- Remove worktree at %s (branch '%s'%s)\n", getTildePath(wt.Path), branch, commits)
+	if !deleteBranch {
+		fmt.Fprintf(&plan, "- Keep branch '%s' (default branch)\n", branch)
+		return plan.String()
+	}
+	fmt.Fprintf(&plan, "- Delete branch '%s'\n", branch)
+	if unpushed > 0 {
+		fmt.Fprintf(&plan, "\n⚠️  WARNING: Branch '%s' has commits that are not on any remote and will be lost.\n", branch)
+	}
+	return plan.String()
+}
+
+// worktreeBranch returns the branch checked out in wt, or "" when it is
+// detached. HEAD is never a branch gh wt rm should delete.
+func worktreeBranch(wt git.WorktreeInfo) string {
+	if wt.Detached || wt.Branch == "HEAD" {
+		return ""
+	}
+	return wt.Branch
+}
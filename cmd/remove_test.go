@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRemovePlan(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	wt := git.WorktreeInfo{Path: "/home/me/wt/repo/pr_12", Branch: "fix-login"}
+
+	assert.Equal(t, "Target: remove worktree 'repo/pr_12'\n\nThis will:\n"+
+		"- Remove worktree at ~/wt/repo/pr_12 (branch 'fix-login', 3 unpushed commits)\n"+
+		"- Delete branch 'fix-login'\n"+
+		"\n⚠️  WARNING: Branch 'fix-login' has commits that are not on any remote and will be lost.\n",
+		buildRemovePlan(wt, 3, true))
+
+	assert.Equal(t, "Target: remove worktree 'repo/pr_12'\n\nThis will:\n"+
+		"- Remove worktree at ~/wt/repo/pr_12 (branch 'fix-login')\n"+
+		"- Delete branch 'fix-login'\n",
+		buildRemovePlan(wt, 0, true))
+
+	wt.Branch = "main"
+	assert.Equal(t, "Target: remove worktree 'repo/pr_12'\n\nThis will:\n"+
+		"- Remove worktree at ~/wt/repo/pr_12 (branch 'main', 2 unpushed commits)\n"+
+		"- Keep branch 'main' (default branch)\n",
+		buildRemovePlan(wt, 2, false))
+
+	wt.Branch = ""
+	assert.Contains(t, buildRemovePlan(wt, -1, false), "- Remove worktree at ~/wt/repo/pr_12 (detached HEAD)\n")
+
+	wt.Branch = "HEAD"
+	assert.NotContains(t, buildRemovePlan(wt, -1, false), "Delete branch")
+}
+
+func TestIsPullRequestHead(t *testing.T) {
+	head := prHead{Repo: "acme/app", Ref: "feat"}
+	tests := []struct {
+		name      string
+		remoteURL string
+		branch    string
+		head      prHead
+		want      bool
+	}{
+		{"head branch", "git@github.com:acme/app.git", "feat", head, true},
+		{"https remote", "https://github.com/ACME/app", "feat", head, true},
+		{"tracks another branch", "git@github.com:acme/app.git", "release-1", head, false},
+		{"same name in a fork", "git@github.com:someone/app.git", "feat", head, false},
+		{"deleted head repository", "git@github.com:acme/app.git", "feat", prHead{Ref: "feat"}, false},
+		{"unknown remote", "", "feat", head, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isPullRequestHead(tt.remoteURL, tt.branch, tt.head))
+		})
+	}
+}
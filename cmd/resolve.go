@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// worktreeRef identifies a PR or issue worktree by the identifier used to create it.
+type worktreeRef struct {
+	// Type is empty when the identifier was a bare number.
+	Type   worktree.WorktreeType
+	Owner  string
+	Repo   string
+	Number int
+}
+
+var (
+	refURLPattern    = regexp.MustCompile(`^/([^/]+)/([^/]+)/(pull|issues)/(\d+)(?:/.*)?$`)
+	refNumberPattern = regexp.MustCompile(`^#?(\d+)$`)
+)
+
+// parseWorktreeRef parses a PR/issue URL or number such as "123" or "#123".
+func parseWorktreeRef(identifier string) (worktreeRef, bool) {
+	if m := refNumberPattern.FindStringSubmatch(identifier); m != nil {
+		n, err := strconv.Atoi(m[1])
+		return worktreeRef{Number: n}, err == nil
+	}
+
+	u, err := url.Parse(identifier)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return worktreeRef{}, false
+	}
+	m := refURLPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return worktreeRef{}, false
+	}
+	n, err := strconv.Atoi(m[4])
+	if err != nil {
+		return worktreeRef{}, false
+	}
+	ref := worktreeRef{Type: worktree.Issue, Owner: m[1], Repo: m[2], Number: n}
+	if m[3] == "pull" {
+		ref.Type = worktree.PR
+	}
+	return ref, true
+}
+
+// matches reports whether a metadata entry was created from this reference.
+func (r worktreeRef) matches(e metadata.Entry) bool {
+	if e.Number != r.Number || e.Type == worktree.Local {
+		return false
+	}
+	if r.Type != "" && e.Type != r.Type {
+		return false
+	}
+	if r.Owner != "" && !strings.EqualFold(e.Owner, r.Owner) {
+		return false
+	}
+	return r.Repo == "" || strings.EqualFold(e.Repo, r.Repo)
+}
+
+// defaultNames returns the worktree names gh wt add uses for this reference.
+func (r worktreeRef) defaultNames() []string {
+	switch r.Type {
+	case worktree.PR:
+		return []string{"pr_" + strconv.Itoa(r.Number)}
+	case worktree.Issue:
+		return []string{"issue_" + strconv.Itoa(r.Number)}
+	default:
+		return []string{"pr_" + strconv.Itoa(r.Number), "issue_" + strconv.Itoa(r.Number)}
+	}
+}
+
+// findWorktreeMatches finds worktrees of the current repository by name, or by the
+// PR/issue URL or number they were created from. References are resolved through
+// metadata first, then through the default pr_N/issue_N names, and finally fall
+// back to name matching.
+func findWorktreeMatches(identifier string) ([]git.WorktreeInfo, error) {
+	ref, ok := parseWorktreeRef(identifier)
+	if !ok {
+		return worktree.FindByName(identifier)
+	}
+
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []git.WorktreeInfo
+	if store, err := metadata.Load(); err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	} else {
+		for _, wt := range worktrees {
+			if e, ok := store.Get(wt.Path); ok && ref.matches(e) {
+				matches = append(matches, wt)
+			}
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+
+	names := ref.defaultNames()
+	for _, wt := range worktrees {
+		base := filepath.Base(wt.Path)
+		for _, name := range names {
+			if base == name {
+				matches = append(matches, wt)
+			}
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+
+	return worktree.FindByName(identifier)
+}
+
+// defaultEntry returns the metadata entry for a worktree created outside gh-wt,
+// which is treated as local.
+func defaultEntry(wt git.WorktreeInfo) metadata.Entry {
+	return metadata.Entry{
+		Path:   wt.Path,
+		Name:   filepath.Base(wt.Path),
+		Branch: wt.Branch,
+		Type:   worktree.Local,
+		Repo:   filepath.Base(filepath.Dir(wt.Path)),
+	}
+}
+
+// touchWorktree records that a command targeted wt. Failures only warn since
+// the timestamp is informational.
+func touchWorktree(wt git.WorktreeInfo) {
+	Log.Worktree = wt.Path
+	if err := metadata.Touch(wt.Path, defaultEntry(wt)); err != nil {
+		Log.Warnf("Failed to record worktree usage: %v\n", err)
+	}
+}
+
+// selectWorktree prompts for one of worktrees. Options are ranked by frecency
+// so the most likely choice is on top and selected by default.
+func selectWorktree(message string, worktrees []git.WorktreeInfo) (git.WorktreeInfo, error) {
+	var store *metadata.Store
+	if s, err := metadata.Load(); err == nil {
+		store = s
+	}
+	ranked := rankByFrecency(worktrees, store, time.Now())
+
+	options := make([]string, len(ranked))
+	for i, wt := range ranked {
+		options[i] = wt.Path
+	}
+	p := newPrompter(promptOut())
+	idx, err := p.Select(message, options[0], options)
+	if err != nil {
+		return git.WorktreeInfo{}, fmt.Errorf("prompt failed: %w", err)
+	}
+	return ranked[idx], nil
+}
+
+// rankByFrecency returns worktrees sorted by descending frecency. Ties keep
+// their original order.
+func rankByFrecency(worktrees []git.WorktreeInfo, store *metadata.Store, now time.Time) []git.WorktreeInfo {
+	score := func(wt git.WorktreeInfo) float64 {
+		if store == nil {
+			return 0
+		}
+		e, _ := store.Get(wt.Path)
+		return e.Frecency(now)
+	}
+	ranked := slices.Clone(worktrees)
+	slices.SortStableFunc(ranked, func(a, b git.WorktreeInfo) int {
+		return cmp.Compare(score(b), score(a))
+	})
+	return ranked
+}
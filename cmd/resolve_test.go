@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorktreeRef(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		want       worktreeRef
+		wantOK     bool
+	}{
+		{
+			name:       "number",
+			identifier: "123",
+			want:       worktreeRef{Number: 123},
+			wantOK:     true,
+		},
+		{
+			name:       "hash number",
+			identifier: "#42",
+			want:       worktreeRef{Number: 42},
+			wantOK:     true,
+		},
+		{
+			name:       "PR URL",
+			identifier: "https://github.com/o/r/pull/123",
+			want:       worktreeRef{Type: worktree.PR, Owner: "o", Repo: "r", Number: 123},
+			wantOK:     true,
+		},
+		{
+			name:       "issue URL with suffix",
+			identifier: "https://github.com/o/r/issues/7/comments",
+			want:       worktreeRef{Type: worktree.Issue, Owner: "o", Repo: "r", Number: 7},
+			wantOK:     true,
+		},
+		{
+			name:       "worktree name",
+			identifier: "pr_123",
+			wantOK:     false,
+		},
+		{
+			name:       "other URL",
+			identifier: "https://github.com/o/r",
+			wantOK:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseWorktreeRef(tt.identifier)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorktreeRefMatches(t *testing.T) {
+	entry := metadata.Entry{Type: worktree.PR, Owner: "o", Repo: "r", Number: 123}
+
+	assert.True(t, worktreeRef{Number: 123}.matches(entry))
+	assert.True(t, worktreeRef{Type: worktree.PR, Owner: "O", Repo: "r", Number: 123}.matches(entry))
+	assert.False(t, worktreeRef{Type: worktree.Issue, Number: 123}.matches(entry))
+	assert.False(t, worktreeRef{Type: worktree.PR, Owner: "other", Repo: "r", Number: 123}.matches(entry))
+	assert.False(t, worktreeRef{Number: 124}.matches(entry))
+	assert.False(t, worktreeRef{Number: 0}.matches(metadata.Entry{Type: worktree.Local}))
+}
+
+func TestRankByFrecency(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	store, err := metadata.Load()
+	require.NoError(t, err)
+
+	now := time.Now()
+	store.Put(metadata.Entry{Path: "/wt/repo/old", LastUsedAt: now.AddDate(0, 0, -30), UseCount: 4})
+	store.Put(metadata.Entry{Path: "/wt/repo/hot", LastUsedAt: now.Add(-time.Minute), UseCount: 2})
+
+	worktrees := []git.WorktreeInfo{{Path: "/wt/repo/new"}, {Path: "/wt/repo/old"}, {Path: "/wt/repo/hot"}}
+	ranked := rankByFrecency(worktrees, store, now)
+
+	paths := make([]string, len(ranked))
+	for i, wt := range ranked {
+		paths[i] = wt.Path
+	}
+	assert.Equal(t, []string{"/wt/repo/hot", "/wt/repo/old", "/wt/repo/new"}, paths)
+	assert.Equal(t, "/wt/repo/new", worktrees[0].Path, "input is not reordered")
+}
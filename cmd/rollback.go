@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/history"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
+	"github.com/ffalor/gh-wt/internal/logger"
+)
+
+// creationJournal records what gh wt add created, so that a creation whose
+// post-create steps fail can be rolled back.
+type creationJournal struct {
+	// BaseDir is the worktree_dir the worktree was created in.
+	BaseDir string
+	// Repo is the repository the worktree belongs to.
+	Repo string
+	// Path is the worktree added by git worktree add.
+	Path string
+	// Branch is the branch created along with the worktree.
+	Branch string
+	// Detail describes the PR or issue of the worktree, for the history.
+	Detail string
+}
+
+// rollback removes the worktree and branch recorded in j, along with the
+// worktree's metadata and port block.
+func (j *creationJournal) rollback() error {
+	Log.Infof("Rolling back worktree %s...\n", getTildePath(j.Path))
+	if err := git.WorktreeRemove(j.Path, true); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	if j.Branch != "" {
+		if err := git.BranchDelete(j.Branch, true); err != nil {
+			return fmt.Errorf("failed to delete branch '%s': %w", j.Branch, err)
+		}
+	}
+
+	recordHistory(history.Event{
+		Op:       history.OpRemove,
+		Repo:     j.Repo,
+		Worktree: j.Path,
+		Branch:   j.Branch,
+		Detail:   j.Detail,
+	})
+	if err := lifecycle.Forget(j.Path); err != nil {
+		Log.Warnf("%v\n", err)
+	}
+	clearCompletionCache()
+	syncWorkspace(j.BaseDir, j.Path)
+	Log.Outf(logger.Green, "✓ Rolled back worktree and branch '%s'\n", j.Branch)
+	return nil
+}
+
+// offerRollback asks whether to roll back the worktree recorded in j after a
+// post-create step failed, and rolls it back when confirmed. Without a
+// terminal, or with --force, the worktree is kept. It reports whether the
+// worktree was rolled back.
+func offerRollback(j *creationJournal) (bool, error) {
+	if forceFlag || !term.IsTerminal(os.Stdin) {
+		Log.Infof("Keeping worktree %s; remove it with gh wt rm\n", getTildePath(j.Path))
+		return false, nil
+	}
+	p := newPrompter(promptOut())
+	message := fmt.Sprintf("Setup of worktree '%s' failed. Roll back (remove the worktree and branch '%s')?", getWorktreeDisplayName(j.Path), j.Branch)
+	confirm, err := p.Confirm(message, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if !confirm {
+		Log.Infof("Keeping worktree %s\n", getTildePath(j.Path))
+		return false, nil
+	}
+	if err := j.rollback(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
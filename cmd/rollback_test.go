@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreationJournalRollback(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		require.NoError(t, exec.Command("git", args...).Run())
+	}
+	t.Chdir(repo)
+	Log = logger.NewLogger(false, false, false)
+	t.Cleanup(func() { Log = nil })
+
+	base := t.TempDir()
+	path := filepath.Join(base, "repo", "fix")
+	require.NoError(t, worktree.Create(context.Background(), git.Default(), path, "fix", "HEAD"))
+	require.NoError(t, metadata.Record(metadata.Entry{Path: path, Branch: "fix", Type: worktree.Local}))
+
+	j := &creationJournal{BaseDir: base, Repo: "repo", Path: path, Branch: "fix"}
+	require.NoError(t, j.rollback())
+
+	assert.False(t, worktree.Exists(path))
+	assert.False(t, git.BranchExists("fix"))
+	store, err := metadata.Load()
+	require.NoError(t, err)
+	_, ok := store.Get(path)
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/github"
 	"github.com/ffalor/gh-wt/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -17,8 +20,12 @@ var (
 	// Used for flags.
 	forceFlag bool
 	verbose   bool
+	debugFlag bool
 	noColor   bool
+	logFormat string
 	cliArgs   string
+	// cliArgv holds the arguments after -- as given, before joining.
+	cliArgv []string
 )
 
 // Version is the current version of the CLI.
@@ -44,7 +51,7 @@ func buildVersion(version, commit, date, builtBy string) string {
 	if builtBy != "" {
 		result = fmt.Sprintf("%s\nbuilt by: %s", result, builtBy)
 	}
-	result = fmt.Sprintf("%s\ngoos: %s\ngoarch: %s", result, runtime.GOOS, runtime.GOARCH)
+	result = fmt.Sprintf("%s\ngo: %s\ngoos: %s\ngoarch: %s", result, runtime.Version(), runtime.GOOS, runtime.GOARCH)
 	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Sum != "" {
 		result = fmt.Sprintf("%s\nmodule version: %s, checksum: %s", result, info.Main.Version, info.Main.Sum)
 	}
@@ -80,11 +87,55 @@ var rootCmd = &cobra.Command{
 		gh wt rm pr_123
 	`),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		_, err := config.Load()
+		// Debug output is a superset of verbose output.
+		Log = logger.NewLogger(verbose || debugFlag, debugFlag, !noColor)
+		switch logFormat {
+		case logger.FormatText, logger.FormatJSON:
+			Log.Format = logFormat
+		default:
+			return fmt.Errorf("invalid --log-format %q (expected text or json)", logFormat)
+		}
+		Log.Command = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().CommandPath()+" ")
+		if Log.Format == logger.FormatJSON {
+			// Keep stdout to events; git's own output goes to stderr.
+			git.SetOutput(os.Stderr)
+		}
+		git.SetLogger(Log)
+		github.SetLogger(Log)
+
+		if err := offerLegacyMigration(cmd); err != nil {
+			return err
+		}
+		if _, err := config.Load(); err != nil {
+			return err
+		}
+		// The config commands stay usable to inspect and fix a broken config.
+		configCommand := cmd == configCmd || cmd.Parent() == configCmd
+		if !configCommand {
+			if err := config.CheckTemplates(); err != nil {
+				return fmt.Errorf("%w\nRun 'gh wt config validate' to check the config", err)
+			}
+		}
+		if err := loadRepoConfig(cmd); err != nil {
+			if !configCommand {
+				return err
+			}
+			Log.Warnf("%v\n", err)
+		}
+		if err := config.BindFlag("prompt", cmd.Flags().Lookup("prompt")); err != nil {
+			return err
+		}
+		cfg, err := config.Get()
 		if err != nil {
 			return err
 		}
-		Log = logger.NewLogger(verbose, !noColor)
+		if cfg.Prompt != config.PromptDefault && cfg.Prompt != config.PromptPlain {
+			return fmt.Errorf("invalid prompt style %q (expected default or plain)", cfg.Prompt)
+		}
+		if cfg.UnicodeNames != config.NamesPreserve && cfg.UnicodeNames != config.NamesTransliterate {
+			return fmt.Errorf("invalid unicode_names %q (expected preserve or transliterate)", cfg.UnicodeNames)
+		}
+		git.SetFetchRetries(cfg.Fetch.Retries)
 		return nil
 	},
 }
@@ -101,11 +152,20 @@ func Execute() {
 	}
 
 	if dashDashIndex != -1 {
-		cliArgs = strings.Join(os.Args[dashDashIndex+1:], " ")
+		cliArgv = os.Args[dashDashIndex+1:]
+		cliArgs = strings.Join(cliArgv, " ")
 		os.Args = os.Args[:dashDashIndex]
 	}
 
 	err := rootCmd.Execute()
+	if errors.Is(err, errCancelled) {
+		fmt.Fprintln(os.Stderr, "Cancelled")
+		os.Exit(exitCancelled)
+	}
+	var exit *exitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.code)
+	}
 	if err != nil {
 		if Log != nil {
 			Log.Errorf("Error: %v\n", err)
@@ -116,6 +176,28 @@ func Execute() {
 	}
 }
 
+// exitError ends gh wt with code once the command has printed its output.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// silentExit returns an error making Execute exit with code without printing
+// an error or usage, e.g. for commands whose exit status is their answer.
+func silentExit(code int) error {
+	silenceErrorOutput()
+	return &exitError{code: code}
+}
+
+// silenceErrorOutput stops cobra from printing the error returned by the
+// command and the usage that follows it.
+func silenceErrorOutput() {
+	rootCmd.SilenceErrors, rootCmd.SilenceUsage = true, true
+}
+
 func init() {
 	// Define command groups
 	rootCmd.AddGroup(&cobra.Group{ID: "worktrees", Title: "Worktrees"})
@@ -124,11 +206,14 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&forceFlag, "force", "f", false, "force operation without prompts")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "debug output, including subprocess commands and timings (written to stderr)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable color output")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "log output format: text or json (one event per line)")
+	rootCmd.PersistentFlags().StringVar(&promptFlag, "prompt", "", "prompt style: default, or plain for numbered line-based prompts (default from config prompt)")
 
 	// Version flag
 	rootCmd.Version = buildVersion(Version, Commit, Date, BuiltBy)
-	rootCmd.SetVersionTemplate(`gh-wt {{printf "version %s\n" .Version}}`)
+	rootCmd.SetVersionTemplate(`{{versionInfo}}`)
 
 	// Add completion command
 	completionCmd := NewCompletionCommand()
@@ -145,3 +230,20 @@ func init() {
 
 // export for cli doc generation.
 func Root() *cobra.Command { return rootCmd }
+
+// nonInteractiveAnnotation marks commands whose output the shell reads, such
+// as prompt and init, so nothing asks questions before they run.
+const nonInteractiveAnnotation = "gh-wt/non-interactive"
+
+// interactiveCommand reports whether cmd may ask questions before it runs:
+// not a hidden command, such as cobra's __complete, nor one marked with
+// nonInteractiveAnnotation.
+func interactiveCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Hidden {
+			return false
+		}
+	}
+	_, ok := cmd.Annotations[nonInteractiveAnnotation]
+	return !ok
+}
@@ -2,23 +2,26 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/prompter"
 	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/ffalor/gh-wt/internal/action"
-	"github.com/ffalor/gh-wt/internal/execext"
+	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/ffalor/gh-wt/internal/git"
 	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
 	"github.com/ffalor/gh-wt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 // runCmd represents the run command.
 var runCmd = &cobra.Command{
-	Use:   "run <worktree> [action] [-- command]",
+	Use:   "run <worktree|number|url> [action] [-- command]",
 	Short: "Run an action or command in an existing worktree",
 	Long: heredoc.Doc(`
 		Run an action or command in an existing worktree.
@@ -26,6 +29,17 @@ var runCmd = &cobra.Command{
 		Use this command to:
 		- Run configured actions on worktrees that were created without an action
 		- Run commands directly in a worktree
+
+		The worktree can be given by name, or by the PR/issue URL or number it
+		was created from. With --all, the only argument is the action, which runs
+		in every managed worktree of the current repository; without an action,
+		the command after -- runs in every worktree instead.
+
+		With --all, up to --parallel runs execute at once (default from the
+		max_parallel_actions config). Their output is streamed with a colored
+		worktree prefix on each line, or with --group printed per worktree once
+		it finishes. A summary with each worktree's exit code follows. Pinned
+		worktrees (see gh wt pin) are skipped unless --include-pinned is given.
 	`),
 	Example: heredoc.Doc(`
 		# Run named action on worktree
@@ -34,28 +48,80 @@ var runCmd = &cobra.Command{
 		# Run command directly in worktree
 		gh wt run pr_123 -- ls
 
+		# Run named action on the worktree created from PR #123
+		gh wt run 123 test
+
+		# Run the test action in every PR worktree (exits non-zero if any fail)
+		gh wt run --all --type pr test
+
+		# Run a command in every worktree, four at a time, grouping the output
+		gh wt run --all --parallel 4 --group -- git status --short
+
 		# Show help
 		gh wt run pr_123
 	`),
-	Args:    cobra.RangeArgs(1, 2),
-	RunE:    runRun,
-	GroupID: "worktrees",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if runAllFlag {
+			if len(cliArgv) > 0 {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
+	RunE:              runRun,
+	ValidArgsFunction: completeRunArgs,
+	GroupID:           "worktrees",
 }
 
+var (
+	runAllFlag           bool
+	runTypeFlag          string
+	runParallelFlag      int
+	runGroupFlag         bool
+	runJSONFlag          bool
+	runIncludePinnedFlag bool
+)
+
 func init() {
 	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().BoolVar(&runAllFlag, "all", false, "run the action in every managed worktree of the current repository")
+	runCmd.Flags().StringVarP(&runTypeFlag, "type", "t", "", "with --all, only run in worktrees of this type (pr, issue, local)")
+	runCmd.Flags().IntVarP(&runParallelFlag, "parallel", "p", 0, "with --all, how many worktrees to run in at once (default from config max_parallel_actions)")
+	runCmd.Flags().BoolVar(&runGroupFlag, "group", false, "with --all, print each worktree's output in one block when it finishes")
+	runCmd.Flags().BoolVar(&runIncludePinnedFlag, "include-pinned", false, "with --all, also run in pinned worktrees")
+	runCmd.Flags().BoolVar(&runJSONFlag, "json", false, "print the timing report of each run as JSON to stdout")
 }
 
 // runRun is the main function for the run command.
 func runRun(cmd *cobra.Command, args []string) error {
-	worktreeName := args[0]
-	var actionName string
+	if (runTypeFlag != "" || runParallelFlag != 0 || runGroupFlag) && !runAllFlag {
+		return fmt.Errorf("--type, --parallel, and --group require --all")
+	}
+	switch worktree.WorktreeType(runTypeFlag) {
+	case "", worktree.PR, worktree.Issue, worktree.Local:
+	default:
+		return fmt.Errorf("invalid --type %q (expected pr, issue, or local)", runTypeFlag)
+	}
+	if runAllFlag {
+		actionName := ""
+		if len(args) > 0 {
+			actionName = args[0]
+		}
+		return runRunAll(actionName)
+	}
 
 	// Determine if we have an action name or just CLI args
+	var actionName string
 	if len(args) > 1 {
 		actionName = args[1]
 	}
+	return runInWorktree(cmd, args[0], actionName)
+}
 
+// runInWorktree runs an action, or the command after -- when actionName is
+// empty, in the worktree given by worktreeName.
+func runInWorktree(cmd *cobra.Command, worktreeName, actionName string) error {
 	// Find the worktree path
 	wt, err := findWorktree(worktreeName)
 	if err != nil {
@@ -66,72 +132,215 @@ func runRun(cmd *cobra.Command, args []string) error {
 	if !worktree.Exists(wt.Path) {
 		return fmt.Errorf("worktree '%s' does not exist at %s", worktreeName, wt.Path)
 	}
+	touchWorktree(wt)
 
-	info := &worktree.WorktreeInfo{
-		WorktreeName: worktreeName,
-		BranchName:   wt.Branch,
+	owner, repoName, err := currentRepo()
+	if err != nil {
+		return err
 	}
-
-	// Get repo name for worktree info - try GitHub API first, fallback to cwd
-	repo, err := repository.Current()
+	store, err := metadata.Load()
 	if err != nil {
-		// Fallback to using current working directory name
-		repoName, err := git.GetRepoName()
-		if err != nil {
-			return err
-		}
-		info.Repo = repoName
-	} else {
-		info.Repo = repo.Name
-		info.Owner = repo.Owner
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+	info := runInfo(wt, store, owner, repoName)
+
+	if actionName == "" && len(cliArgv) == 0 {
+		// No action or command provided, show help
+		return cmd.Help()
 	}
 
+	log, stdout := runOutput()
+	report := &action.Report{}
+	opts := &action.ExecuteOptions{
+		ActionName:   actionName,
+		WorktreePath: wt.Path,
+		Info:         info,
+		CLIArgs:      cliArgs,
+		Args:         cliArgv,
+		Logger:       log,
+		Stdin:        os.Stdin,
+		Stdout:       stdout,
+		Stderr:       os.Stderr,
+		Env:          os.Environ(),
+		Report:       report,
+	}
+	var runErr error
 	if actionName != "" {
 		// Run the action
-		Log.Outf(logger.Magenta, "Running action '%s' in %s...\n", actionName, wt.Path)
-
-		if err := action.Execute(context.Background(), &action.ExecuteOptions{
-			ActionName:   actionName,
-			WorktreePath: wt.Path,
-			Info:         info,
-			CLIArgs:      cliArgs,
-			Logger:       Log,
-			Stdin:        os.Stdin,
-			Stdout:       os.Stdout,
-			Stderr:       os.Stderr,
-			Env:          os.Environ(),
-		}); err != nil {
-			return fmt.Errorf("action '%s' failed: %w", actionName, err)
+		log.Outf(logger.Magenta, "Running action '%s' in %s...\n", actionName, wt.Path)
+		if runErr = action.Execute(context.Background(), opts); runErr != nil {
+			runErr = fmt.Errorf("action '%s' failed: %w", actionName, runErr)
 		}
-
-		Log.Outf(logger.Green, "Action completed successfully.\n")
-	} else if cliArgs != "" {
+	} else {
 		// Run CLI args directly in the worktree
-		Log.Outf(logger.Magenta, "Running in worktree: %s\n", cliArgs)
-
-		if err := execext.RunCommand(context.Background(), &execext.RunCommandOptions{
-			Command: cliArgs,
-			Dir:     wt.Path,
-			Env:     os.Environ(),
-			Stdin:   os.Stdin,
-			Stdout:  os.Stdout,
-			Stderr:  os.Stderr,
-		}); err != nil {
-			return fmt.Errorf("command '%s' failed: %w", cliArgs, err)
+		opts.Command = directCommand(cliArgv)
+		runErr = action.ExecuteCommand(context.Background(), opts)
+	}
+
+	if runJSONFlag {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return err
 		}
-	} else {
-		// No action or command provided, show help
-		return cmd.Help()
 	}
+	return runErr
+}
 
+// runOutput returns the logger and stdout for action runs. With --json, both
+// go to stderr, leaving stdout to the JSON report.
+func runOutput() (*logger.Logger, io.Writer) {
+	if !runJSONFlag {
+		return Log, os.Stdout
+	}
+	log := *Log
+	log.Stdout = os.Stderr
+	return &log, os.Stderr
+}
+
+// directCommand returns the command line to run for the arguments after --.
+// A single argument is run as a shell script, e.g. -- "make && make test";
+// several are quoted, so each reaches the command unchanged, e.g.
+// -- git commit -m "fix the bug".
+func directCommand(argv []string) string {
+	if len(argv) == 1 {
+		return argv[0]
+	}
+	return action.QuoteArgs(argv)
+}
+
+// runRunAll runs an action, or the command after -- when actionName is
+// empty, in every managed worktree of the current repository and fails if any
+// run failed.
+func runRunAll(actionName string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := git.GetWorktreeInfo()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	owner, repoName, err := currentRepo()
+	if err != nil {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+
+	command := ""
+	if actionName == "" {
+		command = directCommand(cliArgv)
+	}
+
+	log, stdout := runOutput()
+	var jobs []action.Job
+	for _, wt := range filterManaged(worktrees, cfg.WorktreeBase, store) {
+		info := runInfo(wt, store, owner, repoName)
+		if runTypeFlag != "" && string(info.Type) != runTypeFlag {
+			continue
+		}
+		if !runIncludePinnedFlag && isPinned(store, wt.Path) {
+			Log.Infof("Skipping pinned worktree %s\n", getWorktreeDisplayName(wt.Path))
+			continue
+		}
+		jobs = append(jobs, action.Job{
+			Label: info.WorktreeName,
+			Options: action.ExecuteOptions{
+				ActionName:   actionName,
+				WorktreePath: wt.Path,
+				Info:         info,
+				CLIArgs:      cliArgs,
+				Args:         cliArgv,
+				Logger:       log,
+				Stdin:        os.Stdin,
+				Stdout:       stdout,
+				Stderr:       os.Stderr,
+				Env:          os.Environ(),
+				Command:      command,
+				Report:       &action.Report{},
+			},
+		})
+	}
+
+	if len(jobs) == 0 {
+		Log.Warnf("No matching worktrees found under %s\n", cfg.WorktreeBase)
+		return nil
+	}
+
+	what := fmt.Sprintf("action '%s'", actionName)
+	if actionName == "" {
+		what = fmt.Sprintf("command '%s'", command)
+	}
+	parallel := cfg.MaxParallelActions
+	if runParallelFlag > 0 {
+		parallel = runParallelFlag
+	}
+	log.Outf(logger.Magenta, "Running %s in %d worktree(s)...\n", what, len(jobs))
+	results := action.ExecuteAll(context.Background(), jobs, action.BatchOptions{Parallel: parallel, Group: runGroupFlag})
+	action.PrintSummary(log, results)
+
+	if runJSONFlag {
+		reports := make([]*action.Report, len(jobs))
+		for i, job := range jobs {
+			reports[i] = job.Options.Report
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(reports); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed in %d of %d worktrees", what, failed, len(results))
+	}
 	return nil
 }
 
-// findWorktree finds the worktree based on the worktree name.
+// runInfo builds the action template data for a worktree, using metadata when
+// available. Worktrees without metadata are treated as local.
+func runInfo(wt git.WorktreeInfo, store *metadata.Store, owner, repoName string) *worktree.WorktreeInfo {
+	info := &worktree.WorktreeInfo{
+		Type:         worktree.Local,
+		Owner:        owner,
+		Repo:         repoName,
+		WorktreeName: filepath.Base(wt.Path),
+		BranchName:   wt.Branch,
+	}
+	if store != nil {
+		if e, ok := store.Get(wt.Path); ok {
+			info.Type = e.Type
+			info.Number = e.Number
+			info.Provider = e.Provider
+			info.Title = e.Title
+		}
+	}
+	return info
+}
+
+// currentRepo returns the owner and name of the current repository, trying the
+// GitHub remote first and falling back to the working directory name.
+func currentRepo() (owner, name string, err error) {
+	repo, err := repository.Current()
+	if err == nil {
+		return repo.Owner, repo.Name, nil
+	}
+	name, err = git.GetRepoName()
+	return "", name, err
+}
+
+// findWorktree finds the worktree based on the worktree name, number, or URL.
 // It prompts if multiple matches.
 func findWorktree(worktreeName string) (git.WorktreeInfo, error) {
 	var info git.WorktreeInfo
-	matches, err := worktree.FindByName(worktreeName)
+	matches, err := findWorktreeMatches(worktreeName)
 	if err != nil {
 		return info, err
 	}
@@ -142,16 +351,7 @@ func findWorktree(worktreeName string) (git.WorktreeInfo, error) {
 
 	// If multiple matches, prompt user to select one
 	if len(matches) > 1 {
-		options := make([]string, len(matches))
-		for i, wt := range matches {
-			options[i] = wt.Path
-		}
-		p := prompter.New(os.Stdin, os.Stdout, os.Stderr)
-		idx, err := p.Select("Multiple worktrees match '"+worktreeName+"'. Select one:", "", options)
-		if err != nil {
-			return info, fmt.Errorf("prompt failed: %w", err)
-		}
-		return matches[idx], nil
+		return selectWorktree("Multiple worktrees match '"+worktreeName+"'. Select one:", matches)
 	}
 
 	// Return the single match
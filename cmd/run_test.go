@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectCommand(t *testing.T) {
+	assert.Equal(t, "make && make test", directCommand([]string{"make && make test"}))
+	assert.Equal(t, `git commit -m 'fix the bug'`, directCommand([]string{"git", "commit", "-m", "fix the bug"}))
+	assert.Equal(t, `echo 'it'\''s' '$HOME'`, directCommand([]string{"echo", "it's", "$HOME"}))
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// shellCmd represents the shell command.
+var shellCmd = &cobra.Command{
+	Use:   "shell <worktree|number|url>",
+	Short: "Start a shell in a worktree",
+	Long: heredoc.Doc(`
+		Start your $SHELL in a worktree, with variables describing the worktree
+		exported. Exit the shell to return to where you were.
+
+		The shell gets these environment variables:
+		  GH_WT_SHELL    set to 1, so prompts can show you are in a worktree shell
+		  GH_WT_NAME     worktree directory name
+		  GH_WT_PATH     worktree path
+		  GH_WT_BRANCH   branch name
+		  GH_WT_TYPE     pr, issue, or local
+		  GH_WT_OWNER    repository owner
+		  GH_WT_REPO     repository name
+		  GH_WT_NUMBER   PR or issue number, when there is one
+	`),
+	Example: heredoc.Doc(`
+		# Start a shell in a worktree
+		gh wt shell pr_123
+
+		# Start a shell in the worktree created from issue #456
+		gh wt shell 456
+	`),
+	Args:              cobra.ExactArgs(1),
+	RunE:              runShell,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	if !worktree.Exists(wt.Path) {
+		return fmt.Errorf("worktree '%s' does not exist at %s", args[0], wt.Path)
+	}
+	touchWorktree(wt)
+	return startShell(wt)
+}
+
+// startShell runs the user's shell in wt until it exits.
+func startShell(wt git.WorktreeInfo) error {
+	owner, repoName, err := currentRepo()
+	if err != nil {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		Log.Warnf("Failed to read worktree metadata: %v\n", err)
+	}
+	info := runInfo(wt, store, owner, repoName)
+
+	if os.Getenv("GH_WT_SHELL") != "" {
+		Log.Infof("Already in a gh wt shell; exit it to return to the previous one\n")
+	}
+
+	shell := userShell()
+	Log.Outf(logger.Green, "Starting %s in %s (exit to return)\n", shell, getTildePath(wt.Path))
+
+	c := exec.Command(shell)
+	c.Dir = wt.Path
+	c.Env = append(worktreeEnv(wt.Path, info), shellEnv(info, wt.Path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		// The shell's exit status is that of the last command run in it.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to start %s: %w", shell, err)
+	}
+	return nil
+}
+
+// userShell returns the user's shell: $SHELL, %COMSPEC% on Windows, or /bin/sh.
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		if comspec := os.Getenv("COMSPEC"); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	return "/bin/sh"
+}
+
+// shellEnv returns the GH_WT_* variables describing a worktree.
+func shellEnv(info *worktree.WorktreeInfo, path string) []string {
+	env := []string{
+		"GH_WT_SHELL=1",
+		"GH_WT_NAME=" + info.WorktreeName,
+		"GH_WT_PATH=" + path,
+		"GH_WT_BRANCH=" + info.BranchName,
+		"GH_WT_TYPE=" + string(info.Type),
+		"GH_WT_OWNER=" + info.Owner,
+		"GH_WT_REPO=" + info.Repo,
+	}
+	if info.Number != 0 {
+		env = append(env, "GH_WT_NUMBER="+strconv.Itoa(info.Number))
+	}
+	return env
+}
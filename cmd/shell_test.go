@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellEnv(t *testing.T) {
+	info := &worktree.WorktreeInfo{
+		Type:         worktree.PR,
+		Owner:        "octo",
+		Repo:         "cli",
+		Number:       12,
+		BranchName:   "fix-login",
+		WorktreeName: "pr_12",
+	}
+
+	assert.Equal(t, []string{
+		"GH_WT_SHELL=1",
+		"GH_WT_NAME=pr_12",
+		"GH_WT_PATH=/wt/cli/pr_12",
+		"GH_WT_BRANCH=fix-login",
+		"GH_WT_TYPE=pr",
+		"GH_WT_OWNER=octo",
+		"GH_WT_REPO=cli",
+		"GH_WT_NUMBER=12",
+	}, shellEnv(info, "/wt/cli/pr_12"))
+
+	info.Number = 0
+	assert.NotContains(t, shellEnv(info, "/wt/cli/pr_12"), "GH_WT_NUMBER=0")
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+// posixInit wraps gh in bash and zsh so that gh wt switch changes the
+// shell's directory. Everything else is passed to gh unchanged.
+const posixInit = `# gh wt shell integration: gh wt switch changes the current directory.
+gh() {
+  if [ "$1" = "wt" ] && [ "$2" = "switch" ]; then
+    shift 2
+    local dir
+    dir="$(env ` + shellIntegrationEnv + `=1 gh wt switch "$@")" || return
+    [ -n "$dir" ] && cd -- "$dir"
+  else
+    command gh "$@"
+  fi
+}
+`
+
+// fishInit is posixInit for fish.
+const fishInit = `# gh wt shell integration: gh wt switch changes the current directory.
+function gh --wraps gh
+    if test "$argv[1]" = wt -a "$argv[2]" = switch
+        set -l dir (env ` + shellIntegrationEnv + `=1 gh wt switch $argv[3..-1]); or return
+        test -n "$dir"; and cd $dir
+    else
+        command gh $argv
+    end
+end
+`
+
+// initCmd represents the init command.
+var initCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish>",
+	Short: "Print the shell integration for gh wt switch",
+	Long: heredoc.Doc(`
+		Print a shell function that lets gh wt switch change the current
+		directory of your shell, the way zoxide and nvm do it.
+
+		The function wraps gh: gh wt switch runs gh wt and cds into the path it
+		prints, and every other gh command is passed through unchanged. Load it
+		from your shell's startup file.
+	`),
+	Example: heredoc.Doc(`
+		# bash, in ~/.bashrc
+		eval "$(gh wt init bash)"
+
+		# zsh, in ~/.zshrc
+		eval "$(gh wt init zsh)"
+
+		# fish, in ~/.config/fish/config.fish
+		gh wt init fish | source
+	`),
+	ValidArgs:   []string{"bash", "zsh", "fish"},
+	Args:        cobra.ExactValidArgs(1),
+	RunE:        runInit,
+	GroupID:     "utilities",
+	Annotations: map[string]string{nonInteractiveAnnotation: "true"},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	script, err := shellInit(args[0])
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(cmd.OutOrStdout(), script)
+	return err
+}
+
+// shellInit returns the shell integration for shell.
+func shellInit(shell string) (string, error) {
+	switch shell {
+	case "bash", "zsh":
+		return posixInit, nil
+	case "fish":
+		return fishInit, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (expected bash, zsh, or fish)", shell)
+}
+
+// initLine returns the line loading the shell integration in the startup
+// file of shell.
+func initLine(shell string) string {
+	if shell == "fish" {
+		return "gh wt init fish | source"
+	}
+	return fmt.Sprintf(`eval "$(gh wt init %s)"`, shell)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellInitBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	target := t.TempDir()
+	bin := t.TempDir()
+	// A fake gh printing the target for wt switch, when run by the function.
+	fake := "#!/bin/sh\n" +
+		"if [ \"$1 $2\" = \"wt switch\" ] && [ -n \"$" + shellIntegrationEnv + "\" ]; then echo " + target + "; else echo \"gh $*\"; fi\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "gh"), []byte(fake), 0o755))
+
+	script, err := shellInit("bash")
+	require.NoError(t, err)
+	c := exec.Command(bash, "-c", script+"gh wt switch pr_1 && pwd && gh pr list")
+	c.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	out, err := c.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	resolved, err := filepath.EvalSymlinks(target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{resolved, "gh pr list"}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+}
+
+func TestShellInit(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := shellInit(shell)
+		require.NoError(t, err)
+		assert.Contains(t, script, shellIntegrationEnv+"=1 gh wt switch")
+	}
+	_, err := shellInit("tcsh")
+	assert.Error(t, err)
+	assert.Equal(t, `eval "$(gh wt init zsh)"`, initLine("zsh"))
+	assert.Equal(t, "gh wt init fish | source", initLine("fish"))
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// maxStack bounds how many PRs --with-stack creates worktrees for.
+const maxStack = 50
+
+// stackPRFields are the fields of the PRs of a stack queried from gh.
+const stackPRFields = "number,title,headRefName,baseRefName,isCrossRepository"
+
+// stackPR is a pull request of a stack of dependent PRs, where each one's
+// base branch is the head branch of the one below it.
+type stackPR struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	// IsCrossRepository is true when the head lives in a fork, so no PR of
+	// the repository can be based on it.
+	IsCrossRepository bool `json:"isCrossRepository"`
+}
+
+// addPR creates the worktree of a PR URL or number, or with --with-stack the
+// worktrees of its whole stack.
+func addPR(p *progress, value string) error {
+	if withStackFlag {
+		return createStack(value)
+	}
+	return createFromPR(p, value, 0)
+}
+
+// createStack creates a worktree for every PR of the stack of the PR given by
+// value, from the bottom up, recording what each is stacked on. PRs that
+// already have a worktree keep it.
+func createStack(value string) error {
+	if gitOnly() {
+		return fmt.Errorf("--with-stack needs the GitHub API to find the PRs of the stack; it cannot be used with --git-only")
+	}
+	if p := providerFor(value); p.name() != config.ProviderGitHub {
+		return fmt.Errorf("--with-stack is only supported for GitHub pull requests")
+	}
+
+	Log.Infof("Finding the stack of PR %s...\n", value)
+	stdout, stderr, err := ghExec("pr", "view", value, "--json", stackPRFields)
+	if err != nil {
+		return fmt.Errorf("failed to fetch PR info: %w\n%s", err, stderr.String())
+	}
+	var start stackPR
+	if err := json.Unmarshal(stdout.Bytes(), &start); err != nil {
+		return fmt.Errorf("failed to parse PR info: %w", err)
+	}
+	stack, err := walkStack(start, listStackPRs)
+	if err != nil {
+		return err
+	}
+	if len(stack) == 1 {
+		Log.Infof("PR #%d is not part of a stack\n", start.Number)
+	} else {
+		Log.Outf(logger.Green, "Creating worktrees for the %d PRs of the stack of PR #%d\n", len(stack), start.Number)
+	}
+
+	owner, repo, err := currentRepo()
+	if err != nil {
+		return err
+	}
+	stackedOn := stackParents(stack)
+	for _, pr := range stack {
+		if path, ok := prWorktree(owner, repo, pr.Number); ok {
+			Log.Infof("PR #%d already has worktree %s\n", pr.Number, getWorktreeDisplayName(path))
+			if err := recordStackBase(path, pr.BaseRefName, stackedOn[pr.Number]); err != nil {
+				Log.Warnf("Failed to record worktree metadata: %v\n", err)
+			}
+			continue
+		}
+		if err := createFromPR(newProgress(Log), strconv.Itoa(pr.Number), stackedOn[pr.Number]); err != nil {
+			return fmt.Errorf("PR #%d: %w", pr.Number, err)
+		}
+	}
+	if len(stack) > 1 {
+		printStack(stack, stackedOn)
+	}
+	return nil
+}
+
+// listStackPRs returns the open PRs whose head (by "--head") or base (by
+// "--base") is branch.
+func listStackPRs(by, branch string) ([]stackPR, error) {
+	stdout, stderr, err := ghExec("pr", "list", by, branch, "--state", "open", "--limit", "20", "--json", stackPRFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the PRs of branch '%s': %w: %s", branch, err, strings.TrimSpace(stderr.String()))
+	}
+	var prs []stackPR
+	if err := json.Unmarshal(stdout.Bytes(), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	return prs, nil
+}
+
+// walkStack returns the stack of start: the PRs it is stacked on, down to the
+// one based on a branch without a PR such as main, then start, then every PR
+// stacked on it, directly or not. Each PR comes after the one it is stacked
+// on. list is listStackPRs.
+func walkStack(start stackPR, list func(by, branch string) ([]stackPR, error)) ([]stackPR, error) {
+	stack := []stackPR{start}
+	seen := map[int]bool{start.Number: true}
+
+	for pr := start; len(stack) < maxStack; {
+		prs, err := list("--head", pr.BaseRefName)
+		if err != nil {
+			return nil, err
+		}
+		i := indexOfStackPR(prs, func(p stackPR) bool { return !p.IsCrossRepository && !seen[p.Number] })
+		if i < 0 {
+			break
+		}
+		pr = prs[i]
+		seen[pr.Number] = true
+		stack = append([]stackPR{pr}, stack...)
+	}
+
+	queue := []stackPR{start}
+	for len(queue) > 0 && len(stack) < maxStack {
+		pr := queue[0]
+		queue = queue[1:]
+		if pr.IsCrossRepository {
+			continue
+		}
+		prs, err := list("--base", pr.HeadRefName)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range prs {
+			if seen[child.Number] || len(stack) == maxStack {
+				continue
+			}
+			seen[child.Number] = true
+			stack = append(stack, child)
+			queue = append(queue, child)
+		}
+	}
+	return stack, nil
+}
+
+func indexOfStackPR(prs []stackPR, match func(stackPR) bool) int {
+	for i, pr := range prs {
+		if match(pr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// stackParents maps each PR of stack to the PR it is stacked on. PRs based on
+// a branch without a PR are not in the map.
+func stackParents(stack []stackPR) map[int]int {
+	byHead := map[string]int{}
+	for _, pr := range stack {
+		if !pr.IsCrossRepository {
+			byHead[pr.HeadRefName] = pr.Number
+		}
+	}
+	parents := map[int]int{}
+	for _, pr := range stack {
+		if n, ok := byHead[pr.BaseRefName]; ok && n != pr.Number {
+			parents[pr.Number] = n
+		}
+	}
+	return parents
+}
+
+// printStack prints stack as a tree growing from its base branch.
+func printStack(stack []stackPR, parents map[int]int) {
+	depth := map[int]int{}
+	Log.Outf(logger.Default, "\nStack:\n  %s\n", stack[0].BaseRefName)
+	for _, pr := range stack {
+		if parent, ok := parents[pr.Number]; ok {
+			depth[pr.Number] = depth[parent] + 1
+		}
+		indent := strings.Repeat("   ", depth[pr.Number])
+		Log.Outf(logger.Default, "  %s└─ #%d %s\n", indent, pr.Number, pr.HeadRefName)
+	}
+}
+
+// stackMark returns the suffix list shows after the name of a worktree of a
+// PR stacked on another.
+func stackMark(store *metadata.Store, path string) string {
+	if store == nil {
+		return ""
+	}
+	if e, _ := store.Get(path); e.StackedOn != 0 {
+		return fmt.Sprintf(" (on #%d)", e.StackedOn)
+	}
+	return ""
+}
+
+// prWorktree returns the path of the existing worktree of PR number of repo.
+func prWorktree(owner, repo string, number int) (string, bool) {
+	store, err := metadata.Load()
+	if err != nil {
+		return "", false
+	}
+	for _, e := range store.List() {
+		if e.Type != worktree.PR || e.Number != number || e.Provider != "" || e.Repo != repo {
+			continue
+		}
+		if owner != "" && e.Owner != "" && !strings.EqualFold(e.Owner, owner) {
+			continue
+		}
+		if isDir(e.Path) {
+			return e.Path, true
+		}
+	}
+	return "", false
+}
+
+// recordStackBase records what the worktree at path is stacked on.
+func recordStackBase(path, base string, stackedOn int) error {
+	return metadata.Update(func(store *metadata.Store) (bool, error) {
+		entry, ok := store.Get(path)
+		if !ok {
+			return false, nil
+		}
+		entry.BaseBranch, entry.StackedOn = base, stackedOn
+		store.Put(entry)
+		return true, nil
+	})
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkStack(t *testing.T) {
+	// main <- a (#1) <- b (#2) <- c (#3)
+	//                         <- d (#4, from a fork)
+	prs := []stackPR{
+		{Number: 1, HeadRefName: "a", BaseRefName: "main"},
+		{Number: 2, HeadRefName: "b", BaseRefName: "a"},
+		{Number: 3, HeadRefName: "c", BaseRefName: "b"},
+		{Number: 4, HeadRefName: "b", BaseRefName: "b", IsCrossRepository: true},
+		{Number: 5, HeadRefName: "other", BaseRefName: "main"},
+	}
+	list := func(by, branch string) ([]stackPR, error) {
+		var matches []stackPR
+		for _, pr := range prs {
+			if (by == "--head" && pr.HeadRefName == branch) || (by == "--base" && pr.BaseRefName == branch) {
+				matches = append(matches, pr)
+			}
+		}
+		return matches, nil
+	}
+
+	stack, err := walkStack(prs[1], list)
+	require.NoError(t, err)
+	var numbers []int
+	for _, pr := range stack {
+		numbers = append(numbers, pr.Number)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, numbers)
+	assert.Equal(t, map[int]int{2: 1, 3: 2, 4: 2}, stackParents(stack))
+
+	stack, err = walkStack(prs[4], list)
+	require.NoError(t, err)
+	assert.Len(t, stack, 1, "a PR based on main with no PRs on top is not a stack")
+}
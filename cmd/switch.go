@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// shellIntegrationEnv is set by the shell function from gh wt init when it
+// runs gh wt switch, which then only needs to print the path to cd into.
+const shellIntegrationEnv = "GH_WT_SHELL_INTEGRATION"
+
+// stdoutReserved is set by commands whose stdout carries only a worktree
+// path, so that prompts go to stderr.
+var stdoutReserved bool
+
+// switchCmd represents the switch command.
+var switchCmd = &cobra.Command{
+	Use:   "switch [worktree|number|url]",
+	Short: "Change your shell's directory to a worktree",
+	Long: heredoc.Doc(`
+		Change the current directory of your shell to a worktree.
+
+		A program cannot change the directory of the shell that started it, so
+		switch needs the shell function printed by gh wt init. Without it,
+		switch prints the worktree path on stdout, e.g. for cd "$(gh wt switch
+		pr_123)", and tells you how to set it up.
+
+		Without an argument, select one of the repository's worktrees, or of
+		every repo's worktrees outside a repository, most used first.
+	`),
+	Example: heredoc.Doc(`
+		# Set up the shell function once, e.g. in ~/.zshrc
+		eval "$(gh wt init zsh)"
+
+		# Then cd into a worktree
+		gh wt switch pr_123
+
+		# Select a worktree to cd into
+		gh wt switch
+	`),
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runSwitch,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	// Stdout carries only the path for the shell function to cd into.
+	stdoutReserved = true
+	Log.Stdout = os.Stderr
+
+	wt, err := switchTarget(args)
+	if err != nil {
+		return err
+	}
+	touchWorktree(wt)
+	fmt.Fprintln(cmd.OutOrStdout(), wt.Path)
+
+	if os.Getenv(shellIntegrationEnv) == "" && term.IsTerminal(os.Stdout) {
+		shell := filepath.Base(userShell())
+		if shell != "zsh" && shell != "fish" {
+			shell = "bash"
+		}
+		Log.Warnf("gh wt cannot change your shell's directory by itself. Run: cd %s\n", wt.Path)
+		Log.Infof("To cd automatically, add this to your shell's startup file:\n  %s\n", initLine(shell))
+	}
+	return nil
+}
+
+// switchTarget returns the worktree named by args, or one selected from the
+// repository's worktrees, including its main worktree, or outside a
+// repository from every repo's managed worktrees.
+func switchTarget(args []string) (git.WorktreeInfo, error) {
+	if len(args) > 0 {
+		return findWorktree(args[0])
+	}
+	if !term.IsTerminal(os.Stdin) {
+		return git.WorktreeInfo{}, fmt.Errorf("specify a worktree; selecting one needs an interactive terminal")
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	store := loadListMetadata()
+
+	var candidates []git.WorktreeInfo
+	if worktrees, err := git.GetWorktreeInfo(); err == nil && len(worktrees) > 0 {
+		candidates = append(worktrees[:1], filterManaged(worktrees[1:], cfg.WorktreeBase, store)...)
+	} else if candidates, err = managedWorktrees(cfg.WorktreeBase, store); err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	if len(candidates) == 0 {
+		return git.WorktreeInfo{}, fmt.Errorf("no worktrees found under %s", cfg.WorktreeBase)
+	}
+	return selectWorktree("Select a worktree to switch to:", candidates)
+}
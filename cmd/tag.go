@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/spf13/cobra"
+)
+
+var tagRemoveFlag bool
+
+// tagCmd represents the tag command.
+var tagCmd = &cobra.Command{
+	Use:   "tag <worktree|number|url> [tag...]",
+	Short: "Add, remove, or show worktree tags",
+	Long: heredoc.Doc(`
+		Label a worktree with tags to organize many concurrent worktrees.
+
+		Tags are stored in the worktree metadata and shown by gh wt list, which
+		can filter on them with --tag. Without tags, the worktree's current tags
+		are printed.
+	`),
+	Example: heredoc.Doc(`
+		# Tag a worktree
+		gh wt tag pr_123 urgent
+
+		# Add several tags at once
+		gh wt tag pr_123 backend review
+
+		# Remove a tag
+		gh wt tag pr_123 urgent --remove
+
+		# Show a worktree's tags
+		gh wt tag pr_123
+
+		# List worktrees with a tag
+		gh wt list --tag urgent
+	`),
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              runTag,
+	ValidArgsFunction: completeWorktrees,
+	GroupID:           "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.Flags().BoolVarP(&tagRemoveFlag, "remove", "d", false, "remove the given tags")
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	tags, err := parseTags(args[1:])
+	if err != nil {
+		return err
+	}
+	if tagRemoveFlag && len(tags) == 0 {
+		return fmt.Errorf("--remove needs at least one tag")
+	}
+
+	wt, err := findWorktree(args[0])
+	if err != nil {
+		return err
+	}
+
+	name := getWorktreeDisplayName(wt.Path)
+	if len(tags) == 0 {
+		store, err := metadata.Load()
+		if err != nil {
+			return err
+		}
+		entry, _ := store.Get(wt.Path)
+		if len(entry.Tags) == 0 {
+			Log.Infof("%s has no tags\n", name)
+			return nil
+		}
+		Log.Outf(logger.Default, "%s\n", strings.Join(entry.Tags, "\n"))
+		return nil
+	}
+
+	var entry metadata.Entry
+	if err := metadata.Update(func(store *metadata.Store) (bool, error) {
+		var ok bool
+		if entry, ok = store.Get(wt.Path); !ok {
+			// Worktrees created outside gh-wt have no metadata yet.
+			entry = defaultEntry(wt)
+		}
+		if tagRemoveFlag {
+			entry.RemoveTags(tags...)
+		} else {
+			entry.AddTags(tags...)
+		}
+		store.Put(entry)
+		return true, nil
+	}); err != nil {
+		return err
+	}
+
+	if len(entry.Tags) == 0 {
+		Log.Outf(logger.Green, "%s has no tags\n", name)
+	} else {
+		Log.Outf(logger.Green, "%s tags: %s\n", name, strings.Join(entry.Tags, ", "))
+	}
+	return nil
+}
+
+// parseTags validates tags given on the command line. Tags are case-sensitive
+// and may not contain whitespace or commas, so they print unambiguously.
+func parseTags(args []string) ([]string, error) {
+	tags := make([]string, 0, len(args))
+	for _, tag := range args {
+		if tag == "" || strings.ContainsAny(tag, ", \t\n") {
+			return nil, fmt.Errorf("invalid tag '%s': tags must be non-empty and contain no whitespace or commas", tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// worktreeTags returns the tags of the worktree at path, or nil when it has no
+// metadata.
+func worktreeTags(store *metadata.Store, path string) []string {
+	if store == nil {
+		return nil
+	}
+	e, _ := store.Get(path)
+	return e.Tags
+}
+
+// filterWorktreesByTags returns the worktrees that have all of tags.
+func filterWorktreesByTags(worktrees []git.WorktreeInfo, store *metadata.Store, tags []string) []git.WorktreeInfo {
+	if len(tags) == 0 {
+		return worktrees
+	}
+	var filtered []git.WorktreeInfo
+	for _, wt := range worktrees {
+		if (metadata.Entry{Tags: worktreeTags(store, wt.Path)}).HasTags(tags...) {
+			filtered = append(filtered, wt)
+		}
+	}
+	return filtered
+}
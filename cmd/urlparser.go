@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/ffalor/gh-wt/internal/config"
+)
+
+// nameFromURL returns the worktree name for input from the first URL parser
+// whose pattern matches it. The parser's branch template is rendered with the
+// pattern's named groups. The returned parser is nil when none matches.
+func nameFromURL(parsers []config.URLParser, input string) (string, *config.URLParser, error) {
+	for i := range parsers {
+		p := &parsers[i]
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid url_parsers pattern %q: %w", p.Pattern, err)
+		}
+		match := re.FindStringSubmatch(input)
+		if match == nil {
+			continue
+		}
+
+		groups := make(map[string]string)
+		for j, group := range re.SubexpNames() {
+			if group != "" {
+				groups[group] = match[j]
+			}
+		}
+
+		tmpl, err := template.New("branch").Option("missingkey=error").Parse(p.Branch)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid url_parsers branch template %q: %w", p.Branch, err)
+		}
+		var name strings.Builder
+		if err := tmpl.Execute(&name, groups); err != nil {
+			return "", nil, fmt.Errorf("failed to render url_parsers branch template %q: %w", p.Branch, err)
+		}
+		if strings.TrimSpace(name.String()) == "" {
+			return "", nil, fmt.Errorf("url_parsers branch template %q rendered an empty name for %s", p.Branch, input)
+		}
+		return strings.TrimSpace(name.String()), p, nil
+	}
+	return "", nil, nil
+}
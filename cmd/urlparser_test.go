@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameFromURL(t *testing.T) {
+	parsers := []config.URLParser{
+		{
+			Name:    "Linear",
+			Pattern: `^https://linear\.app/[^/]+/issue/(?P<key>[A-Z]+-\d+)/(?P<slug>[^/?#]+)`,
+			Branch:  "{{.key}}-{{.slug}}",
+		},
+		{
+			Name:    "Jira",
+			Pattern: `^https://[^/]+\.atlassian\.net/browse/(?P<key>[A-Z]+-\d+)`,
+			Branch:  "{{.key}}",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		parser string
+	}{
+		{name: "linear", input: "https://linear.app/acme/issue/ENG-123/fix-login", want: "ENG-123-fix-login", parser: "Linear"},
+		{name: "jira", input: "https://acme.atlassian.net/browse/PROJ-9", want: "PROJ-9", parser: "Jira"},
+		{name: "no match", input: "my-feature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, parser, err := nameFromURL(parsers, tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			if tt.parser == "" {
+				assert.Nil(t, parser)
+			} else {
+				require.NotNil(t, parser)
+				assert.Equal(t, tt.parser, parser.Name)
+			}
+		})
+	}
+}
+
+func TestNameFromURLErrors(t *testing.T) {
+	_, _, err := nameFromURL([]config.URLParser{{Pattern: "(", Branch: "x"}}, "https://example.com")
+	assert.ErrorContains(t, err, "invalid url_parsers pattern")
+
+	_, _, err = nameFromURL([]config.URLParser{{Pattern: ".", Branch: "{{.missing}}"}}, "https://example.com")
+	assert.ErrorContains(t, err, "failed to render")
+}
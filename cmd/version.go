@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: heredoc.Doc(`
+		Show the gh-wt version, commit, build date, and Go version, along with
+		the versions of the git and gh executables gh-wt uses.
+
+		Include this output when reporting a bug. The same information is
+		printed by gh wt --version.
+	`),
+	Args:    cobra.NoArgs,
+	GroupID: "utilities",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	cobra.AddTemplateFunc("versionInfo", versionInfo)
+}
+
+// versionInfo returns the build information followed by the detected git and
+// gh versions.
+func versionInfo() string {
+	return fmt.Sprintf("gh-wt version %s\ngit: %s\ngh: %s\n",
+		buildVersion(Version, Commit, Date, BuiltBy), gitVersion(), ghVersion())
+}
+
+// gitVersion returns the version of the git executable on PATH.
+func gitVersion() string {
+	out, err := git.CommandOutput("--version")
+	if err != nil {
+		return "not found"
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), "git version ")
+}
+
+// ghVersion returns the version of the gh executable running the extension.
+func ghVersion() string {
+	stdout, _, err := ghExec("--version")
+	if err != nil {
+		return "not found"
+	}
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	return strings.TrimPrefix(strings.TrimSpace(line), "gh version ")
+}
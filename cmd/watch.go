@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchIntervalFlag time.Duration
+	watchNotifyFlag   bool
+)
+
+// watchCmd represents the watch command.
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Monitor CI and review state of PR worktrees",
+	Long: heredoc.Doc(`
+		Poll the CI and review state of every PR worktree and print a line whenever
+		something changes, e.g. "PR #123 checks passed". Runs until interrupted.
+	`),
+	Example: heredoc.Doc(`
+		# Watch all PR worktrees
+		gh wt watch
+
+		# Poll every 5 minutes and send desktop notifications
+		gh wt watch --interval 5m --notify
+	`),
+	Args:    cobra.NoArgs,
+	RunE:    runWatch,
+	GroupID: "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVarP(&watchIntervalFlag, "interval", "i", time.Minute, "time between polls")
+	watchCmd.Flags().BoolVar(&watchNotifyFlag, "notify", false, "send desktop notifications on changes")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchIntervalFlag <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	previous := make(map[string]github.PullRequestStatus)
+	for {
+		entries, err := prWorktreeEntries()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 && len(previous) == 0 {
+			Log.Warnf("No PR worktrees to watch.\n")
+			return nil
+		}
+
+		refs := make([]github.PullRequestRef, len(entries))
+		for i, e := range entries {
+			refs[i] = github.PullRequestRef{Owner: e.Owner, Repo: e.Repo, Number: e.Number}
+		}
+		statuses, err := github.GetPullRequestStatuses(ctx, refs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			Log.Warnf("%v\n", err)
+		}
+
+		for _, ref := range refs {
+			key := fmt.Sprintf("%s/%s#%d", ref.Owner, ref.Repo, ref.Number)
+			status, ok := statuses[ref]
+			if !ok {
+				if err == nil {
+					Log.Warnf("%s: not found\n", key)
+				}
+				continue
+			}
+
+			old, seen := previous[key]
+			previous[key] = status
+			if !seen {
+				Log.Outf(logger.Default, "%s %s: %s\n", time.Now().Format(time.TimeOnly), key, describeStatus(status))
+				continue
+			}
+			for _, change := range describeChanges(old, status) {
+				Log.Outf(logger.Green, "%s PR #%d %s\n", time.Now().Format(time.TimeOnly), status.Number, change)
+				if watchNotifyFlag {
+					notify("gh wt", fmt.Sprintf("%s %s", key, change))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchIntervalFlag):
+		}
+	}
+}
+
+// prWorktreeEntries returns metadata for PR worktrees that still exist on disk.
+func prWorktreeEntries() ([]metadata.Entry, error) {
+	store, err := metadata.Load()
+	if err != nil {
+		return nil, err
+	}
+	var entries []metadata.Entry
+	for _, e := range store.List() {
+		if e.Type == worktree.PR && e.Owner != "" && e.Provider == "" && worktree.Exists(e.Path) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// describeStatus summarizes a PR status on one line.
+func describeStatus(s github.PullRequestStatus) string {
+	parts := []string{strings.ToLower(s.State)}
+	if s.Checks != "" {
+		parts = append(parts, "checks "+strings.ToLower(s.Checks))
+	}
+	if s.ReviewDecision != "" {
+		parts = append(parts, "review "+strings.ToLower(strings.ReplaceAll(s.ReviewDecision, "_", " ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeChanges lists human-readable changes between two polls of a PR.
+func describeChanges(old, cur github.PullRequestStatus) []string {
+	var changes []string
+	if old.State != cur.State {
+		changes = append(changes, "was "+strings.ToLower(cur.State))
+	}
+	if old.Checks != cur.Checks {
+		switch cur.Checks {
+		case "SUCCESS":
+			changes = append(changes, "checks passed")
+		case "FAILURE", "ERROR":
+			changes = append(changes, "checks failed")
+		case "PENDING", "EXPECTED":
+			changes = append(changes, "checks started")
+		case "":
+			changes = append(changes, "checks removed")
+		}
+	}
+	if old.ReviewDecision != cur.ReviewDecision {
+		switch cur.ReviewDecision {
+		case "APPROVED":
+			changes = append(changes, "was approved")
+		case "CHANGES_REQUESTED":
+			changes = append(changes, "has changes requested")
+		case "REVIEW_REQUIRED":
+			changes = append(changes, "needs review")
+		}
+	}
+	return changes
+}
+
+// notify sends a best-effort desktop notification.
+func notify(title, message string) {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "linux":
+		c = exec.Command("notify-send", title, message)
+	default:
+		return
+	}
+	if err := c.Run(); err != nil {
+		Log.Debugf("desktop notification failed: %v\n", err)
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeChanges(t *testing.T) {
+	open := github.PullRequestStatus{Number: 1, State: "OPEN", Checks: "PENDING", ReviewDecision: "REVIEW_REQUIRED"}
+
+	assert.Empty(t, describeChanges(open, open))
+
+	passed := open
+	passed.Checks = "SUCCESS"
+	assert.Equal(t, []string{"checks passed"}, describeChanges(open, passed))
+
+	merged := passed
+	merged.State = "MERGED"
+	merged.ReviewDecision = "APPROVED"
+	assert.Equal(t, []string{"was merged", "was approved"}, describeChanges(passed, merged))
+}
+
+func TestDescribeStatus(t *testing.T) {
+	s := github.PullRequestStatus{State: "OPEN", Checks: "FAILURE", ReviewDecision: "CHANGES_REQUESTED"}
+	assert.Equal(t, "open, checks failure, review changes requested", describeStatus(s))
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/spf13/cobra"
+)
+
+var whichJSONFlag bool
+
+// whichCmd represents the which command.
+var whichCmd = &cobra.Command{
+	Use:   "which <path|branch>",
+	Short: "Show which worktree a path or branch belongs to",
+	Long: heredoc.Doc(`
+		Show the managed worktree a path or branch belongs to, with its repo
+		and type, across every repo under the worktree directory.
+
+		An argument naming an existing file or directory is looked up as a
+		path, anything else as a branch. Several worktrees can have the same
+		branch in different repos; each is printed. Exits with status 1 when
+		no managed worktree matches, which makes it usable in scripts and git
+		hooks.
+	`),
+	Example: heredoc.Doc(`
+		# Which worktree is this file in?
+		gh wt which ./internal/git/git.go
+
+		# Which worktree has branch feature/auth checked out?
+		gh wt which feature/auth
+
+		# In a hook: only act inside managed worktrees
+		if gh wt which . >/dev/null 2>&1; then ...; fi
+	`),
+	Args:    cobra.ExactArgs(1),
+	RunE:    runWhich,
+	GroupID: "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+	whichCmd.Flags().BoolVar(&whichJSONFlag, "json", false, "print the matching worktrees as JSON")
+}
+
+func runWhich(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	store := loadListMetadata()
+	worktrees, err := managedWorktrees(cfg.WorktreeBase, store)
+	if err != nil {
+		return err
+	}
+
+	var matches []git.WorktreeInfo
+	if _, err := os.Stat(args[0]); err == nil {
+		if wt, ok := worktreeContaining(worktrees, args[0]); ok {
+			matches = append(matches, wt)
+		}
+	} else {
+		matches = worktreesWithBranch(worktrees, args[0])
+	}
+	if len(matches) == 0 {
+		Log.Warnf("'%s' does not belong to a managed worktree\n", args[0])
+		return silentExit(1)
+	}
+
+	entries := make([]listEntry, len(matches))
+	for i, wt := range matches {
+		entries[i] = newListEntry(wt, cfg.WorktreeBase, store)
+	}
+	return printWhich(cmd.OutOrStdout(), entries)
+}
+
+// printWhich prints entries as JSON with --json, and otherwise one per line
+// as "repo/name  type  branch  path".
+func printWhich(w io.Writer, entries []listEntry) error {
+	if whichJSONFlag {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	for _, e := range entries {
+		kind := string(e.Type)
+		if e.Number != 0 {
+			kind = fmt.Sprintf("%s #%d", e.Type, e.Number)
+		}
+		branch := e.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		Log.Outf(logger.Green, "%s/%s", e.Repo, e.Name)
+		Log.Outf(logger.Default, "  %s  %s  %s\n", kind, branch, e.Path)
+	}
+	return nil
+}
+
+// managedWorktrees returns the worktrees of every repo under base, followed
+// by those adopted from elsewhere with gh wt adopt.
+func managedWorktrees(base string, store *metadata.Store) ([]git.WorktreeInfo, error) {
+	worktrees, err := git.ListAllWorktrees(base)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list all worktrees: %w", err)
+	}
+	if store == nil {
+		return worktrees, nil
+	}
+	prefix := base + string(os.PathSeparator)
+	for _, e := range store.List() {
+		if !e.Adopted || strings.HasPrefix(e.Path, prefix) || !isDir(e.Path) {
+			continue
+		}
+		branch, err := git.CommandOutputAt(e.Path, "branch", "--show-current")
+		if err != nil {
+			continue
+		}
+		branch = strings.TrimSpace(branch)
+		worktrees = append(worktrees, git.WorktreeInfo{Path: e.Path, Branch: branch, Detached: branch == ""})
+	}
+	return worktrees, nil
+}
+
+// worktreeContaining returns the worktree whose directory contains path. Of
+// nested worktrees, the innermost wins.
+func worktreeContaining(worktrees []git.WorktreeInfo, path string) (git.WorktreeInfo, bool) {
+	target := resolvePath(path)
+	var found git.WorktreeInfo
+	ok := false
+	for _, wt := range worktrees {
+		root := resolvePath(wt.Path)
+		if target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			continue
+		}
+		if !ok || len(wt.Path) > len(found.Path) {
+			found, ok = wt, true
+		}
+	}
+	return found, ok
+}
+
+// worktreesWithBranch returns the worktrees that have branch checked out.
+func worktreesWithBranch(worktrees []git.WorktreeInfo, branch string) []git.WorktreeInfo {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	var matches []git.WorktreeInfo
+	for _, wt := range worktrees {
+		if !wt.Detached && wt.Branch == branch {
+			matches = append(matches, wt)
+		}
+	}
+	return matches
+}
+
+// resolvePath returns path made absolute with symlinks resolved, so that paths
+// through e.g. /tmp and /private/tmp on macOS compare equal.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhich(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		require.NoError(t, exec.Command("git", args...).Run())
+	}
+	t.Chdir(repo)
+
+	base := t.TempDir()
+	fix := filepath.Join(base, "repo", "fix")
+	require.NoError(t, worktree.Create(context.Background(), git.Default(), fix, "feature/fix", "HEAD"))
+	require.NoError(t, os.MkdirAll(filepath.Join(fix, "src"), 0o755))
+	adopted := filepath.Join(t.TempDir(), "spike")
+	require.NoError(t, worktree.Create(context.Background(), git.Default(), adopted, "spike", "HEAD"))
+	require.NoError(t, metadata.Record(metadata.Entry{Path: adopted, Branch: "spike", Type: worktree.Local, Adopted: true}))
+	require.NoError(t, metadata.Record(metadata.Entry{Path: fix, Branch: "feature/fix", Type: worktree.Issue, Repo: "repo", Number: 7}))
+
+	store, err := metadata.Load()
+	require.NoError(t, err)
+	worktrees, err := managedWorktrees(base, store)
+	require.NoError(t, err)
+	require.Len(t, worktrees, 2)
+
+	wt, ok := worktreeContaining(worktrees, filepath.Join(fix, "src"))
+	require.True(t, ok)
+	assert.Equal(t, fix, wt.Path)
+	_, ok = worktreeContaining(worktrees, repo)
+	assert.False(t, ok)
+
+	matches := worktreesWithBranch(worktrees, "refs/heads/spike")
+	require.Len(t, matches, 1)
+	assert.Equal(t, adopted, matches[0].Path)
+	assert.Empty(t, worktreesWithBranch(worktrees, "main"))
+
+	e := newListEntry(wt, base, store)
+	assert.Equal(t, "repo", e.Repo)
+	assert.Equal(t, worktree.Issue, e.Type)
+	assert.Equal(t, 7, e.Number)
+}
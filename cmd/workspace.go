@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var workspaceOutputFlag string
+
+// workspaceCmd represents the workspace command.
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace [repo]",
+	Short: "Write a VS Code workspace with a repo's worktrees",
+	Long: heredoc.Doc(`
+		Write a multi-root VS Code workspace with every worktree of a repo as a
+		folder, so all of them can be opened in one window.
+
+		The workspace is written to <worktree_dir>/<repo>/<repo>.code-workspace
+		and defaults to the current repo. Once it exists, gh wt add and gh wt rm
+		keep its folders in sync. Other settings in the file are preserved.
+	`),
+	Example: heredoc.Doc(`
+		# Write the workspace for the current repo
+		gh wt workspace
+
+		# Write the workspace for another repo under the worktree directory
+		gh wt workspace my-repo
+
+		# Print the workspace instead of writing it
+		gh wt workspace -o -
+
+		# Open it in VS Code
+		code ~/github/worktree/my-repo/my-repo.code-workspace
+	`),
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runWorkspace,
+	GroupID: "worktrees",
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.Flags().StringVarP(&workspaceOutputFlag, "output", "o", "", "file to write, or - for stdout")
+}
+
+func runWorkspace(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	var repo string
+	if len(args) > 0 {
+		repo = args[0]
+	} else if repo, err = currentRepoDir(cfg.WorktreeBase); err != nil {
+		return err
+	}
+
+	folders, err := workspaceFolders(cfg.WorktreeBase, repo)
+	if err != nil {
+		return err
+	}
+	if len(folders) == 0 {
+		Log.Warnf("No worktrees found under %s\n", filepath.Join(cfg.WorktreeBase, repo))
+	}
+
+	if workspaceOutputFlag == "-" {
+		data, err := marshalWorkspace(nil, folders)
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	path := workspaceOutputFlag
+	if path == "" {
+		path = workspacePath(cfg.WorktreeBase, repo)
+	}
+	if err := writeWorkspace(path, folders); err != nil {
+		return err
+	}
+	Log.Outf(logger.Green, "Wrote workspace with %d worktree(s) to %s\n", len(folders), getTildePath(path))
+	return nil
+}
+
+// workspaceFolder is a folder entry of a .code-workspace file.
+type workspaceFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// workspacePath returns the default workspace file of repo.
+func workspacePath(baseDir, repo string) string {
+	return filepath.Join(baseDir, repo, repo+".code-workspace")
+}
+
+// currentRepoDir returns the name of the current repo's directory under
+// baseDir. From a worktree it is the worktree's parent directory; otherwise
+// it is the name of the git root.
+func currentRepoDir(baseDir string) (string, error) {
+	root, err := git.GetGitRoot()
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(baseDir, root); err == nil && !strings.HasPrefix(rel, "..") && rel != "." {
+		repo, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		return repo, nil
+	}
+	return filepath.Base(root), nil
+}
+
+// workspaceFolders returns a folder for each worktree of repo, with paths
+// relative to the workspace file.
+func workspaceFolders(baseDir, repo string) ([]workspaceFolder, error) {
+	worktrees, err := git.ListAllWorktrees(baseDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	repoDir := filepath.Join(baseDir, repo)
+
+	var folders []workspaceFolder
+	for _, wt := range worktrees {
+		if filepath.Dir(wt.Path) != repoDir {
+			continue
+		}
+		name := filepath.Base(wt.Path)
+		folders = append(folders, workspaceFolder{Name: name, Path: name})
+	}
+	return folders, nil
+}
+
+// marshalWorkspace returns the workspace file with its folders replaced,
+// keeping any other settings from existing.
+func marshalWorkspace(existing []byte, folders []workspaceFolder) ([]byte, error) {
+	workspace := make(map[string]json.RawMessage)
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &workspace); err != nil {
+			return nil, fmt.Errorf("failed to parse workspace (comments and trailing commas are not supported): %w", err)
+		}
+	}
+
+	if folders == nil {
+		folders = []workspaceFolder{}
+	}
+	data, err := json.Marshal(folders)
+	if err != nil {
+		return nil, err
+	}
+	workspace["folders"] = data
+
+	out, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workspace: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// writeWorkspace writes the workspace file at path with folders, keeping any
+// other settings already in it.
+func writeWorkspace(path string, folders []workspaceFolder) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read workspace: %w", err)
+	}
+	data, err := marshalWorkspace(existing, folders)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write workspace: %w", err)
+	}
+	return nil
+}
+
+// syncWorkspace updates the workspace of the repo containing worktreePath
+// after a worktree was added or removed. Nothing happens until the workspace
+// has been written once with gh wt workspace.
+func syncWorkspace(baseDir, worktreePath string) {
+	repo := filepath.Base(filepath.Dir(worktreePath))
+	path := workspacePath(baseDir, repo)
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	folders, err := workspaceFolders(baseDir, repo)
+	if err == nil {
+		err = writeWorkspace(path, folders)
+	}
+	if err != nil {
+		Log.Warnf("Failed to update workspace %s: %v\n", getTildePath(path), err)
+		return
+	}
+	Log.Debugf("Updated workspace %s\n", path)
+}
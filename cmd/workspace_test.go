@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteWorkspace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo", "repo.code-workspace")
+
+	require.NoError(t, writeWorkspace(path, []workspaceFolder{{Name: "pr_1", Path: "pr_1"}}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"folders":[{"name":"pr_1","path":"pr_1"}]}`, string(data))
+
+	// Settings added by the user survive updates.
+	require.NoError(t, os.WriteFile(path, []byte(`{"folders":[],"settings":{"files.autoSave":"on"}}`), 0o644))
+	require.NoError(t, writeWorkspace(path, nil))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"folders":[],"settings":{"files.autoSave":"on"}}`, string(data))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"folders": [], // comment`), 0o644))
+	assert.ErrorContains(t, writeWorkspace(path, nil), "failed to parse workspace")
+}
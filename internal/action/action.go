@@ -1,20 +1,17 @@
 package action
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"runtime"
+	"slices"
 	"strings"
-	"text/template"
+	"time"
 
 	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/ffalor/gh-wt/internal/execext"
-	"github.com/ffalor/gh-wt/internal/git"
 	"github.com/ffalor/gh-wt/internal/logger"
 	"github.com/ffalor/gh-wt/internal/worktree"
 )
@@ -32,15 +29,23 @@ type ExecuteOptions struct {
 	WorktreePath string
 	Info         *worktree.WorktreeInfo
 	CLIArgs      string
-	Logger       *logger.Logger
-	Stdin        io.Reader
-	Stdout       io.Writer
-	Stderr       io.Writer
-	Env          []string
+	// Args are the arguments after --, exposed to templates as .Args.
+	Args   []string
+	Logger *logger.Logger
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Env    []string
+	// Command is the shell command run by ExecuteCommand instead of an
+	// action. It is not a template.
+	Command string
+	// Report, when set, is filled with the timing and outcome of the run.
+	Report *Report
 }
 
-// Execute runs the specified action after templating its commands.
-func Execute(ctx context.Context, opts *ExecuteOptions) error {
+// Execute runs the specified action after templating its commands, then
+// prints a summary of each command's status and duration.
+func Execute(ctx context.Context, opts *ExecuteOptions) (err error) {
 	if opts == nil {
 		return ErrNilOptions
 	}
@@ -85,94 +90,201 @@ func Execute(ctx context.Context, opts *ExecuteOptions) error {
 		return err
 	}
 
-	var action *config.Action
-	for i := range cfg.Actions {
-		if cfg.Actions[i].Name == opts.ActionName {
-			action = &cfg.Actions[i]
-			break
-		}
+	action, err := Find(cfg.Actions, opts.ActionName)
+	if err != nil {
+		return err
 	}
 
-	if action == nil {
-		var actionNames []string
-		for _, a := range cfg.Actions {
-			actionNames = append(actionNames, a.Name)
-		}
-		if len(actionNames) == 0 {
-			return fmt.Errorf("unknown action %q (no actions configured)", opts.ActionName)
+	data, err := NewTemplateData(opts.WorktreePath, opts.Info)
+	if err != nil {
+		return err
+	}
+	data.Action = opts.ActionName
+	data.CLI_ARGS = opts.CLIArgs
+	data.SetArgs(opts.Args)
+
+	if action.ReviewContext {
+		if hasReviewContext(opts.Info) {
+			path, err := writeReviewContext(ctx, opts.Info)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(path)
+			data.ReviewFile = path
+		} else {
+			opts.Logger.Warnf("Action '%s' wants review context, but %s is not a GitHub PR worktree\n", opts.ActionName, data.WorktreeName)
 		}
-		return fmt.Errorf("unknown action %q\n\nAvailable actions:\n  %s", opts.ActionName, strings.Join(actionNames, "\n  "))
 	}
 
-	// Get git root directory
-	rootDir, err := git.GetGitRoot()
+	worktreeEnv, err := Env(data, append(slices.Clip(cfg.Env), action.Env...))
+	if err != nil {
+		return err
+	}
+	worktreeEnv, err = ResolveSecrets(ctx, worktreeEnv, cfg.SecretBackends)
 	if err != nil {
-		return fmt.Errorf("failed to get git root directory: %w", err)
-	}
-
-	// Prepare data for template
-	data := struct {
-		WorktreePath string
-		WorktreeName string
-		Action       string
-		CLI_ARGS     string
-		OS           string
-		ARCH         string
-		ROOT_DIR     string
-		*worktree.WorktreeInfo
-	}{
-		WorktreePath: opts.WorktreePath,
-		WorktreeName: filepath.Base(opts.WorktreePath),
-		Action:       opts.ActionName,
-		CLI_ARGS:     opts.CLIArgs,
-		OS:           runtime.GOOS,
-		ARCH:         runtime.GOARCH,
-		ROOT_DIR:     rootDir,
-		WorktreeInfo: opts.Info,
+		return err
+	}
+	env = append(slices.Clip(env), worktreeEnv...)
+	if data.ReviewFile != "" {
+		env = append(env, "GH_WT_REVIEW_FILE="+data.ReviewFile)
 	}
 
 	runDir := opts.WorktreePath
 
 	if action.Dir != "" {
-		tmpl, err := template.New("dir").Parse(action.Dir)
+		runDir, err = Render("dir", action.Dir, data)
 		if err != nil {
-			return fmt.Errorf("failed to parse action directory template: %w", err)
-		}
-		var renderedDir bytes.Buffer
-		if err := tmpl.Execute(&renderedDir, data); err != nil {
 			return fmt.Errorf("failed to render action directory template: %w", err)
 		}
-		runDir = renderedDir.String()
 	}
 
 	opts.Logger.Outf(logger.Magenta, "\nRunning action '%s' in %s...\n", opts.ActionName, runDir)
 
-	for _, cmdStr := range action.Cmds {
-		tmpl, err := template.New("cmd").Parse(cmdStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse command template: %w", err)
+	report := newReport(opts)
+	start := time.Now()
+	defer func() {
+		report.finish(start, err)
+		report.PrintSummary(opts.Logger)
+	}()
+
+	if action.Type == config.ActionTypeExecPlugin {
+		plugin := action.Plugin
+		if plugin == "" {
+			plugin = action.Name
 		}
+		opts.Logger.Outf(logger.Magenta, "[%s]: %s%s\n", opts.ActionName, PluginPrefix, plugin)
 
-		var renderedCmd bytes.Buffer
-		if err := tmpl.Execute(&renderedCmd, data); err != nil {
+		cmdStart := time.Now()
+		err := runPlugin(ctx, plugin, runDir, PluginPayload{
+			Action:       opts.ActionName,
+			WorktreePath: data.WorktreePath,
+			WorktreeName: data.WorktreeName,
+			RootDir:      data.ROOT_DIR,
+			Branch:       opts.Info.BranchName,
+			Type:         string(opts.Info.Type),
+			Owner:        opts.Info.Owner,
+			Repo:         opts.Info.Repo,
+			Number:       opts.Info.Number,
+			CLIArgs:      opts.CLIArgs,
+			Args:         opts.Args,
+			OS:           data.OS,
+			Arch:         data.ARCH,
+		}, env, stdout, stderr)
+		report.record(PluginPrefix+plugin, cmdStart, err)
+		return err
+	}
+
+	for _, cmdStr := range action.Cmds {
+		finalCmd, err := Render("cmd", cmdStr, data)
+		if err != nil {
 			return fmt.Errorf("failed to render command template: %w", err)
 		}
 
-		finalCmd := renderedCmd.String()
 		opts.Logger.Outf(logger.Magenta, "[%s]: %s\n", opts.ActionName, finalCmd)
 
-		if err := execext.RunCommand(ctx, &execext.RunCommandOptions{
+		cmdStart := time.Now()
+		err = execext.RunCommand(ctx, &execext.RunCommandOptions{
 			Command: finalCmd,
+			Args:    opts.Args,
 			Dir:     runDir,
 			Env:     env,
 			Stdin:   stdin,
 			Stdout:  stdout,
 			Stderr:  stderr,
-		}); err != nil {
+		})
+		report.record(finalCmd, cmdStart, err)
+		if err != nil {
 			return fmt.Errorf("command '%s' failed: %w", finalCmd, err)
 		}
 	}
+	return nil
+}
+
+// newReport returns opts.Report reset for a new run, or a new report when it
+// is nil.
+func newReport(opts *ExecuteOptions) *Report {
+	report := opts.Report
+	if report == nil {
+		report = &Report{}
+	}
+	*report = Report{Action: opts.ActionName, Worktree: opts.WorktreePath}
+	return report
+}
+
+// Find returns the action named name, or an error listing the available
+// actions.
+func Find(actions []config.Action, name string) (*config.Action, error) {
+	for i := range actions {
+		if actions[i].Name == name {
+			return &actions[i], nil
+		}
+	}
+
+	var actionNames []string
+	for _, a := range actions {
+		actionNames = append(actionNames, a.Name)
+	}
+	if len(actionNames) == 0 {
+		return nil, fmt.Errorf("unknown action %q (no actions configured)", name)
+	}
+	return nil, fmt.Errorf("unknown action %q\n\nAvailable actions:\n  %s", name, strings.Join(actionNames, "\n  "))
+}
 
-	opts.Logger.Outf(logger.Green, "Action finished successfully.\n")
+// ExecuteCommand runs opts.Command in the worktree, with the same environment
+// as actions.
+func ExecuteCommand(ctx context.Context, opts *ExecuteOptions) error {
+	if opts == nil {
+		return ErrNilOptions
+	}
+	if opts.Logger == nil {
+		return ErrNilLogger
+	}
+	if strings.TrimSpace(opts.Command) == "" {
+		return fmt.Errorf("action: command is required")
+	}
+	if opts.Info == nil {
+		return fmt.Errorf("action: worktree info is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	env := opts.Env
+	if len(env) == 0 {
+		env = os.Environ()
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	data, err := NewTemplateData(opts.WorktreePath, opts.Info)
+	if err != nil {
+		return err
+	}
+	worktreeEnv, err := Env(data, cfg.Env)
+	if err != nil {
+		return err
+	}
+	worktreeEnv, err = ResolveSecrets(ctx, worktreeEnv, cfg.SecretBackends)
+	if err != nil {
+		return err
+	}
+
+	opts.Logger.Outf(logger.Magenta, "Running in worktree: %s\n", opts.Command)
+	report := newReport(opts)
+	start := time.Now()
+	err = execext.RunCommand(ctx, &execext.RunCommandOptions{
+		Command: opts.Command,
+		Dir:     opts.WorktreePath,
+		Env:     append(slices.Clip(env), worktreeEnv...),
+		Stdin:   opts.Stdin,
+		Stdout:  opts.Stdout,
+		Stderr:  opts.Stderr,
+	})
+	report.record(opts.Command, start, err)
+	report.finish(start, err)
+	if err != nil {
+		return fmt.Errorf("command '%s' failed: %w", opts.Command, err)
+	}
 	return nil
 }
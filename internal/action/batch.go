@@ -0,0 +1,270 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// Job is a single action run within a batch. Jobs without an action name
+// run Options.Command instead.
+type Job struct {
+	// Label identifies the run in prefixed output and the summary, e.g. the worktree name.
+	Label   string
+	Options ExecuteOptions
+}
+
+// Result is the outcome of a Job.
+type Result struct {
+	Label    string
+	Err      error
+	Duration time.Duration
+	// ExitCode is the exit status of the failed command, 0 on success.
+	ExitCode int
+}
+
+// BatchOptions configures ExecuteAll.
+type BatchOptions struct {
+	// Parallel is the maximum number of jobs running at once.
+	Parallel int
+	// Group buffers the output of each job and prints it in one block when
+	// the job finishes, instead of streaming it with a prefix per line.
+	Group bool
+}
+
+// prefixColors are the ANSI colors of the labels prefixed to the output of
+// concurrent jobs, cycled through in job order.
+var prefixColors = []string{"36", "35", "33", "34", "32"}
+
+// ExecuteAll runs jobs through Execute with at most batch.Parallel runs at a
+// time; the rest wait in a queue. When more than one job can run at once,
+// stdin is not shared and each job's output is either prefixed with its label
+// or, with batch.Group, printed in one block when the job finishes. Results
+// are returned in the order of jobs.
+func ExecuteAll(ctx context.Context, jobs []Job, batch BatchOptions) []Result {
+	parallel := max(batch.Parallel, 1)
+	concurrent := parallel > 1 && len(jobs) > 1
+
+	colors := make(map[string]string, len(jobs))
+	for i, job := range jobs {
+		colors[job.Label] = prefixColors[i%len(prefixColors)]
+	}
+
+	var mu sync.Mutex
+	return runQueue(ctx, jobs, parallel, func(ctx context.Context, job Job) error {
+		opts := job.Options
+		if opts.Logger != nil {
+			log := *opts.Logger
+			log.Worktree = opts.WorktreePath
+			opts.Logger = &log
+		}
+		execute := Execute
+		if opts.ActionName == "" {
+			execute = ExecuteCommand
+		}
+		if !concurrent || opts.Logger == nil {
+			return execute(ctx, &opts)
+		}
+
+		var stdout, stderr flushWriter
+		if batch.Group {
+			group := newGroupWriter(&mu, orDefault(opts.Stdout, opts.Logger.Stdout), job.Label)
+			stdout, stderr = group, group
+		} else {
+			color := ""
+			if opts.Logger.Color {
+				color = colors[job.Label]
+			}
+			stdout = newPrefixWriter(&mu, orDefault(opts.Stdout, opts.Logger.Stdout), job.Label, color)
+			stderr = newPrefixWriter(&mu, orDefault(opts.Stderr, opts.Logger.Stderr), job.Label, color)
+		}
+		defer stdout.Flush()
+		defer stderr.Flush()
+
+		// JSON events carry the worktree themselves and must not be prefixed.
+		if opts.Logger.Format != logger.FormatJSON {
+			opts.Logger.Stdout = stdout
+			opts.Logger.Stderr = stderr
+		}
+		opts.Stdout = stdout
+		opts.Stderr = stderr
+		opts.Stdin = strings.NewReader("")
+		return execute(ctx, &opts)
+	})
+}
+
+// runQueue runs fn for every job using a pool of parallel workers.
+func runQueue(ctx context.Context, jobs []Job, parallel int, fn func(context.Context, Job) error) []Result {
+	results := make([]Result, len(jobs))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(parallel, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				start := time.Now()
+				err := ctx.Err()
+				if err == nil {
+					err = fn(ctx, jobs[i])
+				}
+				results[i] = Result{Label: jobs[i].Label, Err: err, Duration: time.Since(start), ExitCode: exitCode(err)}
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// PrintSummary prints one line per result followed by the pass/fail totals.
+func PrintSummary(log *logger.Logger, results []Result) {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Label))
+	}
+
+	failed := 0
+	log.Outf(logger.Default, "\nSummary:\n")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			log.Outf(logger.Red, "  ✗ %-*s  exit %-3d  %s  %v\n", width, r.Label, r.ExitCode, r.Duration.Round(time.Millisecond), r.Err)
+			continue
+		}
+		log.Outf(logger.Green, "  ✓ %-*s  exit 0    %s\n", width, r.Label, r.Duration.Round(time.Millisecond))
+	}
+
+	c := logger.Green
+	if failed > 0 {
+		c = logger.Red
+	}
+	log.Outf(c, "%d passed, %d failed\n", len(results)-failed, failed)
+}
+
+// exitCode returns the exit status reported by err: 0 for nil, the status of
+// a failed command, or 1 for other errors.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var status interp.ExitStatus
+	if errors.As(err, &status) {
+		return int(status)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+func orDefault(w, fallback io.Writer) io.Writer {
+	if w != nil {
+		return w
+	}
+	return fallback
+}
+
+// flushWriter is a writer buffering output until it is flushed.
+type flushWriter interface {
+	io.Writer
+	Flush()
+}
+
+// prefixWriter prefixes every line with a label, in color when color is an
+// ANSI color code. Complete lines are written under a shared mutex so lines
+// from concurrent runs never interleave.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(mu *sync.Mutex, w io.Writer, label, color string) *prefixWriter {
+	prefix := "[" + label + "] "
+	if color != "" {
+		prefix = "\x1b[" + color + "m[" + label + "]\x1b[0m "
+	}
+	return &prefixWriter{mu: mu, w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		line := p.buf.Next(i + 1)
+		if err := p.writeLine(line); err != nil {
+			return len(b), err
+		}
+	}
+}
+
+// Flush writes any incomplete trailing line.
+func (p *prefixWriter) Flush() {
+	if p.buf.Len() > 0 {
+		_ = p.writeLine(append(p.buf.Bytes(), '\n'))
+		p.buf.Reset()
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line))
+	return err
+}
+
+// groupWriter buffers all output of a job and writes it in one block, under
+// a header with the job's label, when flushed.
+type groupWriter struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	label string
+	buf   bytes.Buffer
+	bufMu sync.Mutex
+}
+
+func newGroupWriter(mu *sync.Mutex, w io.Writer, label string) *groupWriter {
+	return &groupWriter{mu: mu, w: w, label: label}
+}
+
+func (g *groupWriter) Write(b []byte) (int, error) {
+	g.bufMu.Lock()
+	defer g.bufMu.Unlock()
+	return g.buf.Write(b)
+}
+
+// Flush writes the buffered output. Flushing again writes nothing.
+func (g *groupWriter) Flush() {
+	g.bufMu.Lock()
+	defer g.bufMu.Unlock()
+	if g.buf.Len() == 0 {
+		return
+	}
+	if !bytes.HasSuffix(g.buf.Bytes(), []byte("\n")) {
+		g.buf.WriteByte('\n')
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, _ = io.WriteString(g.w, "=== "+g.label+" ===\n"+g.buf.String())
+	g.buf.Reset()
+}
@@ -0,0 +1,93 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mvdan.cc/sh/v3/interp"
+)
+
+func TestRunQueueLimitsParallelism(t *testing.T) {
+	jobs := make([]Job, 8)
+	for i := range jobs {
+		jobs[i].Label = string(rune('a' + i))
+	}
+
+	var running, peak atomic.Int32
+	fail := errors.New("boom")
+	results := runQueue(context.Background(), jobs, 3, func(_ context.Context, job Job) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if job.Label == "c" {
+			return fail
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Len(t, results, len(jobs))
+	for i, r := range results {
+		assert.Equal(t, jobs[i].Label, r.Label)
+		if r.Label == "c" {
+			assert.ErrorIs(t, r.Err, fail)
+		} else {
+			assert.NoError(t, r.Err)
+		}
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := newPrefixWriter(&mu, &out, "pr_1", "")
+
+	_, _ = w.Write([]byte("hello\nwor"))
+	_, _ = w.Write([]byte("ld\npartial"))
+	w.Flush()
+
+	assert.Equal(t, "[pr_1] hello\n[pr_1] world\n[pr_1] partial\n", out.String())
+}
+
+func TestPrefixWriterColor(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := newPrefixWriter(&mu, &out, "pr_1", "36")
+
+	_, _ = w.Write([]byte("hello\n"))
+
+	assert.Equal(t, "\x1b[36m[pr_1]\x1b[0m hello\n", out.String())
+}
+
+func TestGroupWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := newGroupWriter(&mu, &out, "pr_1")
+
+	_, _ = w.Write([]byte("hello\n"))
+	_, _ = w.Write([]byte("error: partial"))
+	assert.Empty(t, out.String())
+
+	w.Flush()
+	w.Flush()
+	assert.Equal(t, "=== pr_1 ===\nhello\nerror: partial\n", out.String())
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, exitCode(nil))
+	assert.Equal(t, 3, exitCode(fmt.Errorf("command failed: %w", interp.ExitStatus(3))))
+	assert.Equal(t, 1, exitCode(errors.New("boom")))
+}
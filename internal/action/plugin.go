@@ -0,0 +1,79 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// PluginPrefix is the prefix of the executables run by exec-plugin actions.
+const PluginPrefix = "gh-wt-action-"
+
+// PluginPayload is the worktree metadata passed to exec-plugin actions. It is
+// written as JSON to the plugin's stdin and also exposed as GH_WT_*
+// environment variables.
+type PluginPayload struct {
+	Action       string `json:"action"`
+	WorktreePath string `json:"worktree_path"`
+	WorktreeName string `json:"worktree_name"`
+	RootDir      string `json:"root_dir"`
+	Branch       string `json:"branch"`
+	Type         string `json:"type"`
+	Owner        string `json:"owner,omitempty"`
+	Repo         string `json:"repo,omitempty"`
+	Number       int    `json:"number,omitempty"`
+	CLIArgs      string `json:"cli_args,omitempty"`
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	// Args are the arguments after --, unjoined.
+	Args []string `json:"args,omitempty"`
+}
+
+// environ returns the payload as GH_WT_* environment variables.
+func (p PluginPayload) environ() []string {
+	env := []string{
+		"GH_WT_ACTION=" + p.Action,
+		"GH_WT_WORKTREE_PATH=" + p.WorktreePath,
+		"GH_WT_WORKTREE_NAME=" + p.WorktreeName,
+		"GH_WT_ROOT_DIR=" + p.RootDir,
+		"GH_WT_BRANCH=" + p.Branch,
+		"GH_WT_TYPE=" + p.Type,
+		"GH_WT_OWNER=" + p.Owner,
+		"GH_WT_REPO=" + p.Repo,
+		"GH_WT_CLI_ARGS=" + p.CLIArgs,
+	}
+	if p.Number != 0 {
+		env = append(env, "GH_WT_NUMBER="+strconv.Itoa(p.Number))
+	}
+	return env
+}
+
+// runPlugin runs the gh-wt-action-<name> executable found on PATH in dir,
+// passing payload on stdin and in the environment.
+func runPlugin(ctx context.Context, name, dir string, payload PluginPayload, env []string, stdout, stderr io.Writer) error {
+	bin := PluginPrefix + name
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("plugin %s not found on PATH: %w", bin, err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin payload: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = dir
+	cmd.Env = append(append([]string{}, env...), payload.environ()...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed: %w", bin, err)
+	}
+	return nil
+}
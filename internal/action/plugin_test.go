@@ -0,0 +1,44 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$GH_WT_ACTION $GH_WT_NUMBER $(pwd)\"\ncat\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, PluginPrefix+"hello"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	var stdout, stderr bytes.Buffer
+	err = runPlugin(context.Background(), "hello", dir, PluginPayload{
+		Action: "open",
+		Type:   "pr",
+		Number: 42,
+	}, os.Environ(), &stdout, &stderr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "open 42 "+dir+"\n"+
+		`{"action":"open","worktree_path":"","worktree_name":"","root_dir":"","branch":"","type":"pr","number":42,"os":"","arch":""}`,
+		stdout.String())
+}
+
+func TestRunPluginNotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	err := runPlugin(context.Background(), "missing", t.TempDir(), PluginPayload{}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "plugin gh-wt-action-missing not found on PATH")
+}
@@ -0,0 +1,96 @@
+package action
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+)
+
+// Report is the timing and outcome of an action run and of each of its
+// commands, printed as a summary and exposed as JSON.
+type Report struct {
+	Action   string `json:"action,omitempty"`
+	Worktree string `json:"worktree"`
+	Success  bool   `json:"success"`
+	// DurationMs is the wall time of the whole run, in milliseconds.
+	DurationMs int64           `json:"durationMs"`
+	Commands   []CommandReport `json:"commands"`
+}
+
+// CommandReport is the timing and outcome of one command of an action.
+type CommandReport struct {
+	Command    string `json:"command"`
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   int    `json:"exitCode"`
+	Error      string `json:"error,omitempty"`
+}
+
+// record adds the outcome of command, started at start, to the report.
+func (r *Report) record(command string, start time.Time, err error) {
+	c := CommandReport{
+		Command:    command,
+		DurationMs: time.Since(start).Milliseconds(),
+		ExitCode:   exitCode(err),
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	r.Commands = append(r.Commands, c)
+}
+
+// finish records the outcome of the whole run, started at start.
+func (r *Report) finish(start time.Time, err error) {
+	r.Success = err == nil
+	r.DurationMs = time.Since(start).Milliseconds()
+}
+
+// PrintSummary prints the report: a single line for a successful
+// one-command run, and otherwise each command's status and duration followed
+// by the total wall time. Runs that did not reach a command print nothing.
+func (r *Report) PrintSummary(log *logger.Logger) {
+	if len(r.Commands) == 0 {
+		return
+	}
+	if len(r.Commands) == 1 && r.Success {
+		log.Outf(logger.Green, "Action finished successfully in %s.\n", ms(r.DurationMs))
+		return
+	}
+
+	width := 0
+	for _, c := range r.Commands {
+		width = max(width, utf8.RuneCountInString(summaryCommand(c.Command)))
+	}
+	log.Outf(logger.Default, "\nCommands:\n")
+	for _, c := range r.Commands {
+		if c.Error != "" {
+			log.Outf(logger.Red, "  ✗ %-*s  %s  exit %d\n", width, summaryCommand(c.Command), ms(c.DurationMs), c.ExitCode)
+			continue
+		}
+		log.Outf(logger.Green, "  ✓ %-*s  %s\n", width, summaryCommand(c.Command), ms(c.DurationMs))
+	}
+	c := logger.Green
+	if !r.Success {
+		c = logger.Red
+	}
+	log.Outf(c, "Total: %s\n", ms(r.DurationMs))
+}
+
+// summaryCommand shortens a command to its first line, capped at 60
+// characters, for the summary table.
+func summaryCommand(command string) string {
+	for i, r := range command {
+		if r == '\n' {
+			command = command[:i] + " …"
+			break
+		}
+	}
+	if runes := []rune(command); len(runes) > 60 {
+		command = string(runes[:59]) + "…"
+	}
+	return command
+}
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
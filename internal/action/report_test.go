@@ -0,0 +1,56 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mvdan.cc/sh/v3/interp"
+)
+
+func TestReportRecord(t *testing.T) {
+	r := &Report{}
+	start := time.Now()
+	r.record("echo ok", start, nil)
+	r.record("false", start, interp.ExitStatus(1))
+	r.finish(start, errors.New("failed"))
+
+	require.Len(t, r.Commands, 2)
+	assert.Equal(t, CommandReport{Command: "echo ok"}, r.Commands[0])
+	assert.Equal(t, 1, r.Commands[1].ExitCode)
+	assert.NotEmpty(t, r.Commands[1].Error)
+	assert.False(t, r.Success)
+}
+
+func TestReportPrintSummary(t *testing.T) {
+	var buf bytes.Buffer
+	log := &logger.Logger{Stdout: &buf}
+
+	r := &Report{Success: true, DurationMs: 1500, Commands: []CommandReport{{Command: "make", DurationMs: 1500}}}
+	r.PrintSummary(log)
+	assert.Equal(t, "Action finished successfully in 1.5s.\n", buf.String())
+
+	buf.Reset()
+	r = &Report{DurationMs: 2000, Commands: []CommandReport{
+		{Command: "make build", DurationMs: 1200},
+		{Command: "make test\nmake lint", DurationMs: 800, ExitCode: 2, Error: "exit status 2"},
+	}}
+	r.PrintSummary(log)
+	assert.Equal(t, strings.Join([]string{
+		"",
+		"Commands:",
+		"  ✓ make build   1.2s",
+		"  ✗ make test …  800ms  exit 2",
+		"Total: 2s",
+		"",
+	}, "\n"), buf.String())
+
+	buf.Reset()
+	(&Report{}).PrintSummary(log)
+	assert.Empty(t, buf.String())
+}
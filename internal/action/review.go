@@ -0,0 +1,44 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// getReviewContext fetches the review context of a pull request. Tests
+// replace it to avoid calling the GitHub API.
+var getReviewContext = github.GetReviewContext
+
+// hasReviewContext reports whether a review context can be fetched for the
+// worktree described by info: a PR worktree on GitHub.
+func hasReviewContext(info *worktree.WorktreeInfo) bool {
+	return info.Type == worktree.PR && info.Number != 0 && info.Provider == ""
+}
+
+// writeReviewContext writes the changed files and unresolved review threads
+// of the PR of info to a temporary Markdown file and returns its path. The
+// caller removes the file.
+func writeReviewContext(ctx context.Context, info *worktree.WorktreeInfo) (string, error) {
+	rc, err := getReviewContext(ctx, info.Owner, info.Repo, info.Number)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("gh-wt-review-pr%d-*.md", info.Number))
+	if err != nil {
+		return "", fmt.Errorf("failed to create review context file: %w", err)
+	}
+	if _, err := f.WriteString(rc.Markdown()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write review context file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write review context file: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,32 @@
+package action
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/github"
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReviewContext(t *testing.T) {
+	orig := getReviewContext
+	t.Cleanup(func() { getReviewContext = orig })
+	getReviewContext = func(_ context.Context, owner, repo string, number int) (github.ReviewContext, error) {
+		return github.ReviewContext{Number: number, Title: owner + "/" + repo}, nil
+	}
+
+	info := &worktree.WorktreeInfo{Type: worktree.PR, Owner: "octo", Repo: "cli", Number: 12}
+	assert.True(t, hasReviewContext(info))
+	assert.False(t, hasReviewContext(&worktree.WorktreeInfo{Type: worktree.Issue, Number: 12}))
+	assert.False(t, hasReviewContext(&worktree.WorktreeInfo{Type: worktree.PR, Number: 12, Provider: "gitlab"}))
+
+	path, err := writeReviewContext(context.Background(), info)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(path) })
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# PR #12: octo/cli\n")
+}
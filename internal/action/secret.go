@@ -0,0 +1,102 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/execext"
+)
+
+// SecretSchemeEnv is the scheme of env://NAME references, which read NAME
+// from the environment of gh wt itself.
+const SecretSchemeEnv = "env"
+
+// BuiltinSecretBackends are the secret backends available without config.
+// Configured backends with the same scheme replace them.
+var BuiltinSecretBackends = []config.SecretBackend{
+	{Scheme: "op", Command: `op read "$1"`},
+	{Scheme: "pass", Command: `pass show "$2" | head -n 1`},
+}
+
+// secretRef matches secret references, <scheme>://<path>.
+var secretRef = regexp.MustCompile(`^([a-z][a-z0-9+.-]*)://(.+)$`)
+
+// ResolveSecrets returns env with every value referencing a secret, such as
+// op://vault/item/field, replaced by the secret read from its backend. Values
+// whose scheme has no backend are kept as they are. Secrets are read each time
+// a command runs, so they never need to be stored in the config file.
+func ResolveSecrets(ctx context.Context, env []string, backends []config.SecretBackend) ([]string, error) {
+	commands := map[string]string{}
+	for _, b := range BuiltinSecretBackends {
+		commands[b.Scheme] = b.Command
+	}
+	for _, b := range backends {
+		commands[b.Scheme] = b.Command
+	}
+
+	resolved := make([]string, len(env))
+	secrets := map[string]string{}
+	for i, kv := range env {
+		resolved[i] = kv
+		name, value, _ := strings.Cut(kv, "=")
+		m := secretRef.FindStringSubmatch(value)
+		if m == nil {
+			continue
+		}
+		command, ok := commands[m[1]]
+		if !ok && m[1] != SecretSchemeEnv {
+			continue
+		}
+		secret, ok := secrets[value]
+		if !ok {
+			var err error
+			secret, err = readSecret(ctx, command, value, m[1], m[2])
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s for %s: %w", value, name, err)
+			}
+			secrets[value] = secret
+		}
+		resolved[i] = name + "=" + secret
+	}
+	return resolved, nil
+}
+
+// readSecret returns the secret referenced by ref. A command backend is run
+// with ref as $1 and path as $2, and its output, without the trailing newline,
+// is the secret. An empty command reads env:// references.
+func readSecret(ctx context.Context, command, ref, scheme, path string) (string, error) {
+	if command == "" && scheme == SecretSchemeEnv {
+		secret, ok := os.LookupEnv(path)
+		if !ok {
+			return "", fmt.Errorf("%s is not set", path)
+		}
+		return secret, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := execext.RunCommand(ctx, &execext.RunCommandOptions{
+		Command: command,
+		Args:    []string{ref, path},
+		Env:     os.Environ(),
+		Stdin:   os.Stdin,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	secret := strings.TrimRight(stdout.String(), "\r\n")
+	if secret == "" {
+		return "", errors.New("backend printed nothing")
+	}
+	return secret, nil
+}
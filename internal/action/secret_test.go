@@ -0,0 +1,46 @@
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("GH_WT_TEST_TOKEN", "s3cret")
+
+	env, err := ResolveSecrets(context.Background(), []string{
+		"COMPOSE_PROJECT_NAME=repo-pr_12",
+		"TOKEN=env://GH_WT_TEST_TOKEN",
+		"API_KEY=test://vault/item/field",
+		"URL=https://example.com",
+	}, []config.SecretBackend{
+		{Scheme: "test", Command: `echo "$2"; echo "$1"`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"COMPOSE_PROJECT_NAME=repo-pr_12",
+		"TOKEN=s3cret",
+		"API_KEY=vault/item/field\ntest://vault/item/field",
+		"URL=https://example.com",
+	}, env)
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	_, err := ResolveSecrets(context.Background(), []string{"TOKEN=env://GH_WT_TEST_UNSET"}, nil)
+	assert.ErrorContains(t, err, "failed to resolve env://GH_WT_TEST_UNSET for TOKEN: GH_WT_TEST_UNSET is not set")
+
+	_, err = ResolveSecrets(context.Background(), []string{"API_KEY=test://x"}, []config.SecretBackend{
+		{Scheme: "test", Command: `echo "not signed in" >&2; exit 1`},
+	})
+	assert.ErrorContains(t, err, "failed to resolve test://x for API_KEY")
+	assert.ErrorContains(t, err, "not signed in")
+
+	_, err = ResolveSecrets(context.Background(), []string{"API_KEY=test://x"}, []config.SecretBackend{
+		{Scheme: "test", Command: "true"},
+	})
+	assert.ErrorContains(t, err, "backend printed nothing")
+}
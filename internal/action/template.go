@@ -0,0 +1,152 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// TemplateData is the data available to action templates and to other
+// templates rendered for a worktree, such as the direnv .envrc.
+type TemplateData struct {
+	WorktreePath string
+	WorktreeName string
+	Action       string
+	CLI_ARGS     string
+	// Args are the arguments after --, one element per argument.
+	Args []string
+	// ArgsJoined is Args joined with spaces.
+	ArgsJoined string
+	// ArgsQuoted is Args shell-quoted and joined with spaces, safe to splice
+	// into a command line.
+	ArgsQuoted string
+	OS         string
+	ARCH       string
+	ROOT_DIR   string
+	// ComposeProjectName isolates Docker Compose projects per worktree.
+	ComposeProjectName string
+	// Port is the first port of the block assigned to the worktree.
+	Port int
+	// ReviewFile is the path of the PR review context file written for
+	// actions with review_context set.
+	ReviewFile string
+	*worktree.WorktreeInfo
+}
+
+// NewTemplateData returns the template data for the worktree at worktreePath.
+// Port is the first port of the block assigned when the worktree was
+// created, and 0 for directories without one. ROOT_DIR is the main
+// worktree of the repository worktreePath belongs to, and empty when it is
+// not in a repository. Action and the CLI arguments are left for the caller
+// to set with SetArgs.
+func NewTemplateData(worktreePath string, info *worktree.WorktreeInfo) (TemplateData, error) {
+	port, err := ports.Lookup(worktreePath)
+	if err != nil {
+		return TemplateData{}, fmt.Errorf("failed to look up ports: %w", err)
+	}
+	name := filepath.Base(worktreePath)
+	repo := filepath.Base(filepath.Dir(worktreePath))
+	if info != nil && info.Repo != "" {
+		repo = info.Repo
+	}
+	return TemplateData{
+		WorktreePath:       worktreePath,
+		WorktreeName:       name,
+		OS:                 runtime.GOOS,
+		ARCH:               runtime.GOARCH,
+		ROOT_DIR:           rootDir(worktreePath),
+		ComposeProjectName: ComposeProjectName(repo, name),
+		Port:               port,
+		WorktreeInfo:       info,
+	}, nil
+}
+
+// rootDir returns the main worktree of the repository dir belongs to, or ""
+// when dir is not in a repository.
+func rootDir(dir string) string {
+	worktrees, err := git.GetWorktreeInfoAt(dir)
+	if err != nil || len(worktrees) == 0 {
+		return ""
+	}
+	return worktrees[0].Path
+}
+
+// SetArgs sets the arguments after -- in the template data.
+func (d *TemplateData) SetArgs(args []string) {
+	d.Args = args
+	d.ArgsJoined = strings.Join(args, " ")
+	d.ArgsQuoted = QuoteArgs(args)
+}
+
+// shellSafe matches arguments that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// QuoteArgs returns args quoted for a POSIX shell and joined with spaces, so
+// that the shell splits the result back into the same arguments.
+func QuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if shellSafe.MatchString(arg) {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// composeInvalid matches characters not allowed in Compose project names.
+var composeInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ComposeProjectName returns a Docker Compose project name unique to a
+// worktree, <repo>-<worktree>, so the same stack can run in several worktrees
+// without colliding on container and network names. Compose allows only
+// lowercase letters, digits, dashes, and underscores, so accented letters are
+// transliterated first.
+func ComposeProjectName(repo, worktreeName string) string {
+	name := worktree.Transliterate(repo + "-" + worktreeName)
+	name = composeInvalid.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.TrimLeft(name, "-_")
+}
+
+// Env returns the environment variables describing a worktree:
+// COMPOSE_PROJECT_NAME and GH_WT_PORT followed by the configured vars, whose
+// values are templates rendered with data. Configured vars may override the
+// others.
+func Env(data TemplateData, vars []config.EnvVar) ([]string, error) {
+	env := []string{"COMPOSE_PROJECT_NAME=" + data.ComposeProjectName}
+	if data.Port != 0 {
+		env = append(env, "GH_WT_PORT="+strconv.Itoa(data.Port))
+	}
+	for _, v := range vars {
+		value, err := Render(v.Name, v.Value, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render env %s: %w", v.Name, err)
+		}
+		env = append(env, v.Name+"="+value)
+	}
+	return env, nil
+}
+
+// Render parses text as a Go template named name and executes it with data.
+func Render(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,103 @@
+package action
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeProjectName(t *testing.T) {
+	assert.Equal(t, "gh-wt-pr_12", ComposeProjectName("gh-wt", "pr_12"))
+	assert.Equal(t, "my-app-fix-login-page", ComposeProjectName("My.App", "Fix Login/Page"))
+	assert.Equal(t, "app-x", ComposeProjectName("_app", "x"))
+	assert.Equal(t, "app-cafe-strasse", ComposeProjectName("app", "Café 🚀 Straße"))
+}
+
+func TestEnv(t *testing.T) {
+	data := TemplateData{WorktreeName: "pr_12", ComposeProjectName: "repo-pr_12", Port: 4010}
+
+	env, err := Env(data, []config.EnvVar{
+		{Name: "DATABASE_NAME", Value: "app_{{.WorktreeName}}"},
+		{Name: "API_PORT", Value: "{{.Port}}"},
+		{Name: "COMPOSE_PROJECT_NAME", Value: "custom"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"COMPOSE_PROJECT_NAME=repo-pr_12",
+		"GH_WT_PORT=4010",
+		"DATABASE_NAME=app_pr_12",
+		"API_PORT=4010",
+		"COMPOSE_PROJECT_NAME=custom",
+	}, env)
+
+	_, err = Env(data, []config.EnvVar{{Name: "BAD", Value: "{{.Nope"}})
+	assert.ErrorContains(t, err, "failed to render env BAD")
+}
+
+func TestSetArgs(t *testing.T) {
+	var data TemplateData
+	data.SetArgs([]string{"fix the bug", "--debug", "it's"})
+
+	assert.Equal(t, []string{"fix the bug", "--debug", "it's"}, data.Args)
+	assert.Equal(t, "fix the bug --debug it's", data.ArgsJoined)
+	assert.Equal(t, `'fix the bug' --debug 'it'\''s'`, data.ArgsQuoted)
+
+	out, err := Render("cmd", `echo {{index .Args 0}}|{{.ArgsQuoted}}`, data)
+	require.NoError(t, err)
+	assert.Equal(t, `echo fix the bug|'fix the bug' --debug 'it'\''s'`, out)
+}
+
+func TestQuoteArgs(t *testing.T) {
+	assert.Equal(t, "", QuoteArgs(nil))
+	assert.Equal(t, "a/b.go key=value", QuoteArgs([]string{"a/b.go", "key=value"}))
+	assert.Equal(t, `'' '$HOME' 'a"b'`, QuoteArgs([]string{"", "$HOME", `a"b`}))
+}
+
+func TestNewTemplateDataRootDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repo, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	wt := filepath.Join(t.TempDir(), "topic")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"worktree", "add", "-q", "-b", "topic", wt},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	data, err := NewTemplateData(wt, nil)
+	require.NoError(t, err)
+	assert.Equal(t, repo, data.ROOT_DIR, "resolved from the worktree, not the current directory")
+
+	data, err = NewTemplateData(t.TempDir(), nil)
+	require.NoError(t, err, "directories outside a repository are fine")
+	assert.Empty(t, data.ROOT_DIR)
+}
+
+func TestNewTemplateDataOnlyLooksUpPorts(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	data, err := NewTemplateData(dir, nil)
+	require.NoError(t, err)
+	assert.Zero(t, data.Port, "no block is assigned to directories without one")
+	path, err := ports.Path()
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+
+	port, err := ports.Assign(dir, config.PortsConfig{Start: 4100})
+	require.NoError(t, err)
+	data, err = NewTemplateData(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, port, data.Port)
+}
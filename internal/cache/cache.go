@@ -0,0 +1,140 @@
+// Package cache keeps short-lived results, such as shell completions and
+// pull request states, so shell integrations don't wait on git or GitHub.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/lock"
+)
+
+// FileName is the name of the cache inside the state directory.
+const FileName = "cache.json"
+
+// lockTimeout is how long a write waits for another process updating the
+// cache. The cache is only an optimization, so a busy cache is not written.
+const lockTimeout = 2 * time.Second
+
+// cacheEntry is a cached value and when it was computed.
+type cacheEntry struct {
+	Time   time.Time `json:"time"`
+	Values []string  `json:"values"`
+}
+
+// Path returns the location of the cache.
+func Path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Get returns the value stored under key if it is younger than ttl. Otherwise
+// it calls compute and stores its result. A ttl of zero or less disables the
+// cache. Cache errors are ignored: callers such as completion must never fail
+// because of them.
+func Get(key string, ttl time.Duration, compute func() []string) []string {
+	if ttl <= 0 {
+		return compute()
+	}
+	path, err := Path()
+	if err != nil {
+		return compute()
+	}
+
+	entries := readCache(path)
+	now := time.Now()
+	if e, ok := entries[key]; ok && now.Sub(e.Time) < ttl {
+		return e.Values
+	}
+
+	values := compute()
+	update(path, func(entries map[string]cacheEntry) {
+		for k, e := range entries {
+			if now.Sub(e.Time) >= ttl {
+				delete(entries, k)
+			}
+		}
+		entries[key] = cacheEntry{Time: now, Values: values}
+	})
+	return values
+}
+
+// Clear removes all cached values, e.g. after a worktree was added or removed.
+func Clear() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	l, err := acquire(path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// acquire takes the lock of the cache at path.
+func acquire(path string) (*lock.Lock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	return lock.Acquire(ctx, path+".lock", "cache update", nil)
+}
+
+// update applies fn to the entries of the cache at path and writes them back,
+// holding the cache's lock so that concurrent gh wt processes, e.g. prompts of
+// several shells, don't drop each other's entries.
+func update(path string, fn func(entries map[string]cacheEntry)) {
+	l, err := acquire(path)
+	if err != nil {
+		return
+	}
+	defer l.Release()
+	entries := readCache(path)
+	fn(entries)
+	writeCache(path, entries)
+}
+
+func readCache(path string) map[string]cacheEntry {
+	entries := make(map[string]cacheEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil || entries == nil {
+		return make(map[string]cacheEntry)
+	}
+	return entries
+}
+
+func writeCache(path string, entries map[string]cacheEntry) {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), FileName+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	if errors.Join(werr, tmp.Close()) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	calls := 0
+	compute := func() []string {
+		calls++
+		return []string{"a\tfirst", "b"}
+	}
+
+	assert.Equal(t, []string{"a\tfirst", "b"}, Get("worktrees", time.Minute, compute))
+	assert.Equal(t, []string{"a\tfirst", "b"}, Get("worktrees", time.Minute, compute))
+	assert.Equal(t, 1, calls, "second call is served from the cache")
+
+	Get("actions", time.Minute, compute)
+	assert.Equal(t, 2, calls, "keys are cached separately")
+
+	Get("worktrees", 0, compute)
+	assert.Equal(t, 3, calls, "zero ttl disables the cache")
+
+	require.NoError(t, Clear())
+	Get("worktrees", time.Minute, compute)
+	assert.Equal(t, 4, calls, "cleared cache is recomputed")
+}
+
+func TestGetExpires(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	calls := 0
+	compute := func() []string {
+		calls++
+		return nil
+	}
+
+	Get("worktrees", time.Nanosecond, compute)
+	time.Sleep(time.Millisecond)
+	Get("worktrees", time.Nanosecond, compute)
+	assert.Equal(t, 2, calls)
+}
+
+func TestGetConcurrently(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Get(fmt.Sprintf("key%d", i), time.Minute, func() []string { return []string{"v"} })
+		}()
+	}
+	wg.Wait()
+
+	path, err := Path()
+	require.NoError(t, err)
+	assert.Len(t, readCache(path), n, "no entry is lost")
+}
@@ -28,56 +28,56 @@ const (
 
 // DetectShell detects the current shell from environment variables
 func DetectShell(Log *logger.Logger) ShellType {
-	Log.Plainf("Detecting current shell\n")
+	Log.Debugf("Detecting current shell\n")
 
 	// Check shell-specific version variables first (most reliable)
 	if os.Getenv("ZSH_VERSION") != "" {
-		Log.Plainf("Detected zsh from ZSH_VERSION\n")
+		Log.Debugf("Detected zsh from ZSH_VERSION\n")
 		return ShellZsh
 	}
 	if os.Getenv("BASH_VERSION") != "" {
-		Log.Plainf("Detected bash from BASH_VERSION\n")
+		Log.Debugf("Detected bash from BASH_VERSION\n")
 		return ShellBash
 	}
 	if os.Getenv("FISH_VERSION") != "" {
-		Log.Plainf("Detected fish from FISH_VERSION\n")
+		Log.Debugf("Detected fish from FISH_VERSION\n")
 		return ShellFish
 	}
 
 	// Fall back to $SHELL environment variable
 	shell := os.Getenv("SHELL")
 	if shell == "" {
-		Log.Plainf("SHELL environment variable not set, checking platform\n")
+		Log.Debugf("SHELL environment variable not set, checking platform\n")
 		// On Windows, check for PowerShell
 		if runtime.GOOS == "windows" {
-			Log.Plainf("Detected Windows, assuming PowerShell\n")
+			Log.Debugf("Detected Windows, assuming PowerShell\n")
 			return ShellPowerShell
 		}
-		Log.Plainf("Could not detect shell\n")
+		Log.Debugf("Could not detect shell\n")
 		return ShellUnknown
 	}
 
-	Log.Plainf("SHELL environment variable: %s\n", shell)
+	Log.Debugf("SHELL environment variable: %s\n", shell)
 
 	// Extract shell name from path
 	shellName := filepath.Base(shell)
-	Log.Plainf("Shell base name: %s\n", shellName)
+	Log.Debugf("Shell base name: %s\n", shellName)
 
 	switch {
 	case strings.Contains(shellName, "bash"):
-		Log.Plainf("Detected bash from SHELL\n")
+		Log.Debugf("Detected bash from SHELL\n")
 		return ShellBash
 	case strings.Contains(shellName, "zsh"):
-		Log.Plainf("Detected zsh from SHELL\n")
+		Log.Debugf("Detected zsh from SHELL\n")
 		return ShellZsh
 	case strings.Contains(shellName, "fish"):
-		Log.Plainf("Detected fish from SHELL\n")
+		Log.Debugf("Detected fish from SHELL\n")
 		return ShellFish
 	case strings.Contains(shellName, "pwsh") || strings.Contains(shellName, "powershell"):
-		Log.Plainf("Detected PowerShell from SHELL\n")
+		Log.Debugf("Detected PowerShell from SHELL\n")
 		return ShellPowerShell
 	default:
-		Log.Plainf("Unknown shell: %s\n", shellName)
+		Log.Debugf("Unknown shell: %s\n", shellName)
 		return ShellUnknown
 	}
 }
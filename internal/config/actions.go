@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// settings returns the action as it is written to the config file, omitting
+// unset fields.
+func (a Action) settings() map[string]any {
+	m := map[string]any{"name": a.Name}
+	if a.Type != "" {
+		m["type"] = a.Type
+	}
+	if a.Plugin != "" {
+		m["plugin"] = a.Plugin
+	}
+	if len(a.Cmds) > 0 {
+		m["cmds"] = a.Cmds
+	}
+	if a.Dir != "" {
+		m["dir"] = a.Dir
+	}
+	if a.ReviewContext {
+		m["review_context"] = true
+	}
+	if len(a.Env) > 0 {
+		env := make([]map[string]any, len(a.Env))
+		for i, e := range a.Env {
+			env[i] = map[string]any{"name": e.Name, "value": e.Value}
+		}
+		m["env"] = env
+	}
+	return m
+}
+
+// MarshalAction returns a as a YAML document, the way it is written to the
+// config file.
+func MarshalAction(a Action) ([]byte, error) {
+	data, err := yaml.Marshal(a.settings())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode action: %w", err)
+	}
+	return data, nil
+}
+
+// ParseAction parses and validates a YAML document holding one action, such
+// as one produced by MarshalAction.
+func ParseAction(data []byte) (Action, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Action{}, fmt.Errorf("failed to parse action: %w", err)
+	}
+	if doc == nil {
+		return Action{}, errors.New("the action is empty")
+	}
+	wrapped, err := yaml.Marshal(map[string]any{"actions": []any{doc}})
+	if err != nil {
+		return Action{}, fmt.Errorf("failed to encode action: %w", err)
+	}
+	if err := validateDocument(wrapped, "invalid action"); err != nil {
+		return Action{}, err
+	}
+
+	av := viper.New()
+	av.SetConfigType(ConfigType)
+	if err := av.ReadConfig(bytes.NewReader(wrapped)); err != nil {
+		return Action{}, fmt.Errorf("failed to parse action: %w", err)
+	}
+	var actions []Action
+	if err := av.UnmarshalKey("actions", &actions); err != nil || len(actions) != 1 {
+		return Action{}, fmt.Errorf("failed to decode action: %v", err)
+	}
+	return actions[0], nil
+}
+
+// ValidateActions checks actions against Schema, as they would be written to
+// the config file.
+func ValidateActions(actions []Action) error {
+	settings := make([]map[string]any, len(actions))
+	for i, a := range actions {
+		settings[i] = a.settings()
+	}
+	data, err := yaml.Marshal(map[string]any{"actions": settings})
+	if err != nil {
+		return fmt.Errorf("failed to encode actions: %w", err)
+	}
+	return validateDocument(data, "invalid action")
+}
+
+// validateDocument checks data against Schema, returning an error starting
+// with prefix that lists the problems found.
+func validateDocument(data []byte, prefix string) error {
+	problems, err := Validate(data)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = fmt.Errorf("%s: %s", p.Location(), p.Message)
+	}
+	return fmt.Errorf("%s:\n%w", prefix, errors.Join(errs...))
+}
+
+// SaveActions validates actions and writes them to the config file, replacing
+// its actions and keeping its other settings. Actions unchanged from an
+// include or the repository config are left there; where one shadows an
+// action of the config file, the config file keeps its own.
+func SaveActions(actions []Action) error {
+	if err := ValidateActions(actions); err != nil {
+		return err
+	}
+
+	fv, path, err := fileViper()
+	if err != nil {
+		return err
+	}
+	current, _ := fv.Get("actions").([]any)
+	settings := make([]any, 0, len(actions))
+	merged := make([]any, len(actions))
+	for i, a := range actions {
+		merged[i] = a.settings()
+		if ActionSource(a) == "" {
+			settings = append(settings, merged[i])
+			continue
+		}
+		for _, c := range current {
+			if actionName(c) == a.Name {
+				settings = append(settings, c)
+			}
+		}
+	}
+
+	fv.Set("actions", settings)
+	if err := fv.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config to %s: %w", path, err)
+	}
+	v.Set("actions", merged)
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAction(t *testing.T) {
+	a := Action{Name: "test", Cmds: []string{"go test ./..."}, Dir: "{{.WorktreePath}}/api", Env: []EnvVar{{Name: "CI", Value: "1"}}}
+	data, err := MarshalAction(a)
+	require.NoError(t, err)
+	parsed, err := ParseAction(data)
+	require.NoError(t, err)
+	assert.Equal(t, a, parsed)
+
+	_, err = ParseAction([]byte("name: test\ncmd: [make]\n"))
+	assert.ErrorContains(t, err, "cmd")
+	_, err = ParseAction([]byte("name: test\ncmds: ['{{.Bad']\n"))
+	assert.ErrorContains(t, err, "invalid template")
+	_, err = ParseAction([]byte("# nothing\n"))
+	assert.Error(t, err)
+}
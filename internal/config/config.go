@@ -1,31 +1,243 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Action types.
+const (
+	// ActionTypeShell runs the action's Cmds with the built-in shell (default).
+	ActionTypeShell = "shell"
+	// ActionTypeExecPlugin runs an external gh-wt-action-<plugin> executable.
+	ActionTypeExecPlugin = "exec-plugin"
 )
 
 // Action defines a named set of commands to run.
 type Action struct {
 	Name string   `mapstructure:"name"`
+	Type string   `mapstructure:"type"`
 	Cmds []string `mapstructure:"cmds"`
 	Dir  string   `mapstructure:"dir"`
+	// Plugin names the executable run by exec-plugin actions. Defaults to Name.
+	Plugin string `mapstructure:"plugin"`
+	// ReviewContext writes the changed files and unresolved review threads of
+	// the worktree's PR to a temporary file, exposed as .ReviewFile.
+	ReviewContext bool `mapstructure:"review_context"`
+	// Env is set for this action, after the top-level Env.
+	Env []EnvVar `mapstructure:"env"`
+}
+
+// ProjectConfig identifies a GitHub Projects (v2) single-select field to update.
+// It is disabled while Number is 0.
+type ProjectConfig struct {
+	// Owner is the user or organization owning the project. Defaults to the repo owner.
+	Owner  string `mapstructure:"owner"`
+	Number int    `mapstructure:"number"`
+	Field  string `mapstructure:"field"`
+	Value  string `mapstructure:"value"`
+}
+
+// IssueConfig controls GitHub updates made when creating issue worktrees.
+type IssueConfig struct {
+	// Assign assigns the current user to the issue.
+	Assign bool `mapstructure:"assign"`
+	// Project moves the issue's project item when its worktree is created.
+	Project ProjectConfig `mapstructure:"project"`
+}
+
+// URLParser maps URLs from other trackers, such as Jira or Linear, to the
+// name of a local worktree.
+type URLParser struct {
+	Name string `mapstructure:"name"`
+	// Pattern is a regular expression matched against the URL. Its named
+	// groups are available to Branch.
+	Pattern string `mapstructure:"pattern"`
+	// Branch is a template rendering the worktree and branch name, e.g.
+	// "{{.key}}-{{.slug}}".
+	Branch string `mapstructure:"branch"`
+}
+
+// Identity sets the git identity of new worktrees whose repo and type match.
+type Identity struct {
+	// Repo is a glob matched against "owner/repo", e.g. "acme/*". Empty
+	// matches every repo.
+	Repo string `mapstructure:"repo"`
+	// Type is pr, issue, or local. Empty matches every type.
+	Type string `mapstructure:"type"`
+	// Name and Email set user.name and user.email.
+	Name  string `mapstructure:"name"`
+	Email string `mapstructure:"email"`
+	// SigningKey sets user.signingkey.
+	SigningKey string `mapstructure:"signing_key"`
+	// Sign sets commit.gpgsign so every commit is signed.
+	Sign bool `mapstructure:"sign"`
+	// SigningFormat sets gpg.format: openpgp, ssh, or x509.
+	SigningFormat string `mapstructure:"signing_format"`
+}
+
+// DirenvConfig writes a direnv .envrc into new worktrees.
+type DirenvConfig struct {
+	// Envrc is a template for the .envrc, using the action template
+	// variables. Empty disables direnv integration.
+	Envrc string `mapstructure:"envrc"`
+	// Allow runs `direnv allow` on the .envrc written from Envrc. An existing
+	// .envrc is never allowed.
+	Allow bool `mapstructure:"allow"`
+}
+
+// EnvVar is an environment variable set for commands run in a worktree.
+type EnvVar struct {
+	Name string `mapstructure:"name"`
+	// Value is a template using the action template variables.
+	Value string `mapstructure:"value"`
+}
+
+// SecretBackend reads env values referencing a secret, <scheme>://<path>,
+// when a command runs.
+type SecretBackend struct {
+	Scheme string `mapstructure:"scheme"`
+	// Command is a shell command printing the secret, run with the reference
+	// as $1 and the part after <scheme>:// as $2.
+	Command string `mapstructure:"command"`
+}
+
+// EnvFileConfig writes a rendered environment file into new worktrees.
+type EnvFileConfig struct {
+	// Path is relative to the worktree. Defaults to DefaultEnvFile.
+	Path string `mapstructure:"path"`
+	// Template is the file content, using the action template variables.
+	// Empty disables the env file.
+	Template string `mapstructure:"template"`
+}
+
+// PortsConfig sets how port blocks are assigned to worktrees.
+type PortsConfig struct {
+	// Start is the first port of the first block.
+	Start int `mapstructure:"start"`
+	// BlockSize is how many ports each worktree gets.
+	BlockSize int `mapstructure:"block_size"`
+}
+
+// FetchConfig controls fetching from the remote.
+type FetchConfig struct {
+	// Retries is how many times a fetch failing with a network error is
+	// retried, with backoff, before giving up. 0 disables retrying.
+	Retries int `mapstructure:"retries"`
+	// Prune removes remote-tracking refs deleted on the remote that match
+	// the fetched refspecs.
+	Prune bool `mapstructure:"prune"`
+	// Tags fetches all tags from origin.
+	Tags bool `mapstructure:"tags"`
+	// Refspecs are fetched from origin along with the PR's ref.
+	Refspecs []string `mapstructure:"refspecs"`
+	// Remotes are fetched, with their configured refspecs, after origin.
+	Remotes []string `mapstructure:"remotes"`
+}
+
+// CompletionConfig controls shell completion.
+type CompletionConfig struct {
+	// CacheTTL is how many seconds completions of worktrees and pull
+	// requests are cached. 0 disables the cache.
+	CacheTTL int `mapstructure:"cache_ttl"`
+}
+
+// Provider types.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Prompt styles.
+const (
+	// PromptDefault uses interactive arrow-key prompts.
+	PromptDefault = "default"
+	// PromptPlain uses numbered, line-based prompts that work with screen
+	// readers and terminals without raw mode.
+	PromptPlain = "plain"
+)
+
+// Ways non-ASCII characters in worktree and branch names are handled.
+const (
+	// NamesPreserve keeps non-ASCII letters and emoji as typed.
+	NamesPreserve = "preserve"
+	// NamesTransliterate spells names with ASCII characters only.
+	NamesTransliterate = "transliterate"
+)
+
+// ProviderHost selects the provider used for a host, such as a self-managed
+// GitLab instance.
+type ProviderHost struct {
+	Host string `mapstructure:"host"`
+	Type string `mapstructure:"type"`
 }
 
 // Config holds the application configuration.
 type Config struct {
-	WorktreeBase string   `mapstructure:"worktree_dir"`
-	Actions      []Action `mapstructure:"actions"`
+	WorktreeBase string      `mapstructure:"worktree_dir"`
+	Actions      []Action    `mapstructure:"actions"`
+	Issue        IssueConfig `mapstructure:"issue"`
+	// MaxParallelActions limits how many action runs execute at once when an
+	// action is run across several worktrees.
+	MaxParallelActions int `mapstructure:"max_parallel_actions"`
+	// StartComment is a template posted as a comment on the linked issue or PR
+	// when its worktree is created. Empty disables commenting.
+	StartComment string `mapstructure:"start_comment"`
+	// GitOnly creates PR and issue worktrees without querying GitHub.
+	GitOnly bool `mapstructure:"git_only"`
+	// Prompt is the prompt style: PromptDefault or PromptPlain.
+	Prompt string `mapstructure:"prompt"`
+	// UnicodeNames is how non-ASCII characters in names given to gh wt add
+	// are handled: NamesPreserve or NamesTransliterate.
+	UnicodeNames string `mapstructure:"unicode_names"`
+	// LockTimeout is how many seconds add and rm wait for another gh-wt
+	// process working on the same repository. 0 fails immediately.
+	LockTimeout int `mapstructure:"lock_timeout"`
+	// URLParsers turn URLs that are not GitHub PRs or issues into local
+	// worktree names. The first matching parser is used.
+	URLParsers []URLParser `mapstructure:"url_parsers"`
+	// Providers maps hosts to providers. github.com and gitlab.com are known;
+	// other hosts default to GitHub.
+	Providers []ProviderHost `mapstructure:"providers"`
+	// CodeCommand is the VS Code compatible editor run by gh wt code, e.g.
+	// "cursor". Empty detects code, cursor, or codium on PATH.
+	CodeCommand string `mapstructure:"code_command"`
+	// Env is set for actions and commands run in a worktree, in addition to
+	// COMPOSE_PROJECT_NAME.
+	Env []EnvVar `mapstructure:"env"`
+	// SecretBackends resolve env values such as op://vault/item/field, in
+	// addition to and replacing the built-in op, pass, and env backends.
+	SecretBackends []SecretBackend `mapstructure:"secret_backends"`
+	// EnvFile writes a rendered environment file into new worktrees.
+	EnvFile EnvFileConfig `mapstructure:"env_file"`
+	// Ports assigns each worktree a block of ports, exposed as GH_WT_PORT.
+	Ports PortsConfig `mapstructure:"ports"`
+	// Direnv writes a .envrc into new worktrees.
+	Direnv DirenvConfig `mapstructure:"direnv"`
+	// Completion controls shell completion.
+	Completion CompletionConfig `mapstructure:"completion"`
+	// Fetch controls fetching from the remote.
+	Fetch FetchConfig `mapstructure:"fetch"`
+	// Identities set the git identity of new worktrees. The first matching
+	// rule is used.
+	Identities []Identity `mapstructure:"identities"`
 }
 
 // Default values.
 const (
 	DefaultWorktreeBase = "~/github/worktree"
+	DefaultEnvFile      = ".env"
 	ConfigName          = "config"
 	ConfigType          = "yaml"
 )
@@ -37,6 +249,7 @@ var v *viper.Viper
 // If no config file exists, it creates one with default values.
 func Load() (*viper.Viper, error) {
 	v = viper.New()
+	repoConfig = ""
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -52,10 +265,26 @@ func Load() (*viper.Viper, error) {
 	v.SetConfigType(ConfigType)
 
 	v.AutomaticEnv()
-	v.SetEnvPrefix("GH_WT")
+	v.SetEnvPrefix(EnvPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	v.SetDefault("worktree_dir", filepath.Join(home, "github", "worktree"))
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	// Viper only reads the environment for keys it knows; bind every scalar
+	// key so each documented GH_WT_* variable works without a config entry.
+	keys, err := Keys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.Env != "" {
+			if err := v.BindEnv(k.Name, k.Env); err != nil {
+				return nil, fmt.Errorf("failed to bind %s: %w", k.Env, err)
+			}
+		}
+	}
 
 	if err := v.ReadInConfig(); err != nil {
 		var notFound viper.ConfigFileNotFoundError
@@ -76,6 +305,15 @@ func Load() (*viper.Viper, error) {
 				return nil, fmt.Errorf("failed to write config file: %w", err)
 			}
 		}
+		v.SetConfigFile(configFile)
+	}
+
+	includes, err := readIncludes(filepath.Join(configDir, "includes"))
+	if err != nil {
+		return nil, err
+	}
+	if err := applyIncludes(v, includes); err != nil {
+		return nil, err
 	}
 
 	return v, nil
@@ -142,6 +380,31 @@ func Set(key string, value any) {
 	}
 }
 
+// BindFlag binds a command-line flag to a config key so that an explicitly set
+// flag takes precedence over the environment and the config file.
+func BindFlag(key string, flag *pflag.Flag) error {
+	if v == nil {
+		return errors.New("config not initialized")
+	}
+	if flag == nil {
+		return fmt.Errorf("cannot bind nil flag to %q", key)
+	}
+	return v.BindPFlag(key, flag)
+}
+
+// StateDir returns the directory used for gh-wt state such as worktree metadata.
+// It honors $XDG_STATE_HOME and defaults to ~/.local/state/gh-wt.
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "gh-wt"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "gh-wt"), nil
+}
+
 // ConfigFileUsed returns the path of the loaded config file (or "" if none).
 func ConfigFileUsed() string {
 	if v != nil {
@@ -149,3 +412,228 @@ func ConfigFileUsed() string {
 	}
 	return ""
 }
+
+// CheckTemplates parses the templates of the config file and returns an
+// error listing those that do not parse, so a broken template is reported
+// before a command starts rather than midway through it.
+func CheckTemplates() error {
+	path := ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	problems, err := ValidateTemplates(data)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = p
+	}
+	return fmt.Errorf("invalid template(s) in %s:\n%w", path, errors.Join(errs...))
+}
+
+// fileViper returns a Viper instance holding only the settings of the config
+// file, without defaults or environment overrides.
+func fileViper() (*viper.Viper, string, error) {
+	path := ConfigFileUsed()
+	if path == "" {
+		return nil, "", errors.New("config not initialized; call Load first")
+	}
+	fv := viper.New()
+	fv.SetConfigFile(path)
+	if err := fv.ReadInConfig(); err != nil {
+		return nil, "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	return fv, path, nil
+}
+
+// Export writes the settings of the config file, including actions, to w as a
+// single YAML document.
+func Export(w io.Writer) error {
+	fv, _, err := fileViper()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(fv.AllSettings())
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Import reads a YAML document produced by Export and writes it to the config
+// file, replacing its contents or, with merge, merging over them. The previous
+// file is kept next to it with a .bak suffix. It returns the backup path.
+func Import(r io.Reader, merge bool) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config document: %w", err)
+	}
+
+	problems, err := Validate(data)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		errs := make([]error, len(problems))
+		for i, p := range problems {
+			errs[i] = p
+		}
+		return "", fmt.Errorf("invalid config document:\n%w", errors.Join(errs...))
+	}
+
+	incoming := viper.New()
+	incoming.SetConfigType(ConfigType)
+	if err := incoming.ReadConfig(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("failed to parse config document: %w", err)
+	}
+
+	fv, path, err := fileViper()
+	if err != nil {
+		return "", err
+	}
+	if !merge {
+		fv = viper.New()
+	}
+	if err := fv.MergeConfigMap(incoming.AllSettings()); err != nil {
+		return "", fmt.Errorf("failed to merge config: %w", err)
+	}
+
+	backup := path + ".bak"
+	if current, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(backup, current, 0o600); err != nil {
+			return "", fmt.Errorf("failed to back up config: %w", err)
+		}
+	}
+	if err := fv.WriteConfigAs(path); err != nil {
+		return "", fmt.Errorf("failed to write config to %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// configFile returns the path of the gh-wt config file.
+func configFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "gh-wt", "config.yaml"), nil
+}
+
+// LegacyConfigFile returns the path of the config file used by gh-worktree,
+// the previous name of this extension.
+func LegacyConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "gh-worktree", "config.yaml"), nil
+}
+
+// legacyDeclinedFile marks, in the state directory, that migrating the
+// gh-worktree config was declined.
+const legacyDeclinedFile = "legacy_migration_declined"
+
+// PendingLegacyMigration returns the legacy config file when it exists, no
+// gh-wt config file has been created yet, i.e. on the first run after
+// switching from gh-worktree, and migrating it was not declined.
+func PendingLegacyMigration() (string, bool) {
+	current, err := configFile()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(current); !errors.Is(err, os.ErrNotExist) {
+		return "", false
+	}
+	if marker, err := legacyDeclinedPath(); err != nil {
+		return "", false
+	} else if _, err := os.Stat(marker); err == nil {
+		return "", false
+	}
+	legacy, err := LegacyConfigFile()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return "", false
+	}
+	return legacy, true
+}
+
+// DeclineLegacyMigration records that migrating the gh-worktree config was
+// declined, so that PendingLegacyMigration stops offering it.
+func DeclineLegacyMigration() error {
+	marker, err := legacyDeclinedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0o755); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	return os.WriteFile(marker, nil, 0o644)
+}
+
+func legacyDeclinedPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, legacyDeclinedFile), nil
+}
+
+// MigrateLegacy merges the settings of a gh-worktree config file into the
+// gh-wt config file, creating it if needed. Keys unknown to gh-wt are dropped
+// and returned so they can be reported. Settings already in the gh-wt config
+// are overwritten by the legacy ones.
+func MigrateLegacy(legacyPath string) ([]string, error) {
+	legacy := viper.New()
+	legacy.SetConfigFile(legacyPath)
+	legacy.SetConfigType(ConfigType)
+	if err := legacy.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", legacyPath, err)
+	}
+
+	var root schema
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid embedded schema: %w", err)
+	}
+	settings := legacy.AllSettings()
+	var dropped []string
+	for key := range settings {
+		if _, ok := root.Properties[key]; !ok {
+			dropped = append(dropped, key)
+			delete(settings, key)
+		}
+	}
+	sort.Strings(dropped)
+
+	path, err := configFile()
+	if err != nil {
+		return nil, err
+	}
+	target := viper.New()
+	target.SetConfigFile(path)
+	target.SetConfigType(ConfigType)
+	if err := target.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := target.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create config directory: %w", err)
+	}
+	if err := target.WriteConfigAs(path); err != nil {
+		return nil, fmt.Errorf("failed to write config to %s: %w", path, err)
+	}
+	return dropped, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	legacy := filepath.Join(home, ".config", "gh-worktree", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0o755))
+	require.NoError(t, os.WriteFile(legacy, []byte(`worktree_dir: ~/wt
+editor: vim
+actions:
+  - name: tmux
+    cmds:
+      - tmux new -s "{{.BranchName}}"
+`), 0o600))
+
+	path, ok := PendingLegacyMigration()
+	require.True(t, ok)
+	assert.Equal(t, legacy, path)
+
+	dropped, err := MigrateLegacy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"editor"}, dropped)
+
+	_, ok = PendingLegacyMigration()
+	assert.False(t, ok, "migration is only offered before a gh-wt config exists")
+
+	_, err = Load()
+	require.NoError(t, err)
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "wt"), cfg.WorktreeBase)
+	require.Len(t, cfg.Actions, 1)
+	assert.Equal(t, "tmux", cfg.Actions[0].Name)
+	assert.Equal(t, []string{`tmux new -s "{{.BranchName}}"`}, cfg.Actions[0].Cmds)
+}
+
+func TestDeclineLegacyMigration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	legacy := filepath.Join(home, ".config", "gh-worktree", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0o755))
+	require.NoError(t, os.WriteFile(legacy, []byte("worktree_dir: ~/wt\n"), 0o600))
+
+	_, ok := PendingLegacyMigration()
+	require.True(t, ok)
+
+	require.NoError(t, DeclineLegacyMigration())
+	_, ok = PendingLegacyMigration()
+	assert.False(t, ok, "a declined migration is not offered again")
+}
+
+func TestKeys(t *testing.T) {
+	keys, err := Keys()
+	require.NoError(t, err)
+
+	byName := make(map[string]Key, len(keys))
+	for _, k := range keys {
+		byName[k.Name] = k
+	}
+
+	assert.Equal(t, Key{
+		Name:        "ports.start",
+		Type:        "integer",
+		Description: byName["ports.start"].Description,
+		Default:     4000,
+		Env:         "GH_WT_PORTS_START",
+	}, byName["ports.start"])
+	assert.Equal(t, DefaultWorktreeBase, byName["worktree_dir"].Default)
+	assert.Empty(t, byName["actions"].Env, "lists cannot be set from the environment")
+	assert.NotContains(t, byName, "issue", "objects are flattened into their keys")
+
+	// Every default registered with Viper is a documented key.
+	for key := range defaults {
+		assert.Contains(t, byName, key)
+	}
+}
+
+func TestSaveActions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "gh-wt", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("worktree_dir: ~/wt\n"), 0o600))
+	_, err := Load()
+	require.NoError(t, err)
+
+	err = SaveActions([]Action{{Name: "test", Cmds: []string{"make test {{.Nope"}}})
+	require.ErrorContains(t, err, `actions[0].cmds[0] (action "test"): invalid template`)
+
+	require.NoError(t, SaveActions([]Action{{Name: "test", Cmds: []string{"make test"}, ReviewContext: true}}))
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, []Action{{Name: "test", Cmds: []string{"make test"}, ReviewContext: true}}, cfg.Actions)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "review_context: true")
+	assert.Contains(t, string(data), "worktree_dir: ~/wt")
+	assert.NotContains(t, string(data), "max_parallel_actions", "defaults are not written")
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// sourcesFile records where the includes pulled with gh wt config pull came
+// from. It lives in the includes directory.
+const sourcesFile = "sources.json"
+
+// IncludeSource records the GitHub repository a shared config in the includes
+// directory was pulled from.
+type IncludeSource struct {
+	// Repo is "owner/repo".
+	Repo string `json:"repo"`
+	// Ref is the branch, tag, or commit the include follows. Empty follows
+	// the default branch.
+	Ref string `json:"ref,omitempty"`
+	// Path is the file in the repository holding the shared config.
+	Path string `json:"path"`
+	// Commit is the commit the include was pulled from.
+	Commit   string    `json:"commit"`
+	PulledAt time.Time `json:"pulled_at"`
+}
+
+// File returns the name of the include pulled from s.Repo. GitHub owners
+// cannot contain underscores, so the name is unambiguous.
+func (s IncludeSource) File() string {
+	return strings.Replace(s.Repo, "/", "_", 1) + ".yaml"
+}
+
+// inheritedAction is an action merged in from an include or the repository
+// config, with the file it came from.
+type inheritedAction struct {
+	Action
+	file string
+}
+
+// inherited are the actions merged in from includes and the repository
+// config, as loaded, so that SaveActions does not copy them into the config
+// file.
+var inherited []inheritedAction
+
+// layer is the settings of a config file merged with the config file.
+type layer struct {
+	file     string
+	settings map[string]any
+}
+
+// IncludesDir returns the directory of shared configs merged under the config
+// file, ~/.config/gh-wt/includes.
+func IncludesDir() (string, error) {
+	path, err := configFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "includes"), nil
+}
+
+// readIncludes returns the settings of every YAML file in dir, in file name
+// order. A missing directory has no includes.
+func readIncludes(dir string) ([]layer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	includes := make([]layer, 0, len(files))
+	for _, file := range files {
+		iv := viper.New()
+		iv.SetConfigFile(file)
+		if err := iv.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read include %s: %w", file, err)
+		}
+		includes = append(includes, layer{file: file, settings: iv.AllSettings()})
+	}
+	return includes, nil
+}
+
+// applyIncludes merges includes under the settings of v, the config file and
+// the environment: their settings replace the built-in defaults, and their
+// actions are added to those of the config file unless it has an action of
+// the same name. Of several includes, later ones win.
+func applyIncludes(v *viper.Viper, includes []layer) error {
+	inherited = nil
+	var actions []any
+	for _, inc := range includes {
+		for key, value := range flatten("", inc.settings) {
+			if key != "actions" {
+				v.SetDefault(key, value)
+			}
+		}
+		list, _ := inc.settings["actions"].([]any)
+		if err := inherit(inc.file, list); err != nil {
+			return err
+		}
+		actions = overlayActions(actions, list)
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+
+	own, _ := v.Get("actions").([]any)
+	merged := append([]any{}, own...)
+	for _, a := range actions {
+		if !hasAction(own, actionName(a)) {
+			merged = append(merged, a)
+		}
+	}
+	v.Set("actions", merged)
+	return nil
+}
+
+// inherit records the actions of file as inherited. They are decoded the way
+// Get decodes the config, so that they compare equal to the ones SaveActions
+// is given back.
+func inherit(file string, actions []any) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	iv := viper.New()
+	iv.Set("actions", actions)
+	var decoded []Action
+	if err := iv.UnmarshalKey("actions", &decoded); err != nil {
+		return fmt.Errorf("invalid actions in %s: %w", file, err)
+	}
+	for _, a := range decoded {
+		inherited = append(inherited, inheritedAction{Action: a, file: file})
+	}
+	return nil
+}
+
+// overlayActions returns under with the actions of over replacing those of
+// the same name, followed by the other actions of over.
+func overlayActions(under, over []any) []any {
+	merged := append([]any{}, under...)
+	for _, a := range over {
+		name := actionName(a)
+		replaced := false
+		for i, b := range merged {
+			if actionName(b) == name {
+				merged[i], replaced = a, true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, a)
+		}
+	}
+	return merged
+}
+
+// flatten returns the leaves of settings as dotted keys. Lists are leaves.
+func flatten(prefix string, settings map[string]any) map[string]any {
+	leaves := map[string]any{}
+	for key, value := range settings {
+		if m, ok := value.(map[string]any); ok {
+			for k, v := range flatten(prefix+key+".", m) {
+				leaves[k] = v
+			}
+			continue
+		}
+		leaves[prefix+key] = value
+	}
+	return leaves
+}
+
+func actionName(a any) string {
+	m, _ := a.(map[string]any)
+	name, _ := m["name"].(string)
+	return name
+}
+
+func hasAction(actions []any, name string) bool {
+	for _, a := range actions {
+		if actionName(a) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionSource returns the include or repository config a comes from
+// unchanged, or "" when it is defined in the config file.
+func ActionSource(a Action) string {
+	// Later layers win, so look at them first.
+	for i := len(inherited) - 1; i >= 0; i-- {
+		if reflect.DeepEqual(a, inherited[i].Action) {
+			return inherited[i].file
+		}
+	}
+	return ""
+}
+
+// IncludeSources returns the includes pulled from GitHub repositories.
+func IncludeSources() ([]IncludeSource, error) {
+	dir, err := IncludesDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sourcesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read include sources: %w", err)
+	}
+	var sources []IncludeSource
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse include sources: %w", err)
+	}
+	return sources, nil
+}
+
+// SaveInclude validates data, a shared config pulled from src, and writes it
+// to the includes directory, replacing an earlier pull of the same repo.
+func SaveInclude(src IncludeSource, data []byte) error {
+	problems, err := Validate(data)
+	if err != nil {
+		return fmt.Errorf("%s/%s: %w", src.Repo, src.Path, err)
+	}
+	if len(problems) > 0 {
+		errs := make([]error, len(problems))
+		for i, p := range problems {
+			errs[i] = p
+		}
+		return fmt.Errorf("invalid shared config %s/%s:\n%w", src.Repo, src.Path, errors.Join(errs...))
+	}
+
+	dir, err := IncludesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create includes directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, src.File()), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write include: %w", err)
+	}
+
+	sources, err := IncludeSources()
+	if err != nil {
+		return err
+	}
+	return writeSources(dir, append(withoutSource(sources, src.Repo), src))
+}
+
+// RemoveInclude deletes the include pulled from repo. It reports whether there
+// was one.
+func RemoveInclude(repo string) (bool, error) {
+	sources, err := IncludeSources()
+	if err != nil {
+		return false, err
+	}
+	var removed *IncludeSource
+	for i := range sources {
+		if strings.EqualFold(sources[i].Repo, repo) {
+			removed = &sources[i]
+		}
+	}
+	if removed == nil {
+		return false, nil
+	}
+	dir, err := IncludesDir()
+	if err != nil {
+		return false, err
+	}
+	rest := withoutSource(sources, repo)
+	err = os.Remove(filepath.Join(dir, removed.File()))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to remove include: %w", err)
+	}
+	return true, writeSources(dir, rest)
+}
+
+func withoutSource(sources []IncludeSource, repo string) []IncludeSource {
+	rest := make([]IncludeSource, 0, len(sources))
+	for _, s := range sources {
+		if !strings.EqualFold(s.Repo, repo) {
+			rest = append(rest, s)
+		}
+	}
+	return rest
+}
+
+func writeSources(dir string, sources []IncludeSource) error {
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Repo < sources[j].Repo })
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode include sources: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sourcesFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write include sources: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".config", "gh-wt")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "includes"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`lock_timeout: 5
+actions:
+  - name: test
+    cmds: [make test]
+`), 0o600))
+
+	src := IncludeSource{Repo: "acme/gh-wt-config", Path: "gh-wt.yaml", Commit: "abc"}
+	require.NoError(t, SaveInclude(src, []byte(`lock_timeout: 30
+fetch:
+  retries: 7
+actions:
+  - name: test
+    cmds: [npm test]
+  - name: lint
+    cmds: [golangci-lint run]
+`)))
+	require.Error(t, SaveInclude(src, []byte("actions: 5\n")), "invalid shared configs are rejected")
+
+	sources, err := IncludeSources()
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "acme_gh-wt-config.yaml", sources[0].File())
+
+	_, err = Load()
+	require.NoError(t, err)
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.LockTimeout, "the config file wins")
+	assert.Equal(t, 7, cfg.Fetch.Retries, "includes replace defaults")
+	require.Len(t, cfg.Actions, 2)
+	assert.Equal(t, []string{"make test"}, cfg.Actions[0].Cmds, "own actions win")
+	assert.Equal(t, "lint", cfg.Actions[1].Name)
+	assert.Equal(t, filepath.Join(dir, "includes", "acme_gh-wt-config.yaml"), ActionSource(cfg.Actions[1]))
+
+	// Saving actions leaves the included ones to the include.
+	require.NoError(t, SaveActions(append(cfg.Actions, Action{Name: "fmt", Cmds: []string{"go fmt ./..."}})))
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "lint")
+	assert.Contains(t, string(data), "fmt")
+
+	removed, err := RemoveInclude("ACME/gh-wt-config")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	_, err = Load()
+	require.NoError(t, err)
+	cfg, err = Get()
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Fetch.Retries)
+	require.Len(t, cfg.Actions, 2)
+	assert.Equal(t, []string{"test", "fmt"}, []string{cfg.Actions[0].Name, cfg.Actions[1].Name})
+}
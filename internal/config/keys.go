@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables overriding config keys.
+const EnvPrefix = "GH_WT"
+
+// defaults are the default values registered with Viper, by key.
+// worktree_dir is registered separately since it depends on the home directory.
+var defaults = map[string]any{
+	"max_parallel_actions": 1,
+	"direnv.allow":         false,
+	"env_file.path":        DefaultEnvFile,
+	"ports.start":          4000,
+	"ports.block_size":     10,
+	"completion.cache_ttl": 10,
+	"prompt":               PromptDefault,
+	"unicode_names":        NamesPreserve,
+	"lock_timeout":         60,
+	"fetch.retries":        3,
+	"issue.project.field":  "Status",
+	"issue.project.value":  "In Progress",
+}
+
+// Key describes a config key.
+type Key struct {
+	// Name is the dotted key, e.g. "ports.start".
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Default is nil when the key has no default.
+	Default any `json:"default,omitempty"`
+	// Env is the environment variable overriding the key. Lists and objects
+	// cannot be set from the environment and have none.
+	Env string `json:"env,omitempty"`
+}
+
+// Keys returns every config key in Schema, sorted by name, with its default
+// and environment variable.
+func Keys() ([]Key, error) {
+	var root schema
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid embedded schema: %w", err)
+	}
+	var keys []Key
+	collectKeys(&root, "", &keys)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, nil
+}
+
+// collectKeys appends the leaf keys below s, whose names start with prefix.
+func collectKeys(s *schema, prefix string, keys *[]Key) {
+	for name, prop := range s.Properties {
+		name = prefix + name
+		if prop.Type == "object" && prop.Properties != nil {
+			collectKeys(prop, name+".", keys)
+			continue
+		}
+		key := Key{Name: name, Type: prop.Type, Description: prop.Description, Default: defaults[name]}
+		if name == "worktree_dir" {
+			key.Default = DefaultWorktreeBase
+		}
+		switch prop.Type {
+		case "string", "boolean", "integer", "number":
+			key.Env = EnvVarName(name)
+		}
+		*keys = append(*keys, key)
+	}
+}
+
+// EnvVarName returns the environment variable overriding the config key.
+func EnvVarName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// EnvDoc describes an environment variable gh wt sets for the commands it runs.
+type EnvDoc struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// WorktreeEnv lists the environment variables set for actions and commands run
+// in a worktree.
+var WorktreeEnv = []EnvDoc{
+	{"COMPOSE_PROJECT_NAME", "Docker Compose project name unique to the worktree (<repo>-<worktree>)"},
+	{"GH_WT_PORT", "First port of the port block assigned to the worktree"},
+	{"GH_WT_SHELL", "Set to 1 in shells started by gh wt shell"},
+	{"GH_WT_NAME", "Worktree directory name (gh wt shell)"},
+	{"GH_WT_PATH", "Worktree path (gh wt shell)"},
+	{"GH_WT_BRANCH", "Branch name (gh wt shell and exec-plugin actions)"},
+	{"GH_WT_TYPE", "pr, issue, or local (gh wt shell and exec-plugin actions)"},
+	{"GH_WT_OWNER", "Repository owner (gh wt shell and exec-plugin actions)"},
+	{"GH_WT_REPO", "Repository name (gh wt shell and exec-plugin actions)"},
+	{"GH_WT_NUMBER", "PR or issue number, when there is one (gh wt shell and exec-plugin actions)"},
+	{"GH_WT_ACTION", "Name of the running exec-plugin action"},
+	{"GH_WT_WORKTREE_PATH", "Worktree path (exec-plugin actions)"},
+	{"GH_WT_WORKTREE_NAME", "Worktree directory name (exec-plugin actions)"},
+	{"GH_WT_ROOT_DIR", "Root of the repository the worktree belongs to (exec-plugin actions)"},
+	{"GH_WT_CLI_ARGS", "Arguments passed after -- (exec-plugin actions)"},
+	{"GH_WT_REVIEW_FILE", "PR review context file (actions with review_context)"},
+	{"GH_WT_DEMO", "Sandbox directory, in shells started by gh wt demo"},
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// RepoConfigName is the repository config file, committed at the root of a
+// repository to share its actions with everyone working on it.
+const RepoConfigName = ".gh-wt.yaml"
+
+// trustFile records your answer for each repository config, by path, with
+// the hash of the content you answered for. It lives in the state directory.
+const trustFile = "trusted_repo_configs.json"
+
+// repoKeys are the keys a repository config can set: its actions, and
+// settings that only tune how gh wt works. Anything that runs commands,
+// talks to other hosts, acts in your name, or changes your refs, such as
+// code_command, direnv, identities, providers, env, start_comment, or fetch
+// (whose refspecs can force-update local branches), is up to each user.
+var repoKeys = []string{"actions", "lock_timeout", "max_parallel_actions", "ports", "unicode_names"}
+
+// repoConfig is the repository config merged by LoadRepo, if any.
+var repoConfig string
+
+// repoTrust is the answer recorded for a repository config.
+type repoTrust struct {
+	SHA256  string `json:"sha256"`
+	Trusted bool   `json:"trusted"`
+}
+
+// UntrustedRepoConfigError is returned by LoadRepo for a repository config
+// you were never asked about, or that changed since you were.
+type UntrustedRepoConfigError struct {
+	Path string
+	// Data is the content of the file, to show before trusting it.
+	Data []byte
+	// Changed is true when you answered for an earlier version of the file.
+	Changed bool
+}
+
+func (e *UntrustedRepoConfigError) Error() string {
+	if e.Changed {
+		return fmt.Sprintf("repository config %s changed since you reviewed it", e.Path)
+	}
+	return fmt.Sprintf("repository config %s is not trusted", e.Path)
+}
+
+// LoadRepo merges the repository config in dir, if there is one, over the
+// config file. Its settings win over those of the config file, and its
+// actions are added unless you already have an action of the same name; the
+// environment and flags still win over both. The config file itself is not
+// changed.
+//
+// A repository config is only merged once TrustRepoConfig trusted its
+// content. LoadRepo returns an *UntrustedRepoConfigError for content you were
+// not asked about, and ignores content you declined.
+//
+// Keys a repository config cannot set, and keys this version of gh wt does
+// not know, are left out rather than failing every command in the
+// repository; they are returned so they can be reported.
+func LoadRepo(dir string) (ignored []ValidationError, err error) {
+	if v == nil {
+		return nil, errors.New("config not initialized; call Load first")
+	}
+	path := filepath.Join(dir, RepoConfigName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	allowed, ignored, err := allowedRepoKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	problems, err := Validate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var errs []error
+	for _, p := range problems {
+		top, _, _ := strings.Cut(p.Field, ".")
+		top, _, _ = strings.Cut(top, "[")
+		if !slices.Contains(repoKeys, top) {
+			// Reported along with the key it belongs to.
+			continue
+		}
+		if p.Message == "unknown key" {
+			ignored = append(ignored, p)
+			continue
+		}
+		errs = append(errs, p)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid repository config %s:\n%w", path, errors.Join(errs...))
+	}
+
+	rv := viper.New()
+	rv.SetConfigType(ConfigType)
+	if err := rv.ReadConfig(bytes.NewReader(allowed)); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	settings := rv.AllSettings()
+
+	trusted, err := trustedRepoConfigs()
+	if err != nil {
+		return nil, err
+	}
+	answer, ok := trusted[path]
+	if !ok || answer.SHA256 != hashRepoConfig(data) {
+		return nil, &UntrustedRepoConfigError{Path: path, Data: data, Changed: ok}
+	}
+	if !answer.Trusted {
+		return nil, nil
+	}
+
+	actions, _ := settings["actions"].([]any)
+	delete(settings, "actions")
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", path, err)
+	}
+	current, _ := v.Get("actions").([]any)
+	var added []any
+	for _, a := range actions {
+		if !hasAction(current, actionName(a)) && !hasAction(added, actionName(a)) {
+			added = append(added, a)
+		}
+	}
+	if err := inherit(path, added); err != nil {
+		return nil, err
+	}
+	if len(added) > 0 {
+		v.Set("actions", append(append([]any{}, current...), added...))
+	}
+	repoConfig = path
+	return ignored, nil
+}
+
+// allowedRepoKeys returns the repository config data with only its top-level
+// keys in repoKeys, and the keys left out.
+func allowedRepoKeys(data []byte) ([]byte, []ValidationError, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+	root := doc.Content[0]
+	var kept []*yaml.Node
+	var ignored []ValidationError
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if slices.Contains(repoKeys, key.Value) {
+			kept = append(kept, key, root.Content[i+1])
+			continue
+		}
+		ignored = append(ignored, ValidationError{
+			Line:    key.Line,
+			Column:  key.Column,
+			Field:   key.Value,
+			Message: "can only be set in " + ConfigFileUsed(),
+		})
+	}
+	if len(ignored) == 0 {
+		return data, nil, nil
+	}
+	root.Content = kept
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return out, ignored, nil
+}
+
+// RepoConfigUsed returns the path of the repository config merged by
+// LoadRepo, or "" if none.
+func RepoConfigUsed() string {
+	return repoConfig
+}
+
+// TrustRepoConfig records whether data, the content of the repository config
+// at path, is trusted, so that LoadRepo merges or ignores it without asking
+// again until it changes.
+func TrustRepoConfig(path string, data []byte, trust bool) error {
+	trusted, err := trustedRepoConfigs()
+	if err != nil {
+		return err
+	}
+	trusted[path] = repoTrust{SHA256: hashRepoConfig(data), Trusted: trust}
+
+	file, err := trustFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	out, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trusted repository configs: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), trustFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write trusted repository configs: %w", err)
+	}
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write trusted repository configs: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write trusted repository configs: %w", err)
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// trustedRepoConfigs returns the answers recorded for repository configs, by
+// path.
+func trustedRepoConfigs() (map[string]repoTrust, error) {
+	file, err := trustFilePath()
+	if err != nil {
+		return nil, err
+	}
+	trusted := map[string]repoTrust{}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted repository configs: %w", err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return trusted, nil
+}
+
+func trustFilePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, trustFile), nil
+}
+
+func hashRepoConfig(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRepo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := filepath.Join(home, ".config", "gh-wt")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`lock_timeout: 5
+fetch:
+  retries: 1
+  prune: true
+actions:
+  - name: test
+    cmds: [make test]
+  - name: tmux
+    cmds: [tmux new]
+`), 0o600))
+
+	repo := t.TempDir()
+	_, err := LoadRepo(repo)
+	require.NoError(t, err, "a repository without a config is fine")
+
+	path := filepath.Join(repo, RepoConfigName)
+	data := []byte(`lock_timeout: 9
+ports:
+  block_size: 20
+actions:
+  - name: test
+    cmds: [go test ./...]
+  - name: build
+    cmds: [go build ./...]
+`)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err = Load()
+	require.NoError(t, err)
+	var untrusted *UntrustedRepoConfigError
+	_, err = LoadRepo(repo)
+	require.ErrorAs(t, err, &untrusted, "a new repository config must be trusted first")
+	assert.False(t, untrusted.Changed)
+	assert.Equal(t, data, untrusted.Data)
+	assert.Empty(t, RepoConfigUsed())
+
+	require.NoError(t, TrustRepoConfig(path, data, false))
+	_, err = LoadRepo(repo)
+	require.NoError(t, err, "a declined repository config is ignored")
+	assert.Empty(t, RepoConfigUsed())
+
+	require.NoError(t, TrustRepoConfig(path, data, true))
+	ignored, err := LoadRepo(repo)
+	require.NoError(t, err)
+	assert.Empty(t, ignored)
+	assert.Equal(t, filepath.Join(repo, RepoConfigName), RepoConfigUsed())
+
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, 9, cfg.LockTimeout, "the repository config wins")
+	assert.Equal(t, 20, cfg.Ports.BlockSize)
+	require.Len(t, cfg.Actions, 3)
+	assert.Equal(t, []string{"make test"}, cfg.Actions[0].Cmds, "the repository cannot replace your actions")
+	assert.Empty(t, ActionSource(cfg.Actions[0]))
+	assert.Equal(t, "tmux", cfg.Actions[1].Name)
+	assert.Equal(t, "build", cfg.Actions[2].Name)
+	assert.Equal(t, filepath.Join(repo, RepoConfigName), ActionSource(cfg.Actions[2]))
+
+	// Saving actions leaves the file untouched by the repository's.
+	require.NoError(t, SaveActions(cfg.Actions))
+	saved, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), "make test")
+	assert.NotContains(t, string(saved), "go build")
+
+	// A changed file must be trusted again.
+	changed := append(data, []byte("  - name: deploy\n    cmds: [./deploy.sh]\n")...)
+	require.NoError(t, os.WriteFile(path, changed, 0o600))
+	_, err = Load()
+	require.NoError(t, err)
+	_, err = LoadRepo(repo)
+	require.ErrorAs(t, err, &untrusted)
+	assert.True(t, untrusted.Changed)
+
+	// Keys a repository config cannot set, or that gh wt does not know, are
+	// ignored rather than failing.
+	data = []byte("lock_timeout: 7\nworktree_dir: /tmp/wt\ncode_command: evil\nfetch:\n  refspecs: ['+refs/heads/x:refs/heads/main']\nfuture_key: 1\nports:\n  start: 5000\n  future: 2\n")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, TrustRepoConfig(path, data, true))
+	_, err = Load()
+	require.NoError(t, err)
+	ignored, err = LoadRepo(repo)
+	require.NoError(t, err)
+	var fields []string
+	for _, key := range ignored {
+		fields = append(fields, key.Field)
+	}
+	assert.ElementsMatch(t, []string{"worktree_dir", "code_command", "fetch", "future_key", "ports.future"}, fields)
+	cfg, err = Get()
+	require.NoError(t, err)
+	assert.Equal(t, 7, cfg.LockTimeout)
+	assert.Equal(t, 5000, cfg.Ports.Start)
+	assert.NotEqual(t, "/tmp/wt", cfg.WorktreeBase)
+	assert.NotEqual(t, "evil", cfg.CodeCommand)
+	assert.Empty(t, cfg.Fetch.Refspecs)
+}
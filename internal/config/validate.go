@@ -0,0 +1,309 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Schema is the JSON schema describing the config file. It is published with
+// the documentation so editors can validate and complete config files.
+//
+//go:embed schema.json
+var Schema []byte
+
+// schema is the subset of JSON schema used by Schema.
+type schema struct {
+	Type                 string             `json:"type"`
+	Description          string             `json:"description"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Const                *string            `json:"const"`
+	Enum                 []string           `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	If                   *schema            `json:"if"`
+	Then                 *schema            `json:"then"`
+	Else                 *schema            `json:"else"`
+	// Format "go-template" requires a string that parses as a text/template.
+	Format string `json:"format"`
+}
+
+// ValidationError describes a value in a config document that does not match
+// the schema.
+type ValidationError struct {
+	Line   int
+	Column int
+	// Field is the path to the value, e.g. "actions[0].cmds[1]".
+	Field string
+	// Action is the name of the action the value belongs to, if any.
+	Action  string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Location(), e.Message)
+}
+
+// Location returns the field path of the value, followed by the name of its
+// action when it belongs to one.
+func (e ValidationError) Location() string {
+	if e.Action == "" {
+		return e.Field
+	}
+	return fmt.Sprintf("%s (action %q)", e.Field, e.Action)
+}
+
+// IsTemplate reports whether the error is a template syntax error.
+func (e ValidationError) IsTemplate() bool {
+	return strings.HasPrefix(e.Message, invalidTemplate)
+}
+
+// invalidTemplate prefixes the message of template syntax errors.
+const invalidTemplate = "invalid template: "
+
+// Validate checks a YAML config document against Schema. It returns an error
+// if the document cannot be parsed, and otherwise one ValidationError per
+// problem in document order.
+func Validate(data []byte) ([]ValidationError, error) {
+	var root schema
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid embedded schema: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var errs []ValidationError
+	validateNode(doc.Content[0], &root, "", &errs)
+	nameActions(doc.Content[0], errs)
+	return errs, nil
+}
+
+// ValidateTemplates checks the templates of a YAML config document, such as
+// action commands and naming templates, and returns one ValidationError per
+// template that does not parse.
+func ValidateTemplates(data []byte) ([]ValidationError, error) {
+	problems, err := Validate(data)
+	if err != nil {
+		return nil, err
+	}
+	var errs []ValidationError
+	for _, p := range problems {
+		if p.IsTemplate() {
+			errs = append(errs, p)
+		}
+	}
+	return errs, nil
+}
+
+// actionField matches the field path of a value inside an action.
+var actionField = regexp.MustCompile(`^actions\[(\d+)\]`)
+
+// nameActions sets the Action of each error inside an action of the root
+// node to that action's name.
+func nameActions(root *yaml.Node, errs []ValidationError) {
+	var actions *yaml.Node
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "actions" && root.Content[i+1].Kind == yaml.SequenceNode {
+				actions = root.Content[i+1]
+			}
+		}
+	}
+	if actions == nil {
+		return
+	}
+	for i := range errs {
+		m := actionField.FindStringSubmatch(errs[i].Field)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		if n >= len(actions.Content) || actions.Content[n].Kind != yaml.MappingNode {
+			continue
+		}
+		action := actions.Content[n]
+		for j := 0; j+1 < len(action.Content); j += 2 {
+			if action.Content[j].Value == "name" && action.Content[j+1].Kind == yaml.ScalarNode {
+				errs[i].Action = action.Content[j+1].Value
+			}
+		}
+	}
+}
+
+func validateNode(n *yaml.Node, s *schema, field string, errs *[]ValidationError) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	report := func(node *yaml.Node, format string, args ...any) {
+		*errs = append(*errs, ValidationError{
+			Line:    node.Line,
+			Column:  node.Column,
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// An empty document or top-level null is an empty config.
+	if field == "" && n.Tag == "!!null" {
+		return
+	}
+
+	if s.Type != "" && !hasType(n, s.Type) {
+		report(n, "expected %s, got %s", typeNames[s.Type], describe(n))
+		return
+	}
+	if s.Const != nil && (n.Kind != yaml.ScalarNode || n.Value != *s.Const) {
+		report(n, "must be %q", *s.Const)
+		return
+	}
+	if len(s.Enum) > 0 && (n.Kind != yaml.ScalarNode || !slices.Contains(s.Enum, n.Value)) {
+		report(n, "must be one of %s", strings.Join(s.Enum, ", "))
+		return
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			seen[key.Value] = true
+			prop, ok := s.Properties[key.Value]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, ValidationError{
+						Line:    key.Line,
+						Column:  key.Column,
+						Field:   join(field, key.Value),
+						Message: "unknown key",
+					})
+				}
+				continue
+			}
+			validateNode(value, prop, join(field, key.Value), errs)
+		}
+		for _, name := range s.Required {
+			if !seen[name] {
+				report(n, "missing required key %q", name)
+			}
+		}
+
+	case yaml.SequenceNode:
+		if s.MinItems != nil && len(n.Content) < *s.MinItems {
+			report(n, "must have at least %d item(s)", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range n.Content {
+				validateNode(item, s.Items, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!str":
+			if s.MinLength != nil && len(n.Value) < *s.MinLength {
+				report(n, "must not be empty")
+				return
+			}
+			if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(n.Value) {
+				report(n, "must match %s", s.Pattern)
+			}
+			if s.Format == "go-template" {
+				if _, err := template.New(field).Parse(n.Value); err != nil {
+					report(n, invalidTemplate+"%v", err)
+				}
+			}
+		case "!!int":
+			if s.Minimum != nil {
+				i, err := strconv.ParseInt(n.Value, 0, 64)
+				if err == nil && float64(i) < *s.Minimum {
+					report(n, "must be at least %v", *s.Minimum)
+				}
+			}
+		}
+	}
+
+	if s.If != nil {
+		var discard []ValidationError
+		validateNode(n, s.If, field, &discard)
+		if len(discard) == 0 && s.Then != nil {
+			validateNode(n, s.Then, field, errs)
+		} else if len(discard) > 0 && s.Else != nil {
+			validateNode(n, s.Else, field, errs)
+		}
+	}
+}
+
+// typeNames are the descriptions of schema types used in error messages.
+var typeNames = map[string]string{
+	"object":  "a mapping",
+	"array":   "a list",
+	"string":  "a string",
+	"integer": "an integer",
+	"boolean": "a boolean",
+}
+
+// hasType reports whether a node is of the given schema type.
+func hasType(n *yaml.Node, typ string) bool {
+	switch typ {
+	case "object":
+		return n.Kind == yaml.MappingNode
+	case "array":
+		return n.Kind == yaml.SequenceNode
+	case "string":
+		return n.Kind == yaml.ScalarNode && n.Tag == "!!str"
+	case "integer":
+		return n.Kind == yaml.ScalarNode && n.Tag == "!!int"
+	case "boolean":
+		return n.Kind == yaml.ScalarNode && n.Tag == "!!bool"
+	}
+	return true
+}
+
+// describe returns a short description of a node's type for error messages.
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch n.Tag {
+	case "!!null":
+		return "null"
+	case "!!str":
+		return fmt.Sprintf("string %q", n.Value)
+	case "!!int", "!!float":
+		return "number " + n.Value
+	case "!!bool":
+		return "boolean " + n.Value
+	}
+	return n.Value
+}
+
+func join(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
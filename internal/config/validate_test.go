@@ -0,0 +1,145 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []ValidationError
+	}{
+		{
+			name:  "empty document",
+			input: "",
+		},
+		{
+			name: "valid config",
+			input: `worktree_dir: ~/github/worktree
+max_parallel_actions: 2
+issue:
+  assign: true
+  project:
+    number: 5
+actions:
+  - name: tmux
+    dir: "{{.WorktreePath}}"
+    cmds:
+      - tmux new-session -d -s "{{.BranchName}}"
+`,
+		},
+		{
+			name: "unknown keys",
+			input: `worktree_dir: ~/wt
+worktre_dir: ~/typo
+issue:
+  asign: true
+`,
+			expected: []ValidationError{
+				{Line: 2, Column: 1, Field: "worktre_dir", Message: "unknown key"},
+				{Line: 4, Column: 3, Field: "issue.asign", Message: "unknown key"},
+			},
+		},
+		{
+			name: "wrong types",
+			input: `max_parallel_actions: many
+issue:
+  assign: "yes"
+actions: tmux
+`,
+			expected: []ValidationError{
+				{Line: 1, Column: 23, Field: "max_parallel_actions", Message: `expected an integer, got string "many"`},
+				{Line: 3, Column: 11, Field: "issue.assign", Message: `expected a boolean, got string "yes"`},
+				{Line: 4, Column: 10, Field: "actions", Message: `expected a list, got string "tmux"`},
+			},
+		},
+		{
+			name: "empty and missing action cmds",
+			input: `actions:
+  - name: a
+    cmds: []
+  - name: b
+`,
+			expected: []ValidationError{
+				{Line: 3, Column: 11, Field: "actions[0].cmds", Action: "a", Message: "must have at least 1 item(s)"},
+				{Line: 4, Column: 5, Field: "actions[1]", Action: "b", Message: `missing required key "cmds"`},
+			},
+		},
+		{
+			name: "exec-plugin actions need no cmds",
+			input: `actions:
+  - name: vscode
+    type: exec-plugin
+  - name: deploy
+    type: plugin
+`,
+			expected: []ValidationError{
+				{Line: 5, Column: 11, Field: "actions[1].type", Action: "deploy", Message: "must be one of shell, exec-plugin"},
+				{Line: 4, Column: 5, Field: "actions[1]", Action: "deploy", Message: `missing required key "cmds"`},
+			},
+		},
+		{
+			name: "bad template",
+			input: `actions:
+  - name: a
+    cmds:
+      - echo ok
+      - echo {{.BranchName
+`,
+			expected: []ValidationError{
+				{Line: 5, Column: 9, Field: "actions[0].cmds[1]", Action: "a", Message: `invalid template: template: actions[0].cmds[1]:1: unclosed action`},
+			},
+		},
+		{
+			name: "secret backend scheme",
+			input: `secret_backends:
+  - scheme: vault
+    command: vault kv get -field=value "$2"
+  - scheme: Vault
+    command: echo
+`,
+			expected: []ValidationError{
+				{Line: 4, Column: 13, Field: "secret_backends[1].scheme", Message: "must match ^[a-z][a-z0-9+.-]*$"},
+			},
+		},
+		{
+			name:  "minimum",
+			input: "max_parallel_actions: 0\n",
+			expected: []ValidationError{
+				{Line: 1, Column: 23, Field: "max_parallel_actions", Message: "must be at least 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, errs)
+		})
+	}
+}
+
+func TestValidateParseError(t *testing.T) {
+	_, err := Validate([]byte("actions: [\n"))
+	assert.Error(t, err)
+}
+
+func TestValidateTemplates(t *testing.T) {
+	errs, err := ValidateTemplates([]byte(`worktre_dir: /tmp
+start_comment: "Working on {{.Title"
+actions:
+  - name: build
+    cmds:
+      - make {{if .Number}}
+`))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "start_comment", errs[0].Field)
+	assert.Equal(t, "build", errs[1].Action)
+	assert.Equal(t, `line 6: actions[0].cmds[0] (action "build"): invalid template: template: actions[0].cmds[0]:1: unexpected EOF`, errs[1].Error())
+}
@@ -26,6 +26,8 @@ type RunCommandOptions struct {
 	Stdin     io.Reader
 	Stdout    io.Writer
 	Stderr    io.Writer
+	// Args are the positional parameters ($1..$n) of the command.
+	Args []string
 }
 
 // RunCommand runs a shell command with mvdan/sh.
@@ -65,6 +67,10 @@ func RunCommand(ctx context.Context, opts *RunCommandOptions) error {
 		}
 		params = append(params, "-o", opt)
 	}
+	if len(opts.Args) > 0 {
+		params = append(params, "--")
+		params = append(params, opts.Args...)
+	}
 
 	runner, err := interp.New(
 		interp.Params(params...),
@@ -1,41 +1,165 @@
 package git
 
 import (
-	"os/exec"
+	"context"
+	"errors"
+	"io"
+	"strconv"
 	"strings"
 )
 
 // BranchDelete deletes a branch.
 func BranchDelete(branch string, force bool) error {
+	return std.BranchDelete(context.Background(), branch, force)
+}
+
+// BranchDelete is BranchDelete run by c.
+func (c *Client) BranchDelete(ctx context.Context, branch string, force bool) error {
 	args := []string{"branch", "-d"}
 	if force {
 		args[1] = "-D"
 	}
 	args = append(args, branch)
-	return Command(args...)
+	return c.command(ctx, args...)
 }
 
 // BranchExists checks if a branch exists in the repository.
 func BranchExists(branch string) bool {
-	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
-	err := cmd.Run()
-	return err == nil
+	return std.BranchExists(context.Background(), branch)
+}
+
+// BranchExists is BranchExists run by c.
+func (c *Client) BranchExists(ctx context.Context, branch string) bool {
+	return c.silent(ctx, "show-ref", "--verify", "--quiet", "refs/heads/"+branch) == nil
+}
+
+// DefaultBranch returns the default branch of the origin remote, read from
+// refs/remotes/origin/HEAD, or "" when it is not known.
+func DefaultBranch() string {
+	return DefaultBranchAt("")
+}
+
+// DefaultBranchAt is DefaultBranch for the repository at dir.
+func DefaultBranchAt(dir string) string {
+	return std.DefaultBranchAt(context.Background(), dir)
+}
+
+// DefaultBranchAt is DefaultBranchAt run by c.
+func (c *Client) DefaultBranchAt(ctx context.Context, dir string) string {
+	out, err := c.output(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), "origin/")
+}
+
+// IsProtectedBranch reports whether branch is main, master, or the default
+// branch of the origin remote, which gh wt does not delete without --force.
+func IsProtectedBranch(branch string) bool {
+	return std.IsProtectedBranch(context.Background(), branch)
+}
+
+// IsProtectedBranch is IsProtectedBranch run by c.
+func (c *Client) IsProtectedBranch(ctx context.Context, branch string) bool {
+	if branch == "main" || branch == "master" {
+		return true
+	}
+	return branch != "" && branch == c.DefaultBranchAt(ctx, "")
+}
+
+// UnpushedCommits returns how many commits on branch are not on any remote,
+// i.e. would be lost if the branch were deleted.
+func UnpushedCommits(branch string) (int, error) {
+	out, err := CommandOutput("rev-list", "--count", "refs/heads/"+branch, "--not", "--remotes")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
 }
 
-// GetCurrentBranch returns the current branch name in the specified directory.
+// BranchMerged reports whether branch is merged the way `git branch -d`
+// checks it: into its upstream when it has one, otherwise into HEAD. Deleting
+// an unmerged branch needs -D.
+func BranchMerged(branch string) bool {
+	target := "HEAD"
+	if run("", io.Discard, io.Discard, "rev-parse", "--verify", "--quiet", branch+"@{upstream}") == nil {
+		target = branch + "@{upstream}"
+	}
+	return run("", io.Discard, io.Discard, "merge-base", "--is-ancestor", "refs/heads/"+branch, target) == nil
+}
+
+// GetCurrentBranch returns the current branch name in the specified
+// directory, or "" when HEAD is detached.
 func GetCurrentBranch(path string) (string, error) {
 	out, err := CommandOutputAt(path, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(out), nil
+	return branchName(out), nil
 }
 
-// GetCurrentBranchAtCwd returns the current branch name at current working directory.
+// GetCurrentBranchAtCwd returns the current branch name at current working
+// directory, or "" when HEAD is detached.
 func GetCurrentBranchAtCwd() (string, error) {
 	out, err := CommandOutput("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(out), nil
+	return branchName(out), nil
+}
+
+// branchName trims the output of rev-parse --abbrev-ref HEAD, which prints
+// HEAD itself when detached.
+func branchName(out string) string {
+	branch := strings.TrimSpace(out)
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// BranchUpstream returns the remote branch tracks and the branch's name on
+// it, or empty strings when branch does not track a remote branch, e.g. when
+// it tracks refs/pull/N/head.
+func BranchUpstream(branch string) (remote, name string) {
+	remoteOut, err := CommandOutput("config", "--get", "branch."+branch+".remote")
+	if err != nil {
+		return "", ""
+	}
+	mergeOut, err := CommandOutput("config", "--get", "branch."+branch+".merge")
+	if err != nil {
+		return "", ""
+	}
+	remote = strings.TrimSpace(remoteOut)
+	name, ok := strings.CutPrefix(strings.TrimSpace(mergeOut), "refs/heads/")
+	if !ok || remote == "" || remote == "." {
+		return "", ""
+	}
+	return remote, name
+}
+
+// DeleteRemoteBranch deletes branch name on remote. A branch that is already
+// gone from the remote, e.g. deleted by GitHub on merge, is not an error.
+func DeleteRemoteBranch(remote, name string) error {
+	err := run("", io.Discard, io.Discard, "push", remote, "--delete", name)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "remote ref does not exist") {
+		return nil
+	}
+	return err
+}
+
+// SetUpstream configures branch to track mergeRef on remote, the same settings
+// `git branch --set-upstream-to` writes. Unlike that command it does not require
+// a remote-tracking ref, so refs such as refs/pull/N/head can be tracked.
+func SetUpstream(branch, remote, mergeRef string) error {
+	return std.SetUpstream(context.Background(), branch, remote, mergeRef)
+}
+
+// SetUpstream is SetUpstream run by c.
+func (c *Client) SetUpstream(ctx context.Context, branch, remote, mergeRef string) error {
+	if err := c.silent(ctx, "config", "branch."+branch+".remote", remote); err != nil {
+		return err
+	}
+	return c.silent(ctx, "config", "branch."+branch+".merge", mergeRef)
 }
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// Client runs git commands through a Runner. The package functions use the
+// package Client, which runs git through the Runner set with SetRunner in the
+// current directory and writes to the output set with SetOutput. A Client
+// returned by NewClient shares none of that state, so several can be used at
+// once, e.g. one per pkg/wt Manager.
+type Client struct {
+	// r and out are nil for the package Client, which uses the package
+	// Runner and output instead.
+	r   Runner
+	dir string
+	out io.Writer
+}
+
+// std is the Client of the package functions.
+var std = &Client{}
+
+// Default returns the Client the package functions use.
+func Default() *Client {
+	return std
+}
+
+// NewClient returns a Client running git through r in dir, or the current
+// directory when dir is empty, for commands given no directory. The output of
+// commands whose output is not returned, such as git worktree add, is written
+// to out, or discarded when out is nil.
+func NewClient(r Runner, dir string, out io.Writer) *Client {
+	if out == nil {
+		out = io.Discard
+	}
+	return &Client{r: r, dir: dir, out: out}
+}
+
+// run runs git through c's Runner, traces it in debug mode, and returns
+// failures as *Error.
+func (c *Client) run(ctx context.Context, dir string, stdout, stderr io.Writer, args ...string) error {
+	if dir == "" {
+		dir = c.dir
+	}
+	r := c.r
+	if r == nil {
+		r = runner
+	}
+	if skipHooks {
+		args = append([]string{"-c", "core.hooksPath=" + os.DevNull}, args...)
+	}
+	var errOut bytes.Buffer
+	start := time.Now()
+	err := r.Run(ctx, dir, stdout, io.MultiWriter(stderr, &errOut), args...)
+	trace(dir, args, start, err)
+	return newError(args, err, errOut.String())
+}
+
+// output runs git and returns its combined output.
+func (c *Client) output(ctx context.Context, dir string, args ...string) (string, error) {
+	var out syncBuffer
+	err := c.run(ctx, dir, &out, &out, args...)
+	return out.String(), err
+}
+
+// command runs git in c's directory, writing its output to c's output.
+func (c *Client) command(ctx context.Context, args ...string) error {
+	return c.run(ctx, "", c.stdout(), c.stderr(), args...)
+}
+
+// silent runs git in c's directory without output.
+func (c *Client) silent(ctx context.Context, args ...string) error {
+	return c.run(ctx, "", io.Discard, io.Discard, args...)
+}
+
+// stdout returns where c writes the output of commands.
+func (c *Client) stdout() io.Writer {
+	if c.out != nil {
+		return c.out
+	}
+	return stdout
+}
+
+// stderr returns where c writes the error output of commands.
+func (c *Client) stderr() io.Writer {
+	if c.out != nil {
+		return c.out
+	}
+	return stderr
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors reported by git commands, matched with errors.Is.
+var (
+	ErrBranchExists   = errors.New("branch already exists")
+	ErrWorktreeLocked = errors.New("worktree is locked")
+	ErrDirtyWorktree  = errors.New("worktree has uncommitted changes")
+	ErrNotARepo       = errors.New("not a git repository")
+	ErrNetwork        = errors.New("network error")
+)
+
+// Error is a failed git command. Kind is one of the Err* sentinels when the
+// failure was recognized, and nil otherwise.
+type Error struct {
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Kind     error
+	Err      error
+}
+
+// Error describes the command, why it failed, and what git printed on stderr,
+// e.g. "git branch -D main: exit status 1: error: cannot delete branch 'main'".
+func (e *Error) Error() string {
+	reason := e.Err.Error()
+	if e.Kind != nil {
+		reason = e.Kind.Error()
+	}
+	msg := "git " + strings.Join(e.Args, " ") + ": " + reason
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+// Unwrap returns Kind and the underlying error, so errors.Is matches both.
+func (e *Error) Unwrap() []error {
+	if e.Kind != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// errorKinds maps messages git prints on stderr to sentinel errors.
+var errorKinds = []struct {
+	substr string
+	kind   error
+}{
+	{"not a git repository", ErrNotARepo},
+	{"already exists", ErrBranchExists},
+	{"is locked", ErrWorktreeLocked},
+	{"locked working tree", ErrWorktreeLocked},
+	{"contains modified or untracked files", ErrDirtyWorktree},
+	{"could not resolve host", ErrNetwork},
+	{"temporary failure in name resolution", ErrNetwork},
+	{"failed to connect", ErrNetwork},
+	{"connection refused", ErrNetwork},
+	{"connection reset", ErrNetwork},
+	{"connection timed out", ErrNetwork},
+	{"operation timed out", ErrNetwork},
+	{"rpc failed", ErrNetwork},
+	{"early eof", ErrNetwork},
+	{"the remote end hung up unexpectedly", ErrNetwork},
+}
+
+// classify returns the sentinel error for git's stderr, or nil.
+func classify(stderr string) error {
+	stderr = strings.ToLower(stderr)
+	for _, k := range errorKinds {
+		if !strings.Contains(stderr, k.substr) {
+			continue
+		}
+		// Only a branch is reported as existing; paths that exist are not.
+		if k.kind == ErrBranchExists && !strings.Contains(stderr, "branch") {
+			continue
+		}
+		// A server hanging up after rejecting credentials won't recover.
+		if k.kind == ErrNetwork && (strings.Contains(stderr, "authentication failed") || strings.Contains(stderr, "permission denied")) {
+			continue
+		}
+		return k.kind
+	}
+	return nil
+}
+
+// newError wraps err from running git with args as an *Error. Errors other
+// than a non-zero exit, e.g. git not being installed, are returned unchanged.
+func newError(args []string, err error, stderr string) error {
+	var exitErr interface{ ExitCode() int }
+	if err == nil || !errors.As(err, &exitErr) {
+		return err
+	}
+	return &Error{
+		Args:     args,
+		ExitCode: exitErr.ExitCode(),
+		Stderr:   strings.TrimSpace(stderr),
+		Kind:     classify(stderr),
+		Err:      err,
+	}
+}
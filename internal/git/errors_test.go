@@ -0,0 +1,44 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   error
+	}{
+		{"fatal: not a git repository (or any of the parent directories): .git", ErrNotARepo},
+		{"fatal: a branch named 'fix' already exists", ErrBranchExists},
+		{"fatal: '/tmp/wt/fix' already exists", nil},
+		{"fatal: cannot remove a locked working tree, lock reason: in use", ErrWorktreeLocked},
+		{"fatal: '/tmp/wt/fix' contains modified or untracked files, use --force to delete it", ErrDirtyWorktree},
+		{"fatal: couldn't find remote ref refs/pull/1/head", nil},
+		{"fatal: unable to access 'https://github.com/o/r/': Could not resolve host: github.com", ErrNetwork},
+		{"error: RPC failed; curl 56 GnuTLS recv error (-9)\nfatal: early EOF", ErrNetwork},
+		{"git@github.com: Permission denied (publickey).\nfatal: the remote end hung up unexpectedly", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classify(tt.stderr), tt.stderr)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+
+	_, err := CommandOutputAt(dir, "rev-parse", "--git-dir")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotARepo)
+
+	var gitErr *Error
+	require.True(t, errors.As(err, &gitErr))
+	assert.Equal(t, 128, gitErr.ExitCode)
+	assert.Equal(t, []string{"rev-parse", "--git-dir"}, gitErr.Args)
+	assert.Contains(t, gitErr.Stderr, "not a git repository")
+}
@@ -0,0 +1,11 @@
+package git
+
+import "time"
+
+// SetSleep replaces how Fetch waits between retries and returns a function
+// restoring it.
+func SetSleep(f func(time.Duration)) func() {
+	prev := sleep
+	sleep = f
+	return func() { sleep = prev }
+}
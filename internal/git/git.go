@@ -1,50 +1,67 @@
 package git
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// stdout receives the output of Command. It defaults to os.Stdout.
+var stdout io.Writer = os.Stdout
+
+// stderr receives the error output of Command. It defaults to os.Stderr.
+var stderr io.Writer = os.Stderr
+
+// SetOutput sets where Command writes git's standard output, e.g. os.Stderr
+// when stdout is reserved for machine-readable output.
+func SetOutput(w io.Writer) {
+	stdout = w
+}
+
 // Command runs a git command in the current directory.
 func Command(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return std.command(context.Background(), args...)
 }
 
 // CommandSilent runs a git command without output in the current directory.
 func CommandSilent(args ...string) error {
-	cmd := exec.Command("git", args...)
-	return cmd.Run()
+	return std.silent(context.Background(), args...)
 }
 
 // CommandOutput runs a git command and returns the output from current directory.
 func CommandOutput(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+	return CommandOutputAt("", args...)
 }
 
 // CommandOutputAt runs a git command and returns the output from specified directory.
 func CommandOutputAt(path string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = path
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+	return output(path, args...)
 }
 
 // WorktreeAdd adds a worktree with a new branch.
 func WorktreeAdd(branch, worktreePath string) error {
-	return Command("worktree", "add", "-b", branch, worktreePath)
+	return std.WorktreeAdd(context.Background(), branch, worktreePath)
+}
+
+// WorktreeAdd is WorktreeAdd run by c.
+func (c *Client) WorktreeAdd(ctx context.Context, branch, worktreePath string) error {
+	return c.command(ctx, "worktree", "add", "-b", branch, worktreePath)
 }
 
 // WorktreeAddFromRef adds a worktree from a specific ref.
 func WorktreeAddFromRef(branch, worktreePath, ref string) error {
-	return Command("worktree", "add", "-b", branch, worktreePath, ref)
+	return std.WorktreeAddFromRef(context.Background(), branch, worktreePath, ref)
+}
+
+// WorktreeAddFromRef is WorktreeAddFromRef run by c.
+func (c *Client) WorktreeAddFromRef(ctx context.Context, branch, worktreePath, ref string) error {
+	return c.command(ctx, "worktree", "add", "-b", branch, worktreePath, ref)
 }
 
 // WorktreeAddFromBranch adds a worktree from an existing branch.
@@ -54,40 +71,182 @@ func WorktreeAddFromBranch(branch, worktreePath string) error {
 
 // WorktreeRemove removes a worktree.
 func WorktreeRemove(worktreePath string, force bool) error {
+	return std.WorktreeRemove(context.Background(), worktreePath, force)
+}
+
+// WorktreeRemove is WorktreeRemove run by c.
+func (c *Client) WorktreeRemove(ctx context.Context, worktreePath string, force bool) error {
 	args := []string{"worktree", "remove", worktreePath}
 	if force {
 		args = append(args, "--force")
 	}
-	return Command(args...)
+	return c.command(ctx, args...)
+}
+
+// WorktreeMove moves a worktree to a new path.
+func WorktreeMove(worktreePath, newPath string) error {
+	return WorktreeMoveAt("", worktreePath, newPath)
+}
+
+// WorktreeMoveAt moves a worktree of the repository at repoDir to a new path.
+func WorktreeMoveAt(repoDir, worktreePath, newPath string) error {
+	return run(repoDir, stdout, stderr, "worktree", "move", worktreePath, newPath)
+}
+
+// WorktreeRepair repairs the links between the worktree at worktreePath and
+// its repository, e.g. after the worktree directory was moved without git.
+func WorktreeRepair(worktreePath string) error {
+	return run(worktreePath, stdout, stderr, "worktree", "repair")
+}
+
+// FetchOptions are optional flags for Fetch.
+type FetchOptions struct {
+	// Prune removes remote-tracking refs matching the fetched refspecs that
+	// no longer exist on the remote.
+	Prune bool
+	// Tags fetches all tags, not only those pointing into fetched history.
+	Tags bool
 }
 
-// Fetch fetches refs from origin.
-func Fetch(refs ...string) error {
-	args := append([]string{"fetch", "origin"}, refs...)
-	return Command(args...)
+// Fetch fetches refs from remote, or the remote's configured refspecs when
+// refs is empty.
+//
+// A fetch failing with ErrNetwork is retried up to the count set with
+// SetFetchRetries, waiting twice as long before each attempt.
+func Fetch(remote string, opts FetchOptions, refs ...string) error {
+	return FetchAt("", remote, opts, refs...)
+}
+
+// FetchAt is Fetch for the repository at dir.
+func FetchAt(dir, remote string, opts FetchOptions, refs ...string) error {
+	return std.FetchAt(context.Background(), dir, remote, opts, refs...)
+}
+
+// Fetch is Fetch run by c. Retries stop once ctx is done.
+func (c *Client) Fetch(ctx context.Context, remote string, opts FetchOptions, refs ...string) error {
+	return c.FetchAt(ctx, "", remote, opts, refs...)
+}
+
+// FetchAt is FetchAt run by c.
+func (c *Client) FetchAt(ctx context.Context, dir, remote string, opts FetchOptions, refs ...string) error {
+	args := []string{"fetch"}
+	if opts.Prune {
+		args = append(args, "--prune")
+	}
+	if opts.Tags {
+		args = append(args, "--tags")
+	}
+	args = append(append(args, remote), refs...)
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := c.run(ctx, dir, c.stdout(), c.stderr(), args...)
+		if err == nil || !errors.Is(err, ErrNetwork) || attempt > fetchRetries || ctx.Err() != nil {
+			return err
+		}
+		if log != nil {
+			log.Warnf("git fetch failed: %s; retrying in %s (%d/%d)\n", lastLine(err), delay, attempt, fetchRetries)
+		}
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+// fetchRetries is how many times Fetch retries after a network error.
+var fetchRetries int
+
+// retryDelay is the wait before Fetch's first retry; sleep waits it out.
+var (
+	retryDelay = time.Second
+	sleep      = time.Sleep
+)
+
+// SetFetchRetries sets how many times Fetch retries a fetch that failed with
+// a network error.
+func SetFetchRetries(n int) {
+	fetchRetries = max(n, 0)
+}
+
+// skipHooks disables the repository's git hooks for every command run.
+var skipHooks bool
+
+// SetSkipHooks sets whether git commands run without the repository's hooks,
+// such as post-checkout on worktree add, by pointing core.hooksPath at an
+// empty location.
+func SetSkipHooks(skip bool) {
+	skipHooks = skip
+}
+
+// lastLine returns the last line of git's stderr for err, or err's message.
+func lastLine(err error) string {
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.Stderr != "" {
+		lines := strings.Split(gitErr.Stderr, "\n")
+		return lines[len(lines)-1]
+	}
+	return err.Error()
+}
+
+// RemoteRefExists reports whether ref exists on the given remote.
+func RemoteRefExists(remote, ref string) (bool, error) {
+	return std.RemoteRefExists(context.Background(), remote, ref)
+}
+
+// RemoteRefExists is RemoteRefExists run by c.
+func (c *Client) RemoteRefExists(ctx context.Context, remote, ref string) (bool, error) {
+	out, err := c.output(ctx, "", "ls-remote", remote, ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to query %s: %w", remote, err)
+	}
+	return strings.TrimSpace(out) != "", nil
 }
 
 // HasUncommittedChanges checks if a worktree has uncommitted changes.
 func HasUncommittedChanges(worktreePath string) bool {
+	return std.HasUncommittedChanges(context.Background(), worktreePath)
+}
+
+// HasUncommittedChanges is HasUncommittedChanges run by c.
+func (c *Client) HasUncommittedChanges(ctx context.Context, worktreePath string) bool {
 	// Check for staged or unstaged changes
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = worktreePath
-	out, err := cmd.Output()
-	if err != nil {
+	var out bytes.Buffer
+	if err := c.run(ctx, worktreePath, &out, io.Discard, "status", "--porcelain"); err != nil {
 		return false
 	}
-	return len(strings.TrimSpace(string(out))) > 0
+	return len(strings.TrimSpace(out.String())) > 0
 }
 
 // WorktreeInfo represents information about a worktree.
 type WorktreeInfo struct {
-	Path   string
+	Path string
+	// Branch is empty when the worktree is detached.
 	Branch string
+	// Head is the commit checked out.
+	Head string
+	// Detached is set when the worktree has no branch checked out.
+	Detached bool
+	// Bare is set for the bare repository entry of a bare clone.
+	Bare bool
 }
 
 // GetWorktreeInfo returns worktree info (path and branch) for all worktrees.
 func GetWorktreeInfo() ([]WorktreeInfo, error) {
-	out, err := CommandOutput("worktree", "list", "--porcelain")
+	return GetWorktreeInfoAt("")
+}
+
+// GetWorktreeInfoAt returns worktree info for all worktrees of the repository
+// at dir. The main worktree comes first.
+func GetWorktreeInfoAt(dir string) ([]WorktreeInfo, error) {
+	return std.GetWorktreeInfoAt(context.Background(), dir)
+}
+
+// GetWorktreeInfo is GetWorktreeInfo run by c.
+func (c *Client) GetWorktreeInfo(ctx context.Context) ([]WorktreeInfo, error) {
+	return c.GetWorktreeInfoAt(ctx, "")
+}
+
+// GetWorktreeInfoAt is GetWorktreeInfoAt run by c.
+func (c *Client) GetWorktreeInfoAt(ctx context.Context, dir string) ([]WorktreeInfo, error) {
+	out, err := c.output(ctx, dir, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
@@ -107,6 +266,12 @@ func GetWorktreeInfo() ([]WorktreeInfo, error) {
 			branch := strings.TrimPrefix(line, "branch ")
 			// Strip "refs/heads/" prefix if present
 			current.Branch = strings.TrimPrefix(branch, "refs/heads/")
+		} else if strings.HasPrefix(line, "HEAD ") {
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		} else if line == "detached" {
+			current.Detached = true
+		} else if line == "bare" {
+			current.Bare = true
 		}
 	}
 	if current.Path != "" {
@@ -142,9 +307,11 @@ func ListAllWorktrees(baseDir string) ([]WorktreeInfo, error) {
 			if err != nil {
 				continue
 			}
+			branch = strings.TrimSpace(branch)
 			worktrees = append(worktrees, WorktreeInfo{
-				Path:   wtPath,
-				Branch: strings.TrimSpace(branch),
+				Path:     wtPath,
+				Branch:   branch,
+				Detached: branch == "",
 			})
 		}
 	}
@@ -153,7 +320,12 @@ func ListAllWorktrees(baseDir string) ([]WorktreeInfo, error) {
 
 // WorktreeIsRegistered checks if a worktree path is registered in git.
 func WorktreeIsRegistered(worktreePath string) bool {
-	worktrees, err := GetWorktreeInfo()
+	return std.WorktreeIsRegistered(context.Background(), worktreePath)
+}
+
+// WorktreeIsRegistered is WorktreeIsRegistered run by c.
+func (c *Client) WorktreeIsRegistered(ctx context.Context, worktreePath string) bool {
+	worktrees, err := c.GetWorktreeInfo(ctx)
 	if err != nil {
 		return false
 	}
@@ -181,15 +353,17 @@ func GetWorktreeBranch(worktreePath string) (string, error) {
 
 // WorktreePrune prunes stale worktree records.
 func WorktreePrune() error {
-	return CommandSilent("worktree", "prune")
+	return std.WorktreePrune(context.Background())
+}
+
+// WorktreePrune is WorktreePrune run by c.
+func (c *Client) WorktreePrune(ctx context.Context) error {
+	return c.silent(ctx, "worktree", "prune")
 }
 
 // IsGitRepository checks if a directory is a git repository.
 func IsGitRepository(path string) bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = path
-	err := cmd.Run()
-	return err == nil
+	return run(path, io.Discard, io.Discard, "rev-parse", "--git-dir") == nil
 }
 
 // GetRepoName returns the repository name from the current working directory.
@@ -203,9 +377,29 @@ func GetRepoName() (string, error) {
 
 // GetGitRoot returns the git root directory.
 func GetGitRoot() (string, error) {
-	out, err := CommandOutput("rev-parse", "--show-toplevel")
+	return std.GetGitRoot(context.Background())
+}
+
+// GetGitRoot is GetGitRoot run by c.
+func (c *Client) GetGitRoot(ctx context.Context) (string, error) {
+	out, err := c.output(ctx, "", "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", fmt.Errorf("failed to get git root directory: %w", err)
 	}
 	return strings.TrimSpace(out), nil
 }
+
+// RemoteURL returns the URL of a remote as configured, before any url.insteadOf
+// rewrites.
+func RemoteURL(remote string) (string, error) {
+	out, err := CommandOutput("config", "--get", "remote."+remote+".url")
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", remote, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// AddRemote adds a remote named name fetching from url.
+func AddRemote(name, url string) error {
+	return CommandSilent("remote", "add", name, url)
+}
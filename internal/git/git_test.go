@@ -0,0 +1,259 @@
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/git/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWorktreeInfo(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"worktree list --porcelain": {Stdout: "worktree /src/repo.git\nbare\n\n" +
+			"worktree /src/repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+			"worktree /wt/repo/pr_1\nHEAD def\nbranch refs/heads/feature/x\n\n" +
+			"worktree /wt/repo/detached\nHEAD 123\ndetached\n"},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	worktrees, err := git.GetWorktreeInfo()
+	require.NoError(t, err)
+	assert.Equal(t, []git.WorktreeInfo{
+		{Path: "/src/repo.git", Bare: true},
+		{Path: "/src/repo", Branch: "main", Head: "abc"},
+		{Path: "/wt/repo/pr_1", Branch: "feature/x", Head: "def"},
+		{Path: "/wt/repo/detached", Head: "123", Detached: true},
+	}, worktrees)
+	assert.Equal(t, []string{"worktree list --porcelain"}, fake.Calls())
+}
+
+func TestFakeErrors(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"branch -D main": {Stderr: "error: cannot delete branch 'main'\n", ExitCode: 1},
+		"worktree add -b fix /wt/repo/fix": {
+			Stderr:   "fatal: a branch named 'fix' already exists\n",
+			ExitCode: 128,
+		},
+		"show-ref --verify --quiet refs/heads/fix": {},
+		"show-ref --verify --quiet refs/heads/new": {ExitCode: 1},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	assert.ErrorIs(t, git.WorktreeAdd("fix", "/wt/repo/fix"), git.ErrBranchExists)
+	assert.True(t, git.BranchExists("fix"))
+	assert.False(t, git.BranchExists("new"))
+
+	err := git.CommandSilent("branch", "-D", "main")
+	var gitErr *git.Error
+	require.ErrorAs(t, err, &gitErr)
+	assert.Equal(t, 1, gitErr.ExitCode)
+	assert.Nil(t, gitErr.Kind)
+	assert.Equal(t, "error: cannot delete branch 'main'", gitErr.Stderr)
+	assert.EqualError(t, err, "git branch -D main: exit status 1: error: cannot delete branch 'main'")
+
+	err = git.WorktreeAdd("fix", "/wt/repo/fix")
+	assert.EqualError(t, err, "git worktree add -b fix /wt/repo/fix: branch already exists: fatal: a branch named 'fix' already exists")
+}
+
+func TestFetchRetries(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"fetch origin refs/pull/1/head": {
+			Stderr:   "fatal: unable to access 'https://github.com/o/r/': Could not resolve host: github.com\n",
+			ExitCode: 128,
+		},
+		"fetch origin refs/pull/2/head": {
+			Stderr:   "fatal: couldn't find remote ref refs/pull/2/head\n",
+			ExitCode: 128,
+		},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+	var waits []time.Duration
+	t.Cleanup(git.SetSleep(func(d time.Duration) { waits = append(waits, d) }))
+	git.SetFetchRetries(2)
+	t.Cleanup(func() { git.SetFetchRetries(0) })
+
+	assert.ErrorIs(t, git.Fetch("origin", git.FetchOptions{}, "refs/pull/1/head"), git.ErrNetwork)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+
+	// Errors other than network errors are not retried.
+	waits = nil
+	assert.Error(t, git.Fetch("origin", git.FetchOptions{}, "refs/pull/2/head"))
+	assert.Empty(t, waits)
+	assert.Equal(t, []string{
+		"fetch origin refs/pull/1/head",
+		"fetch origin refs/pull/1/head",
+		"fetch origin refs/pull/1/head",
+		"fetch origin refs/pull/2/head",
+	}, fake.Calls())
+}
+
+func TestFetchOptions(t *testing.T) {
+	fake := &gittest.Fake{}
+	t.Cleanup(git.SetRunner(fake))
+
+	require.NoError(t, git.Fetch("origin", git.FetchOptions{Prune: true, Tags: true}, "refs/pull/1/head", "+refs/heads/*:refs/remotes/origin/*"))
+	require.NoError(t, git.Fetch("upstream", git.FetchOptions{}))
+	assert.Equal(t, []string{
+		"fetch --prune --tags origin refs/pull/1/head +refs/heads/*:refs/remotes/origin/*",
+		"fetch upstream",
+	}, fake.Calls())
+}
+
+func TestSetSkipHooks(t *testing.T) {
+	fake := &gittest.Fake{}
+	t.Cleanup(git.SetRunner(fake))
+	git.SetSkipHooks(true)
+	t.Cleanup(func() { git.SetSkipHooks(false) })
+
+	require.NoError(t, git.WorktreeAddFromRef("fix", "/wt/repo/fix", "HEAD"))
+	assert.Equal(t, []string{
+		"-c core.hooksPath=" + os.DevNull + " worktree add -b fix /wt/repo/fix HEAD",
+	}, fake.Calls())
+}
+
+func TestGetCurrentBranchDetached(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"rev-parse --abbrev-ref HEAD": {Stdout: "HEAD\n"},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	branch, err := git.GetCurrentBranchAtCwd()
+	require.NoError(t, err)
+	assert.Empty(t, branch)
+}
+
+func TestIsProtectedBranch(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD": {Stdout: "origin/trunk\n"},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	assert.Equal(t, "trunk", git.DefaultBranch())
+	assert.True(t, git.IsProtectedBranch("main"))
+	assert.True(t, git.IsProtectedBranch("master"))
+	assert.True(t, git.IsProtectedBranch("trunk"))
+	assert.False(t, git.IsProtectedBranch("fix-login"))
+	assert.False(t, git.IsProtectedBranch(""))
+
+	fake.Responses["symbolic-ref --quiet --short refs/remotes/origin/HEAD"] = gittest.Response{ExitCode: 1}
+	assert.Empty(t, git.DefaultBranch())
+	assert.False(t, git.IsProtectedBranch("trunk"))
+}
+
+func TestRemoteBranch(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"config --get branch.fix.remote":   {Stdout: "origin\n"},
+		"config --get branch.fix.merge":    {Stdout: "refs/heads/fix-login\n"},
+		"config --get branch.pr_3.remote":  {Stdout: "origin\n"},
+		"config --get branch.pr_3.merge":   {Stdout: "refs/pull/3/head\n"},
+		"config --get branch.local.remote": {ExitCode: 1},
+		"push origin --delete gone":        {Stderr: "error: unable to delete 'gone': remote ref does not exist\n", ExitCode: 1},
+		"push origin --delete protected":   {Stderr: "remote: error: Cannot delete this branch\n", ExitCode: 1},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	remote, name := git.BranchUpstream("fix")
+	assert.Equal(t, "origin", remote)
+	assert.Equal(t, "fix-login", name)
+	remote, name = git.BranchUpstream("pr_3")
+	assert.Empty(t, remote+name)
+	remote, name = git.BranchUpstream("local")
+	assert.Empty(t, remote+name)
+
+	assert.NoError(t, git.DeleteRemoteBranch("origin", "fix-login"))
+	assert.NoError(t, git.DeleteRemoteBranch("origin", "gone"))
+	assert.Error(t, git.DeleteRemoteBranch("origin", "protected"))
+}
+
+func TestBranchMerged(t *testing.T) {
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"rev-parse --verify --quiet fix@{upstream}":              {Stdout: "abc\n"},
+		"merge-base --is-ancestor refs/heads/fix fix@{upstream}": {},
+		"rev-parse --verify --quiet local@{upstream}":            {ExitCode: 1},
+		"merge-base --is-ancestor refs/heads/local HEAD":         {ExitCode: 1},
+		"rev-parse --verify --quiet done@{upstream}":             {ExitCode: 1},
+		"merge-base --is-ancestor refs/heads/done HEAD":          {},
+	}}
+	t.Cleanup(git.SetRunner(fake))
+
+	assert.True(t, git.BranchMerged("fix"), "merged into its upstream")
+	assert.False(t, git.BranchMerged("local"), "not merged into HEAD")
+	assert.True(t, git.BranchMerged("done"), "merged into HEAD")
+}
+
+func TestSetWorktreeConfig(t *testing.T) {
+	dir := t.TempDir()
+	repo, wt := filepath.Join(dir, "repo"), filepath.Join(dir, "wt")
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", repo, "worktree", "add", "-q", "-b", "wt", wt},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	require.NoError(t, git.SetWorktreeConfig(wt, "user.email", "me@work.example"))
+
+	out, err := git.CommandOutputAt(wt, "config", "user.email")
+	require.NoError(t, err)
+	assert.Equal(t, "me@work.example", strings.TrimSpace(out))
+	out, _ = git.CommandOutputAt(repo, "config", "--local", "user.email")
+	assert.Empty(t, strings.TrimSpace(out), "main worktree is unchanged")
+
+	value, ok, err := git.WorktreeConfig(wt, "user.email")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "me@work.example", value)
+	_, ok, err = git.WorktreeConfig(repo, "user.email")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	settings, err := git.ListWorktreeConfig(wt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user.email=me@work.example"}, settings)
+
+	require.NoError(t, git.UnsetWorktreeConfig(wt, "user.email"))
+	require.NoError(t, git.UnsetWorktreeConfig(wt, "user.email"), "unsetting twice is fine")
+	settings, err = git.ListWorktreeConfig(wt)
+	require.NoError(t, err)
+	assert.Empty(t, settings)
+}
+
+func TestWorktreeSupport(t *testing.T) {
+	dir := t.TempDir()
+	sub, super := filepath.Join(dir, "sub"), filepath.Join(dir, "super")
+	for _, args := range [][]string{
+		{"init", "-q", sub},
+		{"-C", sub, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"init", "-q", super},
+		{"-C", super, "-c", "protocol.file.allow=always", "submodule", "add", "-q", sub, "lib"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	support, err := git.CheckWorktreeSupport(super)
+	require.NoError(t, err)
+	assert.Equal(t, git.WorktreeSupport{}, support)
+
+	lib := filepath.Join(super, "lib")
+	support, err = git.CheckWorktreeSupport(lib)
+	require.NoError(t, err)
+	assert.Equal(t, git.WorktreeSupport{Submodule: true, SharedCoreWorktree: true}, support)
+
+	require.NoError(t, git.EnableWorktreeConfig(lib))
+	support, err = git.CheckWorktreeSupport(lib)
+	require.NoError(t, err)
+	assert.False(t, support.SharedCoreWorktree)
+	out, err := git.CommandOutputAt(lib, "rev-parse", "--show-toplevel")
+	require.NoError(t, err)
+	assert.Equal(t, lib, strings.TrimSpace(out), "the submodule keeps its work tree")
+}
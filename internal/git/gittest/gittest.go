@@ -0,0 +1,60 @@
+// Package gittest provides a fake git.Runner for tests that should not need a
+// real git repository.
+package gittest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Response is the result of a faked git command.
+type Response struct {
+	Stdout string
+	Stderr string
+	// ExitCode is the exit status; non-zero fails the command.
+	ExitCode int
+}
+
+// Fake is a git.Runner that answers commands from Responses, keyed by the
+// space-joined arguments, e.g. "branch --show-current". Unknown commands
+// succeed with no output. Every command run is recorded in Calls.
+type Fake struct {
+	Responses map[string]Response
+
+	mu    sync.Mutex
+	calls []string
+}
+
+// Run implements git.Runner.
+func (f *Fake) Run(_ context.Context, _ string, stdout, stderr io.Writer, args ...string) error {
+	cmd := strings.Join(args, " ")
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	resp := f.Responses[cmd]
+	f.mu.Unlock()
+
+	io.WriteString(stdout, resp.Stdout)
+	io.WriteString(stderr, resp.Stderr)
+	if resp.ExitCode != 0 {
+		return ExitError(resp.ExitCode)
+	}
+	return nil
+}
+
+// Calls returns the commands run so far.
+func (f *Fake) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// ExitError is the error a Fake returns for a non-zero exit status.
+type ExitError int
+
+func (e ExitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// ExitCode returns the exit status.
+func (e ExitError) ExitCode() int { return int(e) }
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrRebaseConflict is returned by Rebase when it stopped on conflicts that
+// have to be resolved by hand.
+var ErrRebaseConflict = errors.New("rebase stopped on conflicts")
+
+// Rebase rebases the branch checked out in the worktree at path onto
+// upstream. Editors are disabled so that it never waits for input. When it
+// stops on conflicts, the rebase is left in progress for resolving them and
+// ErrRebaseConflict is returned with the conflicting files.
+func Rebase(path, upstream string) ([]string, error) {
+	err := run(path, stdout, stderr, "-c", "core.editor=true", "-c", "sequence.editor=true", "rebase", upstream)
+	if err == nil {
+		return nil, nil
+	}
+	if !RebaseInProgress(path) {
+		return nil, err
+	}
+	return ConflictedFiles(path), fmt.Errorf("%w: %w", ErrRebaseConflict, err)
+}
+
+// RebaseInProgress reports whether a rebase is in progress in the worktree at
+// path.
+func RebaseInProgress(path string) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := CommandOutputAt(path, "rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		p := strings.TrimSpace(out)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(path, p)
+		}
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ConflictedFiles returns the files with unresolved conflicts in the worktree
+// at path.
+func ConflictedFiles(path string) []string {
+	out, err := CommandOutputAt(path, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// IsAncestor reports whether commit is an ancestor of, or the same as, the
+// HEAD of the worktree at path.
+func IsAncestor(path, commit string) bool {
+	return run(path, io.Discard, io.Discard, "merge-base", "--is-ancestor", commit, "HEAD") == nil
+}
@@ -0,0 +1,62 @@
+package git_test
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebase(t *testing.T) {
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "t")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "t@example.com")
+	}
+	git.SetOutput(io.Discard)
+	t.Cleanup(func() { git.SetOutput(os.Stdout) })
+
+	dir := t.TempDir()
+	repo, wt := filepath.Join(dir, "repo"), filepath.Join(dir, "wt")
+	gitIn := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-C", dir}, args...)
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commit := func(dir, file, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+		gitIn(dir, "add", file)
+		gitIn(dir, "commit", "-q", "-m", file)
+	}
+
+	gitIn(dir, "init", "-q", "-b", "main", repo)
+	commit(repo, "a.txt", "a\n")
+	gitIn(repo, "worktree", "add", "-q", "-b", "feature", wt)
+	commit(wt, "b.txt", "feature\n")
+	commit(repo, "c.txt", "main\n")
+
+	assert.False(t, git.IsAncestor(wt, "main"))
+	conflicts, err := git.Rebase(wt, "main")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.True(t, git.IsAncestor(wt, "main"))
+
+	commit(wt, "a.txt", "feature\n")
+	commit(repo, "a.txt", "main\n")
+	conflicts, err = git.Rebase(wt, "main")
+	require.ErrorIs(t, err, git.ErrRebaseConflict)
+	assert.Equal(t, []string{"a.txt"}, conflicts)
+	assert.True(t, git.RebaseInProgress(wt))
+	assert.False(t, git.RebaseInProgress(repo), "only the rebasing worktree is affected")
+
+	gitIn(wt, "rebase", "--abort")
+	assert.False(t, git.RebaseInProgress(wt))
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidBranchName is returned by CheckBranchName for names git rejects.
+var ErrInvalidBranchName = errors.New("invalid branch name")
+
+// refForbidden are the characters git check-ref-format rejects anywhere in a
+// ref name, besides control characters.
+const refForbidden = " ~^:?*[\\"
+
+// CheckBranchName checks name against the rules of git check-ref-format
+// --branch and returns an error wrapping ErrInvalidBranchName that says which
+// rule it breaks. Characters git allows, such as / and . and non-ASCII
+// letters, are accepted.
+func CheckBranchName(name string) error {
+	invalid := func(reason string, args ...any) error {
+		return fmt.Errorf("%w '%s': %s", ErrInvalidBranchName, name, fmt.Sprintf(reason, args...))
+	}
+	switch {
+	case name == "":
+		return invalid("it is empty")
+	case name == "@" || name == "HEAD":
+		return invalid("'%s' is reserved", name)
+	case strings.HasPrefix(name, "-"):
+		return invalid("it starts with '-'")
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return invalid("it starts or ends with '/'")
+	case strings.Contains(name, "//"):
+		return invalid("it contains '//'")
+	case strings.HasSuffix(name, "."):
+		return invalid("it ends with '.'")
+	case strings.Contains(name, ".."):
+		return invalid("it contains '..'")
+	case strings.Contains(name, "@{"):
+		return invalid("it contains '@{'")
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return invalid("it contains a control character")
+		}
+		if strings.ContainsRune(refForbidden, r) {
+			return invalid("it contains %q", r)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return invalid("a component starts with '.'")
+		}
+		if strings.HasSuffix(part, ".lock") {
+			return invalid("a component ends with '.lock'")
+		}
+	}
+	return nil
+}
+
+// SanitizeBranchName returns name changed only where git would reject it:
+// forbidden characters become '_', repeated dots and slashes are collapsed,
+// and leading or trailing characters that are not allowed are dropped. Valid
+// names are returned unchanged.
+func SanitizeBranchName(name string) string {
+	if CheckBranchName(name) == nil {
+		return name
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(refForbidden, r) {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	name = strings.ReplaceAll(b.String(), "@{", "_{")
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", ".")
+	}
+
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		part = strings.TrimLeft(part, ".")
+		if strings.HasSuffix(part, ".lock") {
+			part = strings.TrimSuffix(part, ".lock") + "-lock"
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	name = strings.TrimLeft(strings.TrimRight(strings.Join(parts, "/"), "."), "-")
+	if CheckBranchName(name) != nil {
+		// Only reserved names such as HEAD, or nothing at all, remain.
+		return name + "_"
+	}
+	return name
+}
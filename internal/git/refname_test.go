@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBranchName(t *testing.T) {
+	valid := []string{"fix", "feature/auth-refactor", "release/v1.2", "fix.v2", "ümlaut-ä", "🚀-launch", "a@b", "issue_12"}
+	for _, name := range valid {
+		assert.NoError(t, CheckBranchName(name), name)
+	}
+
+	invalid := []string{"", "@", "HEAD", "-fix", "/fix", "fix/", "a//b", "fix.", "a..b", "a@{1}", "fix me", "a~1", "a^", "a:b", "a?", "a*", "a[b", `a\b`, "a\tb", ".hidden", "a/.b", "fix.lock", "a.lock/b"}
+	for _, name := range invalid {
+		assert.ErrorIs(t, CheckBranchName(name), ErrInvalidBranchName, name)
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"feature/auth.v2", "feature/auth.v2"},
+		{"fix: login page", "fix__login_page"},
+		{"a..b", "a.b"},
+		{"/feature//x/", "feature/x"},
+		{".hidden/.x", "hidden/x"},
+		{"fix.lock", "fix-lock"},
+		{"-fix.", "fix"},
+		{"a@{1}", "a_{1}"},
+		{"HEAD", "HEAD_"},
+		{"..", "_"},
+		{"🚀 launch", "🚀_launch"},
+	}
+	for _, tt := range tests {
+		got := SanitizeBranchName(tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+		assert.NoError(t, CheckBranchName(got), tt.name)
+	}
+}
+
+// TestCheckBranchNameMatchesGit compares CheckBranchName with git itself.
+func TestCheckBranchNameMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	names := []string{"fix", "feature/x", "fix.v2", "a..b", "fix.lock", ".x", "a//b", "fix me", "a@{1}", "-fix", "fix.", "🚀", "a:b"}
+	for _, name := range names {
+		gitErr := exec.Command("git", "check-ref-format", "--branch", name).Run()
+		assert.Equal(t, gitErr == nil, CheckBranchName(name) == nil, name)
+	}
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner runs git commands. The package functions run git through the
+// current Runner, so tests can swap in a fake (see the gittest package) and
+// other backends can be plugged in.
+type Runner interface {
+	// Run runs git with args in dir, or the current directory when dir is
+	// empty, writing its standard output and error to stdout and stderr. A
+	// failed command returns an error with an ExitCode() int method, such as
+	// *exec.ExitError.
+	Run(ctx context.Context, dir string, stdout, stderr io.Writer, args ...string) error
+}
+
+// ExecRunner runs the git executable found on PATH.
+type ExecRunner struct{}
+
+// Run implements Runner.
+func (ExecRunner) Run(ctx context.Context, dir string, stdout, stderr io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+var runner Runner = ExecRunner{}
+
+// SetRunner replaces the Runner used by the package functions and returns a
+// function restoring the previous one.
+func SetRunner(r Runner) (restore func()) {
+	prev := runner
+	runner = r
+	return func() { runner = prev }
+}
+
+// run runs git through the package Client.
+func run(dir string, stdout, stderr io.Writer, args ...string) error {
+	return std.run(context.Background(), dir, stdout, stderr, args...)
+}
+
+// output runs git through the package Client and returns its combined output.
+func output(dir string, args ...string) (string, error) {
+	return std.output(context.Background(), dir, args...)
+}
+
+// trace logs the command line, working directory, duration and result of a
+// git command when debug mode is enabled.
+func trace(dir string, args []string, start time.Time, err error) {
+	if log == nil || !log.Debug {
+		return
+	}
+	if dir == "" {
+		dir = "."
+	}
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	log.Debugf("git %s (dir: %s) took %s: %s\n", strings.Join(args, " "), dir, time.Since(start).Round(time.Millisecond), status)
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a command's
+// stdout and stderr.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,11 @@
+package git
+
+import "github.com/ffalor/gh-wt/internal/logger"
+
+// log receives debug traces for git subprocesses. It is nil until SetLogger is called.
+var log *logger.Logger
+
+// SetLogger sets the logger used to trace git subprocesses in debug mode.
+func SetLogger(l *logger.Logger) {
+	log = l
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeSupport describes how well a repository supports linked worktrees.
+type WorktreeSupport struct {
+	// Submodule is set when the repository is a submodule of another
+	// repository. Git's worktree support for submodules is incomplete.
+	Submodule bool
+	// SharedCoreWorktree is set when core.worktree is set in the shared
+	// config, as it is in submodules, where it would point every linked
+	// worktree at the main one. EnableWorktreeConfig moves it.
+	SharedCoreWorktree bool
+}
+
+// CheckWorktreeSupport reports how well the repository at dir, or the current
+// directory when dir is empty, supports linked worktrees.
+func CheckWorktreeSupport(dir string) (WorktreeSupport, error) {
+	var support WorktreeSupport
+	out, err := CommandOutputAt(dir, "rev-parse", "--show-superproject-working-tree")
+	if err != nil {
+		return support, err
+	}
+	support.Submodule = strings.TrimSpace(out) != ""
+
+	shared, _, err := configFiles(dir)
+	if err != nil {
+		return support, err
+	}
+	worktree, _ := configValue(dir, shared, "core.worktree")
+	support.SharedCoreWorktree = worktree != ""
+	return support, nil
+}
+
+// EnableWorktreeConfig enables extensions.worktreeConfig in the repository at
+// dir, so each worktree can have its own config. As git requires, core.worktree
+// and core.bare = true are first moved from the shared config to the main
+// worktree's config, since they would otherwise apply to every worktree.
+func EnableWorktreeConfig(dir string) error {
+	if worktreeConfigEnabled(dir) {
+		return nil
+	}
+	shared, main, err := configFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"core.worktree", "core.bare"} {
+		value, _ := configValue(dir, shared, key)
+		if value == "" || (key == "core.bare" && value != "true") {
+			continue
+		}
+		if err := run(dir, io.Discard, io.Discard, "config", "--file", main, key, value); err != nil {
+			return err
+		}
+		if err := run(dir, io.Discard, io.Discard, "config", "--file", shared, "--unset", key); err != nil {
+			return err
+		}
+	}
+	return run(dir, io.Discard, io.Discard, "config", "--file", shared, "extensions.worktreeConfig", "true")
+}
+
+// SetWorktreeConfig sets key to value in the config of the worktree at
+// worktreePath only, enabling extensions.worktreeConfig in the repository if
+// needed.
+func SetWorktreeConfig(worktreePath, key, value string) error {
+	if err := EnableWorktreeConfig(worktreePath); err != nil {
+		return err
+	}
+	return run(worktreePath, io.Discard, io.Discard, "config", "--worktree", key, value)
+}
+
+// WorktreeConfig returns the value of key in the config of the worktree at
+// worktreePath only, and whether it is set there.
+func WorktreeConfig(worktreePath, key string) (string, bool, error) {
+	if !worktreeConfigEnabled(worktreePath) {
+		return "", false, nil
+	}
+	out, err := CommandOutputAt(worktreePath, "config", "--worktree", "--get", key)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(out), true, nil
+}
+
+// ListWorktreeConfig returns the settings in the config of the worktree at
+// worktreePath only, as key=value lines.
+func ListWorktreeConfig(worktreePath string) ([]string, error) {
+	if !worktreeConfigEnabled(worktreePath) {
+		return nil, nil
+	}
+	var out bytes.Buffer
+	if err := run(worktreePath, &out, io.Discard, "config", "--worktree", "--list"); err != nil {
+		return nil, err
+	}
+	list := strings.TrimSpace(out.String())
+	if list == "" {
+		return nil, nil
+	}
+	return strings.Split(list, "\n"), nil
+}
+
+// UnsetWorktreeConfig removes key from the config of the worktree at
+// worktreePath only. Removing a key that is not set is not an error.
+func UnsetWorktreeConfig(worktreePath, key string) error {
+	if !worktreeConfigEnabled(worktreePath) {
+		return nil
+	}
+	err := run(worktreePath, io.Discard, io.Discard, "config", "--worktree", "--unset-all", key)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 5 {
+		return nil
+	}
+	return err
+}
+
+// worktreeConfigEnabled reports whether extensions.worktreeConfig is enabled
+// in the repository of the worktree at worktreePath. Until it is, git config
+// --worktree reads and writes the shared config.
+func worktreeConfigEnabled(worktreePath string) bool {
+	out, _ := CommandOutputAt(worktreePath, "config", "--bool", "extensions.worktreeConfig")
+	return strings.TrimSpace(out) == "true"
+}
+
+// configFiles returns the shared config file of the repository at dir and the
+// per-worktree config file of its main worktree.
+func configFiles(dir string) (shared, main string, err error) {
+	out, err := CommandOutputAt(dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", "", err
+	}
+	common := strings.TrimSpace(out)
+	if !filepath.IsAbs(common) {
+		abs, err := filepath.Abs(filepath.Join(dir, common))
+		if err != nil {
+			return "", "", err
+		}
+		common = abs
+	}
+	return filepath.Join(common, "config"), filepath.Join(common, "config.worktree"), nil
+}
+
+// configValue returns the value of key in the config file at path, or "" when
+// it is unset.
+func configValue(dir, path, key string) (string, error) {
+	out, err := CommandOutputAt(dir, "config", "--file", path, key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
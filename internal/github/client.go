@@ -0,0 +1,32 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// transport sends the requests of GitHub clients. Tests replace it with a
+// stub.
+var transport http.RoundTripper = http.DefaultTransport
+
+// NewGraphQLClient returns a GraphQL client authenticated the same way as gh
+// that retries server errors and rate limits.
+func NewGraphQLClient() (*api.GraphQLClient, error) {
+	client, err := api.NewGraphQLClient(api.ClientOptions{Transport: &retryTransport{base: transport}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	return client, nil
+}
+
+// NewRESTClient returns a REST client authenticated the same way as gh that
+// retries server errors and rate limits.
+func NewRESTClient() (*api.RESTClient, error) {
+	client, err := api.NewRESTClient(api.ClientOptions{Transport: &retryTransport{base: transport}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrProjectNotFound is returned when the configured project cannot be resolved.
+var ErrProjectNotFound = errors.New("github: project not found")
+
+// ProjectStatusOptions identifies an issue and the project field value to set on it.
+type ProjectStatusOptions struct {
+	// Owner and Repo identify the repository containing the issue.
+	Owner  string
+	Repo   string
+	Number int
+	// ProjectOwner is the user or organization owning the project.
+	ProjectOwner  string
+	ProjectNumber int
+	// Field is the name of a single-select field, e.g. "Status".
+	Field string
+	// Value is the option to select, e.g. "In Progress".
+	Value string
+}
+
+const projectQuery = `
+query($login: String!, $number: Int!, $field: String!) {
+  repositoryOwner(login: $login) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        id
+        field(name: $field) {
+          ... on ProjectV2SingleSelectField {
+            id
+            options { id name }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const issueItemsQuery = `
+query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    issue(number: $number) {
+      id
+      projectItems(first: 100) {
+        nodes { id project { id } }
+      }
+    }
+  }
+}`
+
+const addItemMutation = `
+mutation($project: ID!, $content: ID!) {
+  addProjectV2ItemById(input: {projectId: $project, contentId: $content}) {
+    item { id }
+  }
+}`
+
+const updateFieldMutation = `
+mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+  updateProjectV2ItemFieldValue(input: {
+    projectId: $project, itemId: $item, fieldId: $field,
+    value: {singleSelectOptionId: $option}
+  }) {
+    projectV2Item { id }
+  }
+}`
+
+// SetProjectStatus moves an issue's Projects (v2) item to the given single-select
+// option, adding the issue to the project first if it is not already on it.
+func SetProjectStatus(ctx context.Context, opts ProjectStatusOptions) error {
+	client, err := NewGraphQLClient()
+	if err != nil {
+		return err
+	}
+
+	var project struct {
+		RepositoryOwner struct {
+			ProjectV2 *struct {
+				ID    string
+				Field *struct {
+					ID      string
+					Options []struct {
+						ID   string
+						Name string
+					}
+				}
+			}
+		}
+	}
+	if err := client.DoWithContext(ctx, projectQuery, map[string]any{
+		"login":  opts.ProjectOwner,
+		"number": opts.ProjectNumber,
+		"field":  opts.Field,
+	}, &project); err != nil {
+		return fmt.Errorf("failed to look up project %s/%d: %w", opts.ProjectOwner, opts.ProjectNumber, err)
+	}
+
+	p := project.RepositoryOwner.ProjectV2
+	if p == nil {
+		return fmt.Errorf("%w: %s/%d", ErrProjectNotFound, opts.ProjectOwner, opts.ProjectNumber)
+	}
+	if p.Field == nil || p.Field.ID == "" {
+		return fmt.Errorf("project %s/%d has no single-select field %q", opts.ProjectOwner, opts.ProjectNumber, opts.Field)
+	}
+
+	optionID := ""
+	var names []string
+	for _, o := range p.Field.Options {
+		if strings.EqualFold(o.Name, opts.Value) {
+			optionID = o.ID
+			break
+		}
+		names = append(names, o.Name)
+	}
+	if optionID == "" {
+		return fmt.Errorf("field %q has no option %q (available: %s)", opts.Field, opts.Value, strings.Join(names, ", "))
+	}
+
+	var issue struct {
+		Repository struct {
+			Issue *struct {
+				ID           string
+				ProjectItems struct {
+					Nodes []struct {
+						ID      string
+						Project struct{ ID string }
+					}
+				}
+			}
+		}
+	}
+	if err := client.DoWithContext(ctx, issueItemsQuery, map[string]any{
+		"owner":  opts.Owner,
+		"repo":   opts.Repo,
+		"number": opts.Number,
+	}, &issue); err != nil {
+		return fmt.Errorf("failed to look up issue #%d: %w", opts.Number, err)
+	}
+	if issue.Repository.Issue == nil {
+		return fmt.Errorf("issue #%d not found in %s/%s", opts.Number, opts.Owner, opts.Repo)
+	}
+
+	itemID := ""
+	for _, item := range issue.Repository.Issue.ProjectItems.Nodes {
+		if item.Project.ID == p.ID {
+			itemID = item.ID
+			break
+		}
+	}
+
+	if itemID == "" {
+		var added struct {
+			AddProjectV2ItemByID struct {
+				Item struct{ ID string }
+			} `json:"addProjectV2ItemById"`
+		}
+		if err := client.DoWithContext(ctx, addItemMutation, map[string]any{
+			"project": p.ID,
+			"content": issue.Repository.Issue.ID,
+		}, &added); err != nil {
+			return fmt.Errorf("failed to add issue #%d to project: %w", opts.Number, err)
+		}
+		itemID = added.AddProjectV2ItemByID.Item.ID
+	}
+
+	var updated struct{}
+	if err := client.DoWithContext(ctx, updateFieldMutation, map[string]any{
+		"project": p.ID,
+		"item":    itemID,
+		"field":   p.Field.ID,
+		"option":  optionID,
+	}, &updated); err != nil {
+		return fmt.Errorf("failed to update project field %q: %w", opts.Field, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// graphQLCall is a GraphQL request received by stubGraphQL.
+type graphQLCall struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// stubGraphQL makes GitHub clients answer GraphQL requests with respond,
+// given the request, and returns the requests received.
+func stubGraphQL(t *testing.T, respond func(call graphQLCall) string) *[]graphQLCall {
+	t.Helper()
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GH_HOST", "github.com")
+	var calls []graphQLCall
+	orig := transport
+	transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var call graphQLCall
+		if err := json.NewDecoder(req.Body).Decode(&call); err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewBufferString(respond(call))),
+			Request:    req,
+		}, nil
+	})
+	t.Cleanup(func() { transport = orig })
+	return &calls
+}
+
+func TestSetProjectStatus(t *testing.T) {
+	const (
+		project = `{"data":{"repositoryOwner":{"projectV2":{"id":"P1","field":{"id":"F1","options":[{"id":"O1","name":"Todo"},{"id":"O2","name":"In Progress"}]}}}}}`
+		onItem  = `{"data":{"repository":{"issue":{"id":"I1","projectItems":{"nodes":[{"id":"OTHER","project":{"id":"P9"}},{"id":"ITEM1","project":{"id":"P1"}}]}}}}}`
+		offItem = `{"data":{"repository":{"issue":{"id":"I1","projectItems":{"nodes":[{"id":"OTHER","project":{"id":"P9"}}]}}}}}`
+		added   = `{"data":{"addProjectV2ItemById":{"item":{"id":"NEW"}}}}`
+		updated = `{"data":{"updateProjectV2ItemFieldValue":{"projectV2Item":{"id":"ITEM1"}}}}`
+	)
+
+	tests := []struct {
+		name    string
+		value   string
+		project string
+		issue   string
+		// ops are the operations sent, by the top-level field they use.
+		ops []string
+		// item is the item whose field is updated.
+		item    string
+		wantErr string
+	}{
+		{
+			name:    "item already in the project",
+			value:   "in progress",
+			project: project,
+			issue:   onItem,
+			ops:     []string{"repositoryOwner", "repository", "updateProjectV2ItemFieldValue"},
+			item:    "ITEM1",
+		},
+		{
+			name:    "issue added to the project",
+			value:   "Todo",
+			project: project,
+			issue:   offItem,
+			ops:     []string{"repositoryOwner", "repository", "addProjectV2ItemById", "updateProjectV2ItemFieldValue"},
+			item:    "NEW",
+		},
+		{
+			name:    "missing field",
+			value:   "Todo",
+			project: `{"data":{"repositoryOwner":{"projectV2":{"id":"P1","field":null}}}}`,
+			ops:     []string{"repositoryOwner"},
+			wantErr: `project octo/5 has no single-select field "Status"`,
+		},
+		{
+			name:    "missing option",
+			value:   "Blocked",
+			project: project,
+			ops:     []string{"repositoryOwner"},
+			wantErr: `field "Status" has no option "Blocked" (available: Todo, In Progress)`,
+		},
+		{
+			name:    "missing project",
+			value:   "Todo",
+			project: `{"data":{"repositoryOwner":{"projectV2":null}}}`,
+			ops:     []string{"repositoryOwner"},
+			wantErr: ErrProjectNotFound.Error(),
+		},
+		{
+			name:    "missing issue",
+			value:   "Todo",
+			project: project,
+			issue:   `{"data":{"repository":{"issue":null}}}`,
+			ops:     []string{"repositoryOwner", "repository"},
+			wantErr: "issue #7 not found in octo/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := stubGraphQL(t, func(call graphQLCall) string {
+				switch op := operation(call.Query); op {
+				case "repositoryOwner":
+					return tt.project
+				case "repository":
+					return tt.issue
+				case "addProjectV2ItemById":
+					return added
+				case "updateProjectV2ItemFieldValue":
+					return updated
+				default:
+					t.Errorf("unexpected operation %q", op)
+					return `{"data":{}}`
+				}
+			})
+
+			err := SetProjectStatus(context.Background(), ProjectStatusOptions{
+				Owner:         "octo",
+				Repo:          "repo",
+				Number:        7,
+				ProjectOwner:  "octo",
+				ProjectNumber: 5,
+				Field:         "Status",
+				Value:         tt.value,
+			})
+
+			var ops []string
+			for _, call := range *calls {
+				ops = append(ops, operation(call.Query))
+			}
+			assert.Equal(t, tt.ops, ops)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			last := (*calls)[len(*calls)-1]
+			assert.Equal(t, map[string]any{"project": "P1", "item": tt.item, "field": "F1", "option": optionFor(tt.value)}, last.Variables)
+		})
+	}
+}
+
+// operation returns the first field selected by a GraphQL document, e.g.
+// "repositoryOwner".
+func operation(query string) string {
+	_, body, _ := strings.Cut(query, "{")
+	name, _, _ := strings.Cut(strings.TrimSpace(body), "(")
+	return name
+}
+
+// optionFor returns the ID of the option named value in the stubbed project.
+func optionFor(value string) string {
+	if strings.EqualFold(value, "Todo") {
+		return "O1"
+	}
+	return "O2"
+}
@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// PullRequestStatus is the state of a pull request as shown by gh-wt.
+type PullRequestStatus struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	// State is OPEN, CLOSED, or MERGED.
+	State string `json:"state"`
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED, or empty.
+	ReviewDecision string `json:"reviewDecision"`
+	// Checks is the combined status of the head commit: SUCCESS, FAILURE,
+	// PENDING, ERROR, EXPECTED, or empty when there are no checks.
+	Checks string `json:"checks"`
+}
+
+// PullRequestRef identifies a pull request.
+type PullRequestRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// pullRequestFields are the pull request fields selected by the status queries.
+const pullRequestFields = `number title url state reviewDecision
+      commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }`
+
+// statusBatchSize caps the pull requests fetched per query to stay within
+// GitHub's query complexity limits.
+const statusBatchSize = 100
+
+// pullRequestNode mirrors the pull request fields selected by the status queries.
+type pullRequestNode struct {
+	Number         int
+	Title          string
+	URL            string
+	State          string
+	ReviewDecision string
+	Commits        struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string
+				}
+			}
+		}
+	}
+}
+
+func (n pullRequestNode) status() PullRequestStatus {
+	s := PullRequestStatus{
+		Number:         n.Number,
+		Title:          n.Title,
+		URL:            n.URL,
+		State:          n.State,
+		ReviewDecision: n.ReviewDecision,
+	}
+	if len(n.Commits.Nodes) > 0 && n.Commits.Nodes[0].Commit.StatusCheckRollup != nil {
+		s.Checks = n.Commits.Nodes[0].Commit.StatusCheckRollup.State
+	}
+	return s
+}
+
+// GetPullRequestStatus fetches the state, review decision, and combined check
+// status of a pull request.
+func GetPullRequestStatus(ctx context.Context, owner, repo string, number int) (PullRequestStatus, error) {
+	ref := PullRequestRef{Owner: owner, Repo: repo, Number: number}
+	statuses, err := GetPullRequestStatuses(ctx, []PullRequestRef{ref})
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	status, ok := statuses[ref]
+	if !ok {
+		return PullRequestStatus{}, fmt.Errorf("PR #%d not found in %s/%s", number, owner, repo)
+	}
+	return status, nil
+}
+
+// GetPullRequestStatuses fetches the status of many pull requests with one
+// GraphQL query per 100 pull requests, using an alias for each repository and
+// pull request. Pull requests that do not exist are left out of the result.
+func GetPullRequestStatuses(ctx context.Context, refs []PullRequestRef) (map[PullRequestRef]PullRequestStatus, error) {
+	statuses := make(map[PullRequestRef]PullRequestStatus, len(refs))
+	if len(refs) == 0 {
+		return statuses, nil
+	}
+
+	client, err := NewGraphQLClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(refs); start += statusBatchSize {
+		batch := refs[start:min(start+statusBatchSize, len(refs))]
+		query, aliases := pullRequestStatusQuery(batch)
+
+		var resp map[string]map[string]*pullRequestNode
+		err := client.DoWithContext(ctx, query, nil, &resp)
+		if err != nil && !onlyNotFound(err) {
+			return nil, fmt.Errorf("failed to fetch PR statuses: %w", err)
+		}
+		for alias, ref := range aliases {
+			repoAlias, prAlias, _ := strings.Cut(alias, ".")
+			if node := resp[repoAlias][prAlias]; node != nil {
+				statuses[ref] = node.status()
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// ListOpenPullRequests returns up to 100 of the most recently updated open
+// pull requests of a repository, with only their number and title set.
+func ListOpenPullRequests(ctx context.Context, owner, repo string) ([]PullRequestStatus, error) {
+	client, err := NewRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	var prs []PullRequestStatus
+	path := fmt.Sprintf("repos/%s/%s/pulls?state=open&sort=updated&per_page=100", owner, repo)
+	if err := client.DoWithContext(ctx, "GET", path, nil, &prs); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	return prs, nil
+}
+
+// pullRequestStatusQuery builds a query selecting each pull request in refs.
+// It returns the query and the "repoAlias.prAlias" path of each ref.
+func pullRequestStatusQuery(refs []PullRequestRef) (string, map[string]PullRequestRef) {
+	type repoKey struct{ owner, repo string }
+	var order []repoKey
+	byRepo := make(map[repoKey][]PullRequestRef)
+	for _, ref := range refs {
+		key := repoKey{ref.Owner, ref.Repo}
+		if _, ok := byRepo[key]; !ok {
+			order = append(order, key)
+		}
+		byRepo[key] = append(byRepo[key], ref)
+	}
+
+	aliases := make(map[string]PullRequestRef, len(refs))
+	var q strings.Builder
+	q.WriteString("query {\n")
+	for i, key := range order {
+		repoAlias := fmt.Sprintf("r%d", i)
+		fmt.Fprintf(&q, "  %s: repository(owner: %s, name: %s) {\n", repoAlias, quote(key.owner), quote(key.repo))
+		for _, ref := range byRepo[key] {
+			prAlias := fmt.Sprintf("pr%d", ref.Number)
+			aliases[repoAlias+"."+prAlias] = ref
+			fmt.Fprintf(&q, "    %s: pullRequest(number: %d) { %s }\n", prAlias, ref.Number, pullRequestFields)
+		}
+		q.WriteString("  }\n")
+	}
+	q.WriteString("}")
+	return q.String(), aliases
+}
+
+// quote returns s as a GraphQL string literal.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// onlyNotFound reports whether err is a GraphQL error caused only by missing
+// repositories or pull requests. The rest of the response is still usable.
+func onlyNotFound(err error) bool {
+	var gqlErr *api.GraphQLError
+	if !errors.As(err, &gqlErr) {
+		return false
+	}
+	for _, e := range gqlErr.Errors {
+		if e.Type != "NOT_FOUND" {
+			return false
+		}
+	}
+	return true
+}
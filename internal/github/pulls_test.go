@@ -0,0 +1,39 @@
+package github
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestStatusQuery(t *testing.T) {
+	refs := []PullRequestRef{
+		{Owner: "octo", Repo: "cli", Number: 12},
+		{Owner: "other", Repo: "tool", Number: 3},
+		{Owner: "octo", Repo: "cli", Number: 40},
+	}
+
+	query, aliases := pullRequestStatusQuery(refs)
+
+	assert.Contains(t, query, `r0: repository(owner: "octo", name: "cli") {`)
+	assert.Contains(t, query, `r1: repository(owner: "other", name: "tool") {`)
+	assert.Contains(t, query, "pr12: pullRequest(number: 12)")
+	assert.Contains(t, query, "pr40: pullRequest(number: 40)")
+	assert.Contains(t, query, "pr3: pullRequest(number: 3)")
+	assert.Equal(t, map[string]PullRequestRef{
+		"r0.pr12": refs[0],
+		"r0.pr40": refs[2],
+		"r1.pr3":  refs[1],
+	}, aliases)
+}
+
+func TestOnlyNotFound(t *testing.T) {
+	notFound := &api.GraphQLError{Errors: []api.GraphQLErrorItem{{Type: "NOT_FOUND"}}}
+	mixed := &api.GraphQLError{Errors: []api.GraphQLErrorItem{{Type: "NOT_FOUND"}, {Type: "FORBIDDEN"}}}
+
+	assert.True(t, onlyNotFound(notFound))
+	assert.False(t, onlyNotFound(mixed))
+	assert.False(t, onlyNotFound(errors.New("boom")))
+}
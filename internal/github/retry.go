@@ -0,0 +1,267 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/logger"
+)
+
+// log receives retry warnings. It is nil until SetLogger is called.
+var log *logger.Logger
+
+// SetLogger sets the logger used to report retried GitHub requests.
+func SetLogger(l *logger.Logger) {
+	log = l
+}
+
+// Retry policy for GitHub requests. Variables so tests can shorten them.
+var (
+	// maxAttempts is the number of times a request is tried.
+	maxAttempts = 4
+	// baseDelay is the first backoff delay for server errors; it doubles on
+	// each retry.
+	baseDelay = time.Second
+	// maxWait is the longest gh-wt waits for a rate limit to reset before
+	// giving up with a RateLimitError.
+	maxWait = time.Minute
+	// sleep waits for d or until ctx is done.
+	sleep = func(ctx context.Context, d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return nil
+		}
+	}
+	now = time.Now
+)
+
+// RateLimitError is returned when the GitHub API rate limit is exhausted and
+// does not reset soon enough to wait for it.
+type RateLimitError struct {
+	// Reset is when the limit resets. It is zero when unknown.
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return "GitHub API rate limit exceeded; try again later"
+	}
+	return fmt.Sprintf("GitHub API rate limit exceeded; it resets at %s (in %s)",
+		e.Reset.Local().Format("15:04:05"), e.Reset.Sub(now()).Round(time.Second))
+}
+
+// backoff returns the delay before retry number attempt (starting at 1).
+func backoff(attempt int) time.Duration {
+	return baseDelay << (attempt - 1)
+}
+
+// waitFor decides whether to wait d for a rate limit and retry. It returns a
+// RateLimitError when d is too long or no attempts are left.
+func waitFor(d time.Duration, attempt int) (time.Duration, bool, error) {
+	if d <= maxWait && attempt < maxAttempts {
+		return d, true, nil
+	}
+	return 0, false, &RateLimitError{Reset: now().Add(d)}
+}
+
+// retryDelay decides whether an API response should be retried and after how
+// long. Server errors are retried with backoff. Rate limit responses are
+// retried once the limit resets, if that is within maxWait.
+func retryDelay(status int, header http.Header, attempt int) (time.Duration, bool, error) {
+	switch {
+	case status >= 500:
+		return backoff(attempt), attempt < maxAttempts, nil
+
+	case status == http.StatusForbidden || status == http.StatusTooManyRequests:
+		// Secondary rate limits send Retry-After.
+		if s := header.Get("Retry-After"); s != "" {
+			if secs, err := strconv.Atoi(s); err == nil {
+				return waitFor(time.Duration(secs)*time.Second, attempt)
+			}
+		}
+		// The primary rate limit is exhausted when no requests remain.
+		if header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, false, &RateLimitError{}
+			}
+			return waitFor(max(time.Unix(reset, 0).Sub(now()), 0)+time.Second, attempt)
+		}
+		if status == http.StatusTooManyRequests {
+			return backoff(attempt), attempt < maxAttempts, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// retryTransport retries GitHub API reads that fail with server errors or
+// rate limits. Mutations are sent once: a failed response does not mean they
+// had no effect, and sending them again could e.g. add an item twice.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	read := isRead(req)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, retry, err := retryDelay(resp.StatusCode, resp.Header, attempt)
+		if err != nil {
+			drain(resp)
+			return nil, err
+		}
+		if !retry || !read {
+			return resp, nil
+		}
+
+		drain(resp)
+		warnRetry(resp.Status, delay)
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRead reports whether req only reads: a GET or HEAD request, or a GraphQL
+// query whose body can be sent again.
+func isRead(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return req.Body == nil || req.GetBody != nil
+	case http.MethodPost:
+		if !strings.HasSuffix(req.URL.Path, "/graphql") || req.GetBody == nil {
+			return false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return false
+		}
+		defer body.Close()
+		var payload struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			return false
+		}
+		query := strings.TrimSpace(payload.Query)
+		return strings.HasPrefix(query, "query") || strings.HasPrefix(query, "{")
+	}
+	return false
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+func warnRetry(reason string, delay time.Duration) {
+	if log != nil {
+		log.Warnf("GitHub request failed (%s); retrying in %s...\n", reason, delay.Round(time.Second))
+	}
+}
+
+var (
+	// ghServerError matches gh errors for 5xx responses, e.g. "HTTP 502: Bad Gateway".
+	ghServerError = regexp.MustCompile(`HTTP 5\d\d`)
+	// ghSecondaryLimit matches gh errors for secondary rate limits.
+	ghSecondaryLimit = regexp.MustCompile(`(?i)secondary rate limit|submitted too quickly|abuse detection`)
+	// ghPrimaryLimit matches gh errors for an exhausted primary rate limit.
+	ghPrimaryLimit = regexp.MustCompile(`(?i)API rate limit (already )?exceeded`)
+)
+
+// RetryExec runs a gh command through run and retries it when gh reports a
+// server error or rate limit, judging by its stderr. Only use it for commands
+// that read; run commands that change anything once. When the primary rate
+// limit is exhausted and does not reset within a minute, it returns a
+// RateLimitError with the reset time instead of gh's error text.
+func RetryExec(ctx context.Context, run func() (stdout, stderr bytes.Buffer, err error)) (stdout, stderr bytes.Buffer, err error) {
+	for attempt := 1; ; attempt++ {
+		stdout, stderr, err = run()
+		if err == nil {
+			return stdout, stderr, nil
+		}
+
+		var delay time.Duration
+		var retry bool
+		msg := stderr.String()
+		switch {
+		case ghPrimaryLimit.MatchString(msg):
+			reset, resetErr := rateLimitReset(ctx)
+			if resetErr != nil {
+				return stdout, bytes.Buffer{}, &RateLimitError{}
+			}
+			delay, retry, err = waitFor(max(reset.Sub(now()), 0)+time.Second, attempt)
+			if err != nil {
+				return stdout, bytes.Buffer{}, err
+			}
+		case ghSecondaryLimit.MatchString(msg), ghServerError.MatchString(msg):
+			delay, retry = backoff(attempt), attempt < maxAttempts
+		}
+		if !retry {
+			return stdout, stderr, err
+		}
+
+		warnRetry(strings.TrimSpace(firstLine(msg)), delay)
+		if err := sleep(ctx, delay); err != nil {
+			return stdout, stderr, err
+		}
+	}
+}
+
+// rateLimitReset returns when the exhausted REST or GraphQL rate limit resets.
+// Querying the rate limit does not count against it.
+func rateLimitReset(ctx context.Context) (time.Time, error) {
+	client, err := NewRESTClient()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var resp struct {
+		Resources map[string]struct {
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := client.DoWithContext(ctx, http.MethodGet, "rate_limit", nil, &resp); err != nil {
+		return time.Time{}, err
+	}
+	var reset time.Time
+	for _, r := range resp.Resources {
+		if t := time.Unix(r.Reset, 0); r.Remaining == 0 && t.After(reset) {
+			reset = t
+		}
+	}
+	if reset.IsZero() {
+		return time.Time{}, fmt.Errorf("no exhausted rate limit found")
+	}
+	return reset, nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
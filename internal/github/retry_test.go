@@ -0,0 +1,199 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noSleep records requested delays instead of sleeping.
+func noSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	orig := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &delays
+}
+
+func TestRetryDelay(t *testing.T) {
+	fixed := time.Unix(1_700_000_000, 0)
+	orig := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = orig })
+
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	tests := []struct {
+		name      string
+		status    int
+		header    http.Header
+		attempt   int
+		delay     time.Duration
+		retry     bool
+		rateLimit bool
+	}{
+		{name: "ok", status: 200, header: header(), attempt: 1},
+		{name: "server error backs off", status: 502, header: header(), attempt: 2, delay: 2 * time.Second, retry: true},
+		{name: "server error gives up", status: 503, header: header(), attempt: maxAttempts, delay: 8 * time.Second},
+		{name: "forbidden without limit", status: 403, header: header(), attempt: 1},
+		{name: "secondary limit", status: 403, header: header("Retry-After", "5"), attempt: 1, delay: 5 * time.Second, retry: true},
+		{
+			name:    "primary limit resets soon",
+			status:  403,
+			header:  header("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(fixed.Add(10*time.Second).Unix(), 10)),
+			attempt: 1, delay: 11 * time.Second, retry: true,
+		},
+		{
+			name:      "primary limit resets later",
+			status:    403,
+			header:    header("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(fixed.Add(time.Hour).Unix(), 10)),
+			attempt:   1,
+			rateLimit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry, err := retryDelay(tt.status, tt.header, tt.attempt)
+			if tt.rateLimit {
+				var rl *RateLimitError
+				require.ErrorAs(t, err, &rl)
+				assert.Contains(t, rl.Error(), "resets at")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.retry, retry)
+			if tt.retry {
+				assert.Equal(t, tt.delay, delay)
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	delays := noSleep(t)
+
+	var bodies []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	const query = `{"query":"query { viewer { login } }"}`
+	client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport}}
+	resp, err := client.Post(srv.URL+"/graphql", "application/json", strings.NewReader(query))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{query, query, query}, bodies, "request body is replayed on retry")
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *delays)
+}
+
+func TestRetryTransportSendsMutationsOnce(t *testing.T) {
+	delays := noSleep(t)
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport}}
+	resp, err := client.Post(srv.URL+"/graphql", "application/json", strings.NewReader(`{"query":"mutation { addComment }"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *delays)
+}
+
+func TestIsRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		read   bool
+	}{
+		{name: "GET", method: http.MethodGet, path: "/repos/o/r", read: true},
+		{name: "HEAD", method: http.MethodHead, path: "/repos/o/r", read: true},
+		{name: "query", method: http.MethodPost, path: "/graphql", body: `{"query":"query($n: Int!) { x }"}`, read: true},
+		{name: "anonymous query", method: http.MethodPost, path: "/graphql", body: `{"query":" { viewer { login } }"}`, read: true},
+		{name: "mutation", method: http.MethodPost, path: "/graphql", body: `{"query":"\nmutation($p: ID!) { x }"}`},
+		{name: "invalid body", method: http.MethodPost, path: "/graphql", body: `query`},
+		{name: "REST POST", method: http.MethodPost, path: "/repos/o/r/issues/1/comments", body: `{"body":"hi"}`},
+		{name: "PATCH", method: http.MethodPatch, path: "/repos/o/r/issues/1", body: `{}`},
+		{name: "DELETE", method: http.MethodDelete, path: "/repos/o/r/git/refs/heads/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, "https://api.github.com"+tt.path, body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.read, isRead(req))
+		})
+	}
+}
+
+func TestRetryExec(t *testing.T) {
+	delays := noSleep(t)
+
+	attempts := 0
+	stdout, _, err := RetryExec(context.Background(), func() (stdout, stderr bytes.Buffer, err error) {
+		attempts++
+		if attempts == 1 {
+			stderr.WriteString("HTTP 502: Bad Gateway (https://api.github.com/graphql)\n")
+			return stdout, stderr, errors.New("exit status 1")
+		}
+		stdout.WriteString("done")
+		return stdout, stderr, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "done", stdout.String())
+	assert.Equal(t, []time.Duration{time.Second}, *delays)
+
+	attempts = 0
+	_, stderr, err := RetryExec(context.Background(), func() (stdout, stderr bytes.Buffer, err error) {
+		attempts++
+		stderr.WriteString("GraphQL: Could not resolve to a PullRequest with the number of 9.\n")
+		return stdout, stderr, errors.New("exit status 1")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts, "other errors are not retried")
+	assert.Contains(t, stderr.String(), "Could not resolve")
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReviewContext is what a reviewer needs to know about a pull request: its
+// description, the files it changes, and its unresolved review threads.
+type ReviewContext struct {
+	Number  int
+	Title   string
+	URL     string
+	Body    string
+	Files   []ChangedFile
+	Threads []ReviewThread
+}
+
+// ChangedFile is a file changed by a pull request.
+type ChangedFile struct {
+	Path      string
+	Additions int
+	Deletions int
+}
+
+// ReviewThread is an unresolved review thread on a pull request.
+type ReviewThread struct {
+	Path string
+	// Line is the line the thread is on, or 0 when it is outdated.
+	Line     int
+	Comments []ReviewComment
+}
+
+// ReviewComment is a comment in a review thread.
+type ReviewComment struct {
+	Author string
+	Body   string
+}
+
+// reviewContextQuery selects the first 100 changed files and review threads
+// of a pull request, and the first 50 comments of each thread.
+const reviewContextQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      number title url body
+      files(first: 100) { nodes { path additions deletions } }
+      reviewThreads(first: 100) {
+        nodes {
+          isResolved path line
+          comments(first: 50) { nodes { author { login } body } }
+        }
+      }
+    }
+  }
+}`
+
+// reviewContextNode mirrors the pull request fields selected by
+// reviewContextQuery.
+type reviewContextNode struct {
+	Number int
+	Title  string
+	URL    string
+	Body   string
+	Files  struct {
+		Nodes []ChangedFile
+	}
+	ReviewThreads struct {
+		Nodes []struct {
+			IsResolved bool
+			Path       string
+			Line       int
+			Comments   struct {
+				Nodes []struct {
+					Author *struct{ Login string }
+					Body   string
+				}
+			}
+		}
+	}
+}
+
+func (n reviewContextNode) reviewContext() ReviewContext {
+	c := ReviewContext{
+		Number: n.Number,
+		Title:  n.Title,
+		URL:    n.URL,
+		Body:   n.Body,
+		Files:  n.Files.Nodes,
+	}
+	for _, t := range n.ReviewThreads.Nodes {
+		if t.IsResolved {
+			continue
+		}
+		thread := ReviewThread{Path: t.Path, Line: t.Line}
+		for _, comment := range t.Comments.Nodes {
+			author := "ghost"
+			if comment.Author != nil {
+				author = comment.Author.Login
+			}
+			thread.Comments = append(thread.Comments, ReviewComment{Author: author, Body: comment.Body})
+		}
+		c.Threads = append(c.Threads, thread)
+	}
+	return c
+}
+
+// GetReviewContext fetches the description, changed files, and unresolved
+// review threads of a pull request.
+func GetReviewContext(ctx context.Context, owner, repo string, number int) (ReviewContext, error) {
+	client, err := NewGraphQLClient()
+	if err != nil {
+		return ReviewContext{}, err
+	}
+	var resp struct {
+		Repository struct {
+			PullRequest *reviewContextNode
+		}
+	}
+	vars := map[string]any{"owner": owner, "repo": repo, "number": number}
+	if err := client.DoWithContext(ctx, reviewContextQuery, vars, &resp); err != nil {
+		return ReviewContext{}, fmt.Errorf("failed to fetch review context of PR #%d: %w", number, err)
+	}
+	if resp.Repository.PullRequest == nil {
+		return ReviewContext{}, fmt.Errorf("PR #%d not found in %s/%s", number, owner, repo)
+	}
+	return resp.Repository.PullRequest.reviewContext(), nil
+}
+
+// Markdown renders the review context as a Markdown document.
+func (c ReviewContext) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# PR #%d: %s\n\n%s\n", c.Number, c.Title, c.URL)
+	if body := strings.TrimSpace(c.Body); body != "" {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+
+	fmt.Fprintf(&b, "\n## Changed files (%d)\n\n", len(c.Files))
+	for _, f := range c.Files {
+		fmt.Fprintf(&b, "- %s (+%d -%d)\n", f.Path, f.Additions, f.Deletions)
+	}
+
+	fmt.Fprintf(&b, "\n## Unresolved review threads (%d)\n", len(c.Threads))
+	for _, t := range c.Threads {
+		location := t.Path
+		if t.Line > 0 {
+			location = fmt.Sprintf("%s:%d", t.Path, t.Line)
+		} else {
+			location += " (outdated)"
+		}
+		fmt.Fprintf(&b, "\n### %s\n", location)
+		for _, comment := range t.Comments {
+			fmt.Fprintf(&b, "\n**@%s**:\n%s\n", comment.Author, strings.TrimSpace(comment.Body))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewContext(t *testing.T) {
+	var node reviewContextNode
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"number": 12, "title": "Fix login", "url": "https://github.com/octo/cli/pull/12", "body": "Fixes the redirect.\n",
+		"files": {"nodes": [{"path": "auth/login.go", "additions": 10, "deletions": 2}]},
+		"reviewThreads": {"nodes": [
+			{"isResolved": true, "path": "auth/login.go", "line": 3, "comments": {"nodes": [{"author": {"login": "amy"}, "body": "done"}]}},
+			{"isResolved": false, "path": "auth/login.go", "line": 42, "comments": {"nodes": [
+				{"author": {"login": "amy"}, "body": "Check the error here."},
+				{"author": null, "body": "Agreed"}
+			]}},
+			{"isResolved": false, "path": "README.md", "line": 0, "comments": {"nodes": [{"author": {"login": "bob"}, "body": "Typo"}]}}
+		]}
+	}`), &node))
+
+	rc := node.reviewContext()
+	require.Len(t, rc.Threads, 2)
+	assert.Equal(t, []ReviewComment{{Author: "amy", Body: "Check the error here."}, {Author: "ghost", Body: "Agreed"}}, rc.Threads[0].Comments)
+
+	assert.Equal(t, "# PR #12: Fix login\n\nhttps://github.com/octo/cli/pull/12\n\nFixes the redirect.\n"+
+		"\n## Changed files (1)\n\n- auth/login.go (+10 -2)\n"+
+		"\n## Unresolved review threads (2)\n"+
+		"\n### auth/login.go:42\n\n**@amy**:\nCheck the error here.\n\n**@ghost**:\nAgreed\n"+
+		"\n### README.md (outdated)\n\n**@bob**:\nTypo\n",
+		rc.Markdown())
+}
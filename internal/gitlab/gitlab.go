@@ -0,0 +1,140 @@
+// Package gitlab looks up GitLab merge requests and issues for gh-wt.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of GitLab references, named after their URL segment.
+const (
+	KindMergeRequest = "merge_requests"
+	KindIssue        = "issues"
+)
+
+// Ref identifies a merge request or issue in a GitLab project.
+type Ref struct {
+	Host string
+	// Project is the full project path, e.g. group/subgroup/project.
+	Project string
+	Kind    string
+	Number  int
+}
+
+// refPath matches the path of merge request and issue URLs, e.g.
+// /group/project/-/merge_requests/12.
+var refPath = regexp.MustCompile(`^/(.+?)/-/(merge_requests|issues)/(\d+)(?:/.*)?$`)
+
+// ParseURL parses a GitLab merge request or issue URL.
+func ParseURL(u *url.URL) (Ref, bool) {
+	m := refPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return Ref{}, false
+	}
+	number, err := strconv.Atoi(m[3])
+	if err != nil {
+		return Ref{}, false
+	}
+	return Ref{Host: u.Hostname(), Project: m[1], Kind: m[2], Number: number}, true
+}
+
+// scpRemote matches scp-like remote URLs, e.g. git@gitlab.com:group/project.git.
+var scpRemote = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemote returns the host and project path of a git remote URL.
+func ParseRemote(remote string) (host, project string, ok bool) {
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, project = u.Hostname(), u.Path
+	} else if m := scpRemote.FindStringSubmatch(remote); m != nil {
+		host, project = m[1], m[2]
+	} else {
+		return "", "", false
+	}
+	project = strings.TrimSuffix(strings.Trim(project, "/"), ".git")
+	return host, project, project != ""
+}
+
+// MergeRequest is the merge request information needed to create its worktree.
+type MergeRequest struct {
+	IID             int    `json:"iid"`
+	Title           string `json:"title"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SourceProjectID int    `json:"source_project_id"`
+	TargetProjectID int    `json:"target_project_id"`
+}
+
+// IsCrossProject reports whether the source branch lives in a fork.
+func (mr MergeRequest) IsCrossProject() bool {
+	return mr.SourceProjectID != mr.TargetProjectID
+}
+
+// Issue is the issue information needed to create its worktree.
+type Issue struct {
+	IID   int    `json:"iid"`
+	Title string `json:"title"`
+}
+
+// Client is a minimal GitLab REST API client.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for the GitLab instance on host. Requests are
+// authenticated with GITLAB_TOKEN when it is set; public projects work without it.
+func NewClient(host string) *Client {
+	return &Client{
+		baseURL: "https://" + host + "/api/v4",
+		token:   os.Getenv("GITLAB_TOKEN"),
+		http:    http.DefaultClient,
+	}
+}
+
+// MergeRequest fetches merge request iid of project.
+func (c *Client) MergeRequest(ctx context.Context, project string, iid int) (MergeRequest, error) {
+	var mr MergeRequest
+	err := c.get(ctx, fmt.Sprintf("projects/%s/merge_requests/%d", url.PathEscape(project), iid), &mr)
+	return mr, err
+}
+
+// Issue fetches issue iid of project.
+func (c *Client) Issue(ctx context.Context, project string, iid int) (Issue, error) {
+	var issue Issue
+	err := c.get(ctx, fmt.Sprintf("projects/%s/issues/%d", url.PathEscape(project), iid), &issue)
+	return issue, err
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitLab request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitLab request failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   Ref
+		wantOK bool
+	}{
+		{
+			input:  "https://gitlab.com/group/sub/project/-/merge_requests/12",
+			want:   Ref{Host: "gitlab.com", Project: "group/sub/project", Kind: KindMergeRequest, Number: 12},
+			wantOK: true,
+		},
+		{
+			input:  "https://git.example.com/team/app/-/issues/7/designs",
+			want:   Ref{Host: "git.example.com", Project: "team/app", Kind: KindIssue, Number: 7},
+			wantOK: true,
+		},
+		{input: "https://github.com/o/r/pull/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			u, err := url.Parse(tt.input)
+			require.NoError(t, err)
+			got, ok := ParseURL(u)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		remote  string
+		host    string
+		project string
+	}{
+		{remote: "https://gitlab.com/group/project.git", host: "gitlab.com", project: "group/project"},
+		{remote: "git@gitlab.com:group/sub/project.git", host: "gitlab.com", project: "group/sub/project"},
+		{remote: "ssh://git@git.example.com:2222/team/app", host: "git.example.com", project: "team/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			host, project, ok := ParseRemote(tt.remote)
+			assert.True(t, ok)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.project, project)
+		})
+	}
+}
+
+func TestClientMergeRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/merge_requests/12" {
+			http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		_, _ = w.Write([]byte(`{"iid":12,"title":"Fix login","source_branch":"fix-login","source_project_id":2,"target_project_id":1}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL + "/api/v4", token: "secret", http: srv.Client()}
+	mr, err := c.MergeRequest(context.Background(), "group/project", 12)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix login", mr.Title)
+	assert.Equal(t, "fix-login", mr.SourceBranch)
+	assert.True(t, mr.IsCrossProject())
+
+	_, err = c.Issue(context.Background(), "group/project", 1)
+	assert.ErrorContains(t, err, "404 Not Found")
+}
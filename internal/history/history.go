@@ -0,0 +1,108 @@
+// Package history keeps an append-only log of the operations gh-wt performs,
+// such as creating and removing worktrees, for gh wt log.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/config"
+)
+
+// FileName is the name of the history log inside the state directory.
+const FileName = "history.jsonl"
+
+// Operations recorded in the history.
+const (
+	OpAdd    = "add"
+	OpRemove = "rm"
+	OpAdopt  = "adopt"
+)
+
+// Event is one operation in the history.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Op is the operation, e.g. OpAdd.
+	Op string `json:"op"`
+	// Repo is the repository the worktree belongs to.
+	Repo string `json:"repo,omitempty"`
+	// Worktree is the path of the worktree.
+	Worktree string `json:"worktree"`
+	Branch   string `json:"branch,omitempty"`
+	// Detail describes the operation, e.g. "PR #12".
+	Detail string `json:"detail,omitempty"`
+}
+
+// Path returns the location of the history log.
+func Path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Append adds e to the history, setting its time to now if unset. Each event
+// is one line written with a single append, so concurrent gh-wt processes
+// don't interleave events.
+func Append(e Event) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history event: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Read returns all events in the order they were recorded. A missing log
+// yields no events; lines that cannot be parsed are skipped.
+func Read() ([]Event, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,37 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	events, err := Read()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, Append(Event{Time: created, Op: OpAdd, Repo: "repo", Worktree: "/wt/repo/pr_12", Branch: "fix", Detail: "PR #12"}))
+	require.NoError(t, Append(Event{Op: OpRemove, Repo: "repo", Worktree: "/wt/repo/pr_12"}))
+
+	path, err := Path()
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	events, err = Read()
+	require.NoError(t, err)
+	require.Len(t, events, 2, "unparseable lines are skipped")
+	assert.Equal(t, Event{Time: created, Op: OpAdd, Repo: "repo", Worktree: "/wt/repo/pr_12", Branch: "fix", Detail: "PR #12"}, events[0])
+	assert.Equal(t, OpRemove, events[1].Op)
+	assert.False(t, events[1].Time.IsZero(), "time defaults to now")
+}
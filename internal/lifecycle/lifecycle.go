@@ -0,0 +1,152 @@
+// Package lifecycle creates and removes worktrees with the safeguards gh wt
+// applies, for the gh wt commands and the pkg/wt API alike: default branches
+// and pinned worktrees are not deleted to make room for a new worktree, and
+// worktrees are forgotten from the worktree metadata and port registry when
+// they are removed. Callers hold the lock of the repository's worktree
+// directory (see internal/lock) around these calls, and pass the git.Client
+// to run git through: git.Default() for the gh wt commands, the Manager's own
+// for pkg/wt.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/ports"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+var (
+	// ErrProtectedBranch is returned when making room for a worktree would
+	// delete a default branch.
+	ErrProtectedBranch = errors.New("branch is a default branch")
+	// ErrPinned is returned when making room for a worktree would remove a
+	// pinned worktree.
+	ErrPinned = errors.New("worktree is pinned")
+)
+
+// Conflicts is what is in the way of a new worktree.
+type Conflicts struct {
+	// DirExists is set when the worktree directory exists.
+	DirExists bool
+	// Registered is set when git has a worktree registered at the path.
+	Registered bool
+	// BranchExists is set when the branch to create exists.
+	BranchExists bool
+}
+
+// Any reports whether anything is in the way.
+func (c Conflicts) Any() bool {
+	return c.DirExists || c.Registered || c.BranchExists
+}
+
+// FindConflicts returns what is in the way of a new worktree at path on a new
+// branch.
+func FindConflicts(ctx context.Context, g *git.Client, path, branch string) Conflicts {
+	return Conflicts{
+		DirExists:    worktree.Exists(path),
+		Registered:   g.WorktreeIsRegistered(ctx, path),
+		BranchExists: g.BranchExists(ctx, branch),
+	}
+}
+
+// CheckOverwrite refuses to clear c, the conflicts of a worktree at path on
+// branch, when that deletes a default branch or a pinned worktree, unless
+// force is set.
+func CheckOverwrite(ctx context.Context, g *git.Client, path, branch string, c Conflicts, force bool) error {
+	if c.BranchExists && !DeletableBranch(ctx, g, branch, force) {
+		return fmt.Errorf("%w: %s", ErrProtectedBranch, branch)
+	}
+	if c.DirExists && !force {
+		return CheckNotPinned(path)
+	}
+	return nil
+}
+
+// CheckNotPinned returns ErrPinned when the worktree at path is pinned.
+// Unreadable metadata pins nothing.
+func CheckNotPinned(path string) error {
+	store, err := metadata.Load()
+	if err != nil {
+		return nil
+	}
+	if e, _ := store.Get(path); e.Pinned {
+		return fmt.Errorf("%w: %s", ErrPinned, path)
+	}
+	return nil
+}
+
+// DeletableBranch reports whether branch is deleted along with its worktree:
+// any branch but a default one, unless force is set.
+func DeletableBranch(ctx context.Context, g *git.Client, branch string, force bool) bool {
+	return branch != "" && (force || !g.IsProtectedBranch(ctx, branch))
+}
+
+// Clear removes the conflicts c in the way of a worktree at path on branch.
+// Check them with CheckOverwrite first.
+func Clear(ctx context.Context, g *git.Client, path, branch string, c Conflicts) error {
+	if c.DirExists && c.Registered {
+		if err := g.WorktreeRemove(ctx, path, true); err != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+	} else if c.DirExists {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove directory: %w", err)
+		}
+	} else if c.Registered {
+		if err := g.WorktreePrune(ctx); err != nil {
+			return fmt.Errorf("failed to prune worktree: %w", err)
+		}
+	}
+
+	if c.BranchExists {
+		if err := g.BranchDelete(ctx, branch, true); err != nil {
+			return fmt.Errorf("failed to delete branch: %w", err)
+		}
+	}
+	return nil
+}
+
+// Add creates the worktree at path on a new branch from startPoint. A
+// directory left behind by a failed git worktree add is removed.
+func Add(ctx context.Context, g *git.Client, path, branch, startPoint string) error {
+	if err := worktree.Create(ctx, g, path, branch, startPoint); err != nil {
+		if worktree.Exists(path) {
+			os.RemoveAll(path)
+		}
+		return err
+	}
+	return nil
+}
+
+// AssignPorts assigns the worktree at path, once created, its port block.
+// Assigning it along with the worktree, rather than when an action first
+// needs it, keeps blocks in creation order and out of the way of actions
+// running in parallel.
+func AssignPorts(path string) (int, error) {
+	// Unset config falls back to the default port range.
+	cfg, _ := config.Get()
+	port, err := ports.Assign(path, cfg.Ports)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign ports: %w", err)
+	}
+	return port, nil
+}
+
+// Forget drops the worktree at path, once removed, from the worktree metadata
+// and releases its port block.
+func Forget(path string) error {
+	var errs []error
+	if err := metadata.Forget(path); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update worktree metadata: %w", err))
+	}
+	if err := ports.Release(path); err != nil {
+		errs = append(errs, fmt.Errorf("failed to release worktree ports: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+// Package lock provides cross-process lock files, so concurrent gh-wt
+// invocations don't interleave git worktree commands on the same repository.
+package lock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// FileName is the name of a repository's lock file inside its worktree
+// directory. The leading dot keeps it out of worktree listings.
+const FileName = ".gh-wt.lock"
+
+// takeoverSuffix is appended to a lock's path to name the file guarding its
+// takeover. Only the process holding the guard replaces an abandoned lock.
+const takeoverSuffix = ".takeover"
+
+// guardStaleAfter is how old a takeover guard must be before it is considered
+// abandoned. A guard is only held for a read and a rename.
+const guardStaleAfter = 10 * time.Second
+
+// pollInterval is how often a waiting Acquire retries.
+const pollInterval = 200 * time.Millisecond
+
+// Holder describes the process holding a lock.
+type Holder struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+func (h Holder) String() string {
+	return fmt.Sprintf("'%s' (pid %d on %s) since %s", h.Command, h.PID, h.Host, h.Since.Local().Format(time.TimeOnly))
+}
+
+// Lock is a held lock file.
+type Lock struct {
+	path     string
+	released bool
+}
+
+// Acquire creates the lock file at path, recording command as its holder. If
+// another live process holds the lock, Acquire calls waiting once with the
+// holder and retries until the lock is free or ctx is done.
+//
+// Locks left by processes of this host that are no longer running are taken
+// over atomically: the new holder is renamed over the abandoned lock, so two
+// processes can never both take it. Locks held from another host are waited
+// for however old they are, since their holder may still be running.
+func Acquire(ctx context.Context, path, command string, waiting func(Holder)) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create lock directory: %w", err)
+	}
+	host, _ := os.Hostname()
+	self, err := json.Marshal(Holder{PID: os.Getpid(), Host: host, Command: command, Since: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	notified := false
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, werr := f.Write(self)
+			cerr := f.Close()
+			if err := errors.Join(werr, cerr); err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock %s: %w", path, err)
+			}
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock %s: %w", path, err)
+		}
+
+		data, holder, ok := readHolder(path)
+		if !ok || abandoned(holder, host) {
+			took, err := takeOver(path, data, self)
+			if err != nil {
+				return nil, err
+			}
+			if took {
+				return &Lock{path: path}, nil
+			}
+			continue
+		}
+		if !notified && waiting != nil {
+			waiting(holder)
+			notified = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for lock held by %s; if it is no longer running, remove %s: %w", holder, path, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Release removes the lock file. Releasing a lock more than once is a no-op.
+func (l *Lock) Release() error {
+	if l == nil || l.released {
+		return nil
+	}
+	l.released = true
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to release lock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// takeOver replaces the abandoned lock at path, whose content was seen, with
+// self. It reports false, without error, when another process changed the
+// lock in the meantime or is taking it over.
+func takeOver(path string, seen, self []byte) (bool, error) {
+	guard := path + takeoverSuffix
+	g, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > guardStaleAfter {
+			os.Remove(guard)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to take over lock %s: %w", path, err)
+	}
+	g.Close()
+	defer os.Remove(guard)
+
+	// Fresh locks are only created where there is none, and abandoned ones
+	// are only replaced under the guard, so the lock is still abandoned if it
+	// did not change since it was read.
+	if current, err := os.ReadFile(path); err != nil || !bytes.Equal(current, seen) {
+		return false, nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, fmt.Errorf("failed to take over lock %s: %w", path, err)
+	}
+	_, werr := tmp.Write(self)
+	if err := errors.Join(werr, tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return false, fmt.Errorf("failed to take over lock %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return false, fmt.Errorf("failed to take over lock %s: %w", path, err)
+	}
+	current, err := os.ReadFile(path)
+	return err == nil && bytes.Equal(current, self), nil
+}
+
+// readHolder reads the holder of the lock at path, along with the raw content
+// of the file. It reports false for lock files that are missing or
+// unreadable.
+func readHolder(path string) ([]byte, Holder, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Holder{}, false
+	}
+	var h Holder
+	if err := json.Unmarshal(data, &h); err != nil {
+		// The holder may be between creating and writing the file; only treat
+		// it as abandoned once it is old.
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) > time.Second {
+			return data, Holder{}, false
+		}
+		return data, Holder{Command: "gh wt", Since: info.ModTime()}, true
+	}
+	return data, h, true
+}
+
+// abandoned reports whether h no longer holds its lock: its process ran on
+// this host and has exited.
+func abandoned(h Holder, host string) bool {
+	return h.Host == host && h.PID != 0 && !processRunning(h.PID)
+}
+
+// processRunning reports whether a process with pid is running on this host.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess fails on Windows when there is no such process.
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,125 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireWaitsForHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo", FileName)
+
+	l, err := Acquire(context.Background(), path, "gh wt add a", nil)
+	require.NoError(t, err)
+
+	var waitedOn []Holder
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = Acquire(ctx, path, "gh wt add b", func(h Holder) { waitedOn = append(waitedOn, h) })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "gh wt add a")
+	require.Len(t, waitedOn, 1, "waiting is called once")
+	assert.Equal(t, os.Getpid(), waitedOn[0].PID)
+
+	require.NoError(t, l.Release())
+	require.NoError(t, l.Release(), "release is idempotent")
+
+	l, err = Acquire(context.Background(), path, "gh wt add b", nil)
+	require.NoError(t, err)
+	require.NoError(t, l.Release())
+}
+
+// exitedPID returns the PID of a process that has exited.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	exited := exec.Command("true")
+	require.NoError(t, exited.Run())
+	return exited.Process.Pid
+}
+
+func TestAcquireTakesOverAbandonedLocks(t *testing.T) {
+	host, _ := os.Hostname()
+	for name, content := range map[string][]byte{
+		"holder exited": mustMarshal(t, Holder{PID: exitedPID(t), Host: host, Command: "gh wt rm", Since: time.Now()}),
+		"empty file":    nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			require.NoError(t, os.WriteFile(path, content, 0o644))
+			old := time.Now().Add(-time.Minute)
+			require.NoError(t, os.Chtimes(path, old, old))
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			l, err := Acquire(ctx, path, "gh wt add", func(Holder) { t.Error("should not wait on an abandoned lock") })
+			require.NoError(t, err)
+			_, holder, ok := readHolder(path)
+			require.True(t, ok)
+			assert.Equal(t, "gh wt add", holder.Command)
+			require.NoError(t, l.Release())
+			assert.NoFileExists(t, path+takeoverSuffix)
+		})
+	}
+}
+
+func TestAcquireWaitsForOldLiveHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	holder := Holder{PID: os.Getpid(), Host: "elsewhere", Command: "gh wt rm", Since: time.Now().Add(-time.Hour)}
+	require.NoError(t, os.WriteFile(path, mustMarshal(t, holder), 0o644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err := Acquire(ctx, path, "gh wt add", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a lock is not taken over for its age")
+	assert.ErrorContains(t, err, "remove "+path)
+}
+
+func TestAcquireTakeOverIsExclusive(t *testing.T) {
+	host, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), FileName)
+	abandonedHolder := Holder{PID: exitedPID(t), Host: host, Command: "gh wt rm", Since: time.Now()}
+	require.NoError(t, os.WriteFile(path, mustMarshal(t, abandonedHolder), 0o644))
+
+	var holding, maxHolding atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			l, err := Acquire(ctx, path, "gh wt add", nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			n := holding.Add(1)
+			for {
+				m := maxHolding.Load()
+				if n <= m || maxHolding.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			holding.Add(-1)
+			assert.NoError(t, l.Release())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), maxHolding.Load(), "one holder at a time")
+}
+
+func mustMarshal(t *testing.T, h Holder) []byte {
+	t.Helper()
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	return data
+}
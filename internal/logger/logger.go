@@ -1,11 +1,41 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 )
 
+// Log formats.
+const (
+	// FormatText prints human-readable, optionally colored, output (default).
+	FormatText = "text"
+	// FormatJSON prints one JSON Event per line.
+	FormatJSON = "json"
+)
+
+// Event levels.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Event is a structured log line written in FormatJSON.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Command  string    `json:"command,omitempty"`
+	Worktree string    `json:"worktree,omitempty"`
+	Message  string    `json:"message"`
+	// DurationMs is the time since the command started, in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+}
+
 type (
 	Color     func() PrintFunc
 	PrintFunc func(io.Writer, string, ...any)
@@ -55,20 +85,37 @@ func colorPrint(code string) PrintFunc {
 
 // Logger is a wrapper that prints stuff to STDOUT or STDERR,
 // with optional color and verbosity.
+//
+// Verbose enables additional user-facing detail. Debug enables internal
+// tracing (subprocess command lines, timings) which is always written
+// to STDERR so it never mixes with regular output.
+//
+// With Format set to FormatJSON, every message is written as an Event tagged
+// with Command and Worktree instead, so wrapper tools can parse the output.
 type Logger struct {
 	Stdout  io.Writer
 	Stderr  io.Writer
 	Verbose bool
+	Debug   bool
 	Color   bool
+	// Format is FormatText or FormatJSON. Empty means FormatText.
+	Format string
+	// Command and Worktree tag JSON events.
+	Command  string
+	Worktree string
+
+	start time.Time
 }
 
 // NewLogger creates a new Logger instance.
-func NewLogger(verbose, useColor bool) *Logger {
+func NewLogger(verbose, debug, useColor bool) *Logger {
 	return &Logger{
 		Stdout:  os.Stdout,
 		Stderr:  os.Stderr,
 		Verbose: verbose,
+		Debug:   debug,
 		Color:   useColor,
+		start:   time.Now(),
 	}
 }
 
@@ -79,9 +126,18 @@ func (l *Logger) Outf(c Color, s string, args ...any) {
 
 // FOutf prints stuff to the given writer.
 func (l *Logger) FOutf(w io.Writer, c Color, s string, args ...any) {
+	l.write(w, LevelInfo, c, s, args...)
+}
+
+// write prints a message at level to w, as text or as a JSON event.
+func (l *Logger) write(w io.Writer, level string, c Color, s string, args ...any) {
 	if len(args) == 0 {
 		s, args = "%s", []any{s}
 	}
+	if l.Format == FormatJSON {
+		l.writeEvent(w, level, fmt.Sprintf(s, args...))
+		return
+	}
 	if !l.Color {
 		c = None
 	}
@@ -89,6 +145,33 @@ func (l *Logger) FOutf(w io.Writer, c Color, s string, args ...any) {
 	print(w, s, args...)
 }
 
+// writeEvent writes msg as a JSON event. Blank messages, such as the spacing
+// lines of text output, are dropped.
+func (l *Logger) writeEvent(w io.Writer, level, msg string) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
+	}
+	now := time.Now()
+	var elapsed time.Duration
+	if !l.start.IsZero() {
+		elapsed = now.Sub(l.start)
+	}
+	data, err := json.Marshal(Event{
+		Time:       now,
+		Level:      level,
+		Command:    l.Command,
+		Worktree:   l.Worktree,
+		Message:    msg,
+		DurationMs: elapsed.Milliseconds(),
+	})
+	if err != nil {
+		return
+	}
+	// One write per event keeps concurrent events on separate lines.
+	w.Write(append(data, '\n'))
+}
+
 // VerboseOutf prints stuff to STDOUT if verbose mode is enabled.
 func (l *Logger) VerboseOutf(c Color, s string, args ...any) {
 	if l.Verbose {
@@ -98,14 +181,7 @@ func (l *Logger) VerboseOutf(c Color, s string, args ...any) {
 
 // Errf prints stuff to STDERR.
 func (l *Logger) Errf(c Color, s string, args ...any) {
-	if len(args) == 0 {
-		s, args = "%s", []any{s}
-	}
-	if !l.Color {
-		c = None
-	}
-	print := c()
-	print(l.Stderr, s, args...)
+	l.write(l.Stderr, LevelInfo, c, s, args...)
 }
 
 // VerboseErrf prints stuff to STDERR if verbose mode is enabled.
@@ -115,14 +191,26 @@ func (l *Logger) VerboseErrf(c Color, s string, args ...any) {
 	}
 }
 
+// Debugf prints an internal tracing message to STDERR if debug mode is enabled.
+func (l *Logger) Debugf(s string, args ...any) {
+	if l == nil || !l.Debug {
+		return
+	}
+	if l.Format == FormatJSON {
+		l.write(l.Stderr, LevelDebug, Blue, s, args...)
+		return
+	}
+	l.Errf(Blue, "[debug] "+s, args...)
+}
+
 // Warnf prints a warning message to STDERR.
 func (l *Logger) Warnf(s string, args ...any) {
-	l.Errf(Yellow, s, args...)
+	l.write(l.Stderr, LevelWarn, Yellow, s, args...)
 }
 
 // Errorf prints an error message to STDERR.
 func (l *Logger) Errorf(s string, args ...any) {
-	l.Errf(Red, s, args...)
+	l.write(l.Stderr, LevelError, Red, s, args...)
 }
 
 // Infof prints an informational message to STDOUT.
@@ -132,8 +220,5 @@ func (l *Logger) Infof(s string, args ...any) {
 
 // Plainf prints a plain message to STDOUT.
 func (l *Logger) Plainf(s string, args ...any) {
-	if len(args) == 0 {
-		s, args = "%s", []any{s}
-	}
-	fmt.Fprintf(l.Stdout, s, args...)
+	l.write(l.Stdout, LevelInfo, None, s, args...)
 }
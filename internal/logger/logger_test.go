@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	l := NewLogger(false, true, true)
+	l.Stdout, l.Stderr = &stdout, &stderr
+	l.Format = FormatJSON
+	l.Command = "add"
+	l.Worktree = "/wt/repo/pr_1"
+
+	l.Outf(Green, "\nWorktree created successfully!\n")
+	l.Plainf("  ")
+	l.Warnf("Failed to set upstream for branch '%s'\n", "fix")
+	l.Debugf("git fetch took %s\n", "1s")
+
+	var out Event
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	assert.Equal(t, LevelInfo, out.Level)
+	assert.Equal(t, "add", out.Command)
+	assert.Equal(t, "/wt/repo/pr_1", out.Worktree)
+	assert.Equal(t, "Worktree created successfully!", out.Message)
+
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	require.Len(t, lines, 2)
+	var warn, debug Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &warn))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &debug))
+	assert.Equal(t, LevelWarn, warn.Level)
+	assert.Equal(t, "Failed to set upstream for branch 'fix'", warn.Message)
+	assert.Equal(t, LevelDebug, debug.Level)
+	assert.Equal(t, "git fetch took 1s", debug.Message)
+}
+
+func TestTextFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	l := NewLogger(false, false, false)
+	l.Stdout = &stdout
+
+	l.Outf(Green, "created %s\n", "pr_1")
+	l.Plainf("100%")
+	assert.Equal(t, "created pr_1\n100%", stdout.String())
+}
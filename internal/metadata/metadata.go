@@ -0,0 +1,285 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/lock"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// FileName is the name of the metadata file inside the state directory.
+const FileName = "worktrees.json"
+
+// lockTimeout is how long Update waits for another gh wt process to finish
+// updating the store.
+const lockTimeout = 30 * time.Second
+
+// Entry records what gh-wt knows about a worktree it created.
+type Entry struct {
+	Path   string                `json:"path"`
+	Name   string                `json:"name"`
+	Branch string                `json:"branch"`
+	Type   worktree.WorktreeType `json:"type"`
+	Owner  string                `json:"owner,omitempty"`
+	Repo   string                `json:"repo"`
+	Number int                   `json:"number,omitempty"`
+	// Provider hosts the PR or issue, e.g. "gitlab". Empty means GitHub.
+	Provider string `json:"provider,omitempty"`
+	// Title is the PR or issue title when the worktree was created.
+	Title string `json:"title,omitempty"`
+	// Tags are user-defined labels, sorted and unique.
+	Tags []string `json:"tags,omitempty"`
+	// CreatedAt is when gh-wt created the worktree.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	// LastUsedAt is when a command last targeted the worktree.
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+	// UseCount is how many times the worktree was created or targeted.
+	UseCount int `json:"use_count,omitempty"`
+	// Adopted is set for worktrees created outside gh-wt and adopted with
+	// gh wt adopt. They are managed even outside the worktree directory.
+	Adopted bool `json:"adopted,omitempty"`
+	// SetupSkipped is set for worktrees created with gh wt add --no-verify,
+	// whose git hooks and post-create setup did not run.
+	SetupSkipped bool `json:"setup_skipped,omitempty"`
+	// Pinned is set with gh wt pin. Pinned worktrees are skipped by bulk
+	// operations and not removed without --force.
+	Pinned bool `json:"pinned,omitempty"`
+	// BaseBranch is the branch a PR merges into.
+	BaseBranch string `json:"base_branch,omitempty"`
+	// StackedOn is the number of the PR this one is stacked on, i.e. whose
+	// head branch is BaseBranch.
+	StackedOn int `json:"stacked_on,omitempty"`
+}
+
+// Frecency scores how likely the worktree is wanted at now, combining how
+// often and how recently it was used. Worktrees never used score 0.
+func (e Entry) Frecency(now time.Time) float64 {
+	last := e.LastUsedAt
+	if last.IsZero() {
+		last = e.CreatedAt
+	}
+	if last.IsZero() {
+		return 0
+	}
+
+	count := float64(max(e.UseCount, 1))
+	switch age := now.Sub(last); {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
+	}
+}
+
+// HasTags reports whether the entry has all of tags.
+func (e Entry) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddTags adds tags to the entry, keeping Tags sorted and unique.
+func (e *Entry) AddTags(tags ...string) {
+	e.Tags = append(e.Tags, tags...)
+	slices.Sort(e.Tags)
+	e.Tags = slices.Compact(e.Tags)
+}
+
+// RemoveTags removes tags from the entry.
+func (e *Entry) RemoveTags(tags ...string) {
+	e.Tags = slices.DeleteFunc(e.Tags, func(t string) bool {
+		return slices.Contains(tags, t)
+	})
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+	}
+}
+
+// Store is the on-disk collection of worktree entries, keyed by absolute path.
+type Store struct {
+	path    string
+	Entries map[string]Entry `json:"worktrees"`
+}
+
+// Path returns the location of the metadata file.
+func Path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the metadata store. A missing file yields an empty store.
+func Load() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, Entries: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata %s: %w", path, err)
+	}
+	if s.Entries == nil {
+		s.Entries = make(map[string]Entry)
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk, creating the state directory if needed.
+// Use Update to change the store.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	_, werr := tmp.Write(data)
+	if err := errors.Join(werr, tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Get returns the entry for the worktree at path.
+func (s *Store) Get(path string) (Entry, bool) {
+	e, ok := s.Entries[filepath.Clean(path)]
+	return e, ok
+}
+
+// Put adds or replaces the entry for e.Path.
+func (s *Store) Put(e Entry) {
+	e.Path = filepath.Clean(e.Path)
+	s.Entries[e.Path] = e
+}
+
+// Delete removes the entry for the worktree at path.
+func (s *Store) Delete(path string) {
+	delete(s.Entries, filepath.Clean(path))
+}
+
+// List returns all entries sorted by path.
+func (s *Store) List() []Entry {
+	entries := make([]Entry, 0, len(s.Entries))
+	for _, e := range s.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+// Update runs fn on the store and saves it when fn reports a change. It holds
+// the store's lock throughout, so that concurrent gh wt processes, e.g.
+// parallel adds, or a prompt touching a worktree during an add, never lose
+// each other's changes. Changes must go through Update rather than Load and
+// Save.
+func Update(fn func(s *Store) (changed bool, err error)) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	l, err := lock.Acquire(ctx, path+".lock", "metadata update", nil)
+	if err != nil {
+		return fmt.Errorf("failed to lock metadata: %w", err)
+	}
+	defer l.Release()
+
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+	changed, err := fn(s)
+	if err != nil || !changed {
+		return err
+	}
+	return s.Save()
+}
+
+// Record adds e to the store.
+func Record(e Entry) error {
+	return Update(func(s *Store) (bool, error) {
+		s.Put(e)
+		return true, nil
+	})
+}
+
+// Touch sets the last-used time of the worktree at path to now. Worktrees
+// without an entry get fallback as their entry.
+func Touch(path string, fallback Entry) error {
+	return Update(func(s *Store) (bool, error) {
+		e, ok := s.Get(path)
+		if !ok {
+			e = fallback
+			e.Path = path
+		}
+		e.LastUsedAt = time.Now()
+		e.UseCount++
+		s.Put(e)
+		return true, nil
+	})
+}
+
+// Move moves the entry for oldPath to newPath. It does nothing when oldPath
+// has no entry.
+func Move(oldPath, newPath string) error {
+	return Update(func(s *Store) (bool, error) {
+		e, ok := s.Get(oldPath)
+		if !ok {
+			return false, nil
+		}
+		s.Delete(oldPath)
+		e.Path = newPath
+		s.Put(e)
+		return true, nil
+	})
+}
+
+// Forget removes the entry for path.
+func Forget(path string) error {
+	return Update(func(s *Store) (bool, error) {
+		if _, ok := s.Get(path); !ok {
+			return false, nil
+		}
+		s.Delete(path)
+		return true, nil
+	})
+}
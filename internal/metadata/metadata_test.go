@@ -0,0 +1,146 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/worktree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, s.Entries)
+
+	entry := Entry{
+		Path:   "/base/repo/fix-auth",
+		Name:   "fix-auth",
+		Branch: "feature/auth-refactor",
+		Type:   worktree.Local,
+		Repo:   "repo",
+	}
+	require.NoError(t, Record(entry))
+
+	s, err = Load()
+	require.NoError(t, err)
+	got, ok := s.Get("/base/repo/fix-auth/")
+	require.True(t, ok)
+	assert.Equal(t, entry, got)
+
+	require.NoError(t, Forget(entry.Path))
+	s, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, s.List())
+}
+
+func TestEntryTags(t *testing.T) {
+	var e Entry
+	e.AddTags("urgent", "backend", "urgent")
+	assert.Equal(t, []string{"backend", "urgent"}, e.Tags)
+	assert.True(t, e.HasTags("urgent"))
+	assert.True(t, e.HasTags())
+	assert.False(t, e.HasTags("urgent", "frontend"))
+
+	e.RemoveTags("backend", "urgent")
+	assert.Nil(t, e.Tags)
+}
+
+func TestTouch(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, Record(Entry{Path: "/base/repo/a", Name: "a", CreatedAt: created}))
+	require.NoError(t, Touch("/base/repo/a", Entry{}))
+	require.NoError(t, Touch("/base/repo/b", Entry{Name: "b", Type: worktree.Local}))
+
+	s, err := Load()
+	require.NoError(t, err)
+	a, ok := s.Get("/base/repo/a")
+	require.True(t, ok)
+	assert.Equal(t, "a", a.Name)
+	assert.True(t, a.CreatedAt.Equal(created))
+	assert.False(t, a.LastUsedAt.IsZero())
+	assert.Equal(t, 1, a.UseCount)
+
+	b, ok := s.Get("/base/repo/b")
+	require.True(t, ok)
+	assert.Equal(t, "b", b.Name)
+	assert.True(t, b.CreatedAt.IsZero())
+	assert.False(t, b.LastUsedAt.IsZero())
+}
+
+func TestEntryJSONKeys(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := json.Marshal(Entry{
+		CreatedAt:    now,
+		LastUsedAt:   now,
+		UseCount:     2,
+		SetupSkipped: true,
+		BaseBranch:   "main",
+		StackedOn:    3,
+	})
+	require.NoError(t, err)
+
+	var keys map[string]any
+	require.NoError(t, json.Unmarshal(data, &keys))
+	for _, key := range []string{"created_at", "last_used_at", "use_count", "setup_skipped", "base_branch", "stacked_on"} {
+		assert.Contains(t, keys, key)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Record(Entry{Path: fmt.Sprintf("/base/repo/wt%d", i), Repo: "repo"}))
+			assert.NoError(t, Touch("/base/repo/shared", Entry{Repo: "repo"}))
+		}()
+	}
+	wg.Wait()
+
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Len(t, s.Entries, n+1, "no entry is lost")
+	shared, _ := s.Get("/base/repo/shared")
+	assert.Equal(t, n, shared.UseCount, "no touch is lost")
+}
+
+func TestMove(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	require.NoError(t, Record(Entry{Path: "/old/repo/a", Name: "a", Tags: []string{"x"}}))
+	require.NoError(t, Move("/old/repo/a", "/new/repo/a"))
+	require.NoError(t, Move("/old/repo/missing", "/new/repo/missing"))
+
+	s, err := Load()
+	require.NoError(t, err)
+	_, ok := s.Get("/old/repo/a")
+	assert.False(t, ok)
+	a, ok := s.Get("/new/repo/a")
+	require.True(t, ok)
+	assert.Equal(t, "/new/repo/a", a.Path)
+	assert.Equal(t, []string{"x"}, a.Tags)
+	assert.Len(t, s.List(), 1)
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Zero(t, Entry{}.Frecency(now))
+	assert.Equal(t, 4.0, Entry{CreatedAt: now.Add(-time.Minute)}.Frecency(now))
+	assert.Equal(t, 6.0, Entry{LastUsedAt: now.Add(-2 * time.Hour), UseCount: 3}.Frecency(now))
+	assert.Equal(t, 1.0, Entry{LastUsedAt: now.Add(-48 * time.Hour), UseCount: 2}.Frecency(now))
+	assert.Equal(t, 5.0, Entry{LastUsedAt: now.AddDate(0, -1, 0), UseCount: 20}.Frecency(now))
+}
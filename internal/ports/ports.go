@@ -0,0 +1,212 @@
+// Package ports assigns each worktree a block of TCP ports, so dev servers in
+// different worktrees don't fight over the same port.
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/ffalor/gh-wt/internal/lock"
+)
+
+// FileName is the name of the port registry inside the state directory.
+const FileName = "ports.json"
+
+// lockTimeout is how long Assign, Release, and Move wait for another gh wt
+// process to finish updating the registry.
+const lockTimeout = 30 * time.Second
+
+// Defaults used when the config leaves ports unset.
+const (
+	DefaultStart     = 4000
+	DefaultBlockSize = 10
+)
+
+// maxPort is the highest valid TCP port.
+const maxPort = 65535
+
+// Registry maps worktree paths to the first port of their block.
+type Registry struct {
+	path  string
+	Ports map[string]int `json:"ports"`
+}
+
+// Path returns the location of the port registry.
+func Path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the port registry. A missing file yields an empty registry.
+func Load() (*Registry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{path: path, Ports: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read port registry: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse port registry %s: %w", path, err)
+	}
+	if r.Ports == nil {
+		r.Ports = make(map[string]int)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to disk, creating the state directory if needed.
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode port registry: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write port registry: %w", err)
+	}
+	_, werr := tmp.Write(data)
+	if err := errors.Join(werr, tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write port registry: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write port registry: %w", err)
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// update runs fn on the registry and saves it when fn reports a change. It
+// holds the registry's lock throughout, so that concurrent gh wt processes,
+// e.g. actions started in parallel, never assign the same block twice or
+// lose each other's changes.
+func update(fn func(r *Registry) (changed bool, err error)) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	l, err := lock.Acquire(ctx, path+".lock", "port assignment", nil)
+	if err != nil {
+		return fmt.Errorf("failed to lock port registry: %w", err)
+	}
+	defer l.Release()
+
+	r, err := Load()
+	if err != nil {
+		return err
+	}
+	changed, err := fn(r)
+	if err != nil || !changed {
+		return err
+	}
+	return r.Save()
+}
+
+// Allocate returns the first port of the block assigned to the worktree at
+// path, assigning the lowest free block if it has none. Blocks of worktrees
+// whose directory no longer exists are freed first.
+func (r *Registry) Allocate(path string, cfg config.PortsConfig) (int, error) {
+	path = filepath.Clean(path)
+	if port, ok := r.Ports[path]; ok {
+		return port, nil
+	}
+
+	start, size := cfg.Start, cfg.BlockSize
+	if start <= 0 {
+		start = DefaultStart
+	}
+	if size <= 0 {
+		size = DefaultBlockSize
+	}
+
+	used := make(map[int]bool, len(r.Ports))
+	for p, port := range r.Ports {
+		if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) {
+			delete(r.Ports, p)
+			continue
+		}
+		used[port] = true
+	}
+	for port := start; port+size-1 <= maxPort; port += size {
+		if !used[port] {
+			r.Ports[path] = port
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free block of %d ports from %d", size, start)
+}
+
+// Lookup returns the first port of the block assigned to the worktree at
+// path, or 0 if it has none.
+func Lookup(path string) (int, error) {
+	r, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	return r.Ports[filepath.Clean(path)], nil
+}
+
+// Assign allocates a port block for the worktree at path in the registry,
+// unless it has one, and returns its first port.
+func Assign(path string, cfg config.PortsConfig) (int, error) {
+	var port int
+	err := update(func(r *Registry) (bool, error) {
+		if p, ok := r.Ports[filepath.Clean(path)]; ok {
+			port = p
+			return false, nil
+		}
+		var err error
+		port, err = r.Allocate(path, cfg)
+		return err == nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return port, nil
+}
+
+// Release frees the block of the worktree at path in the registry.
+func Release(path string) error {
+	return update(func(r *Registry) (bool, error) {
+		path = filepath.Clean(path)
+		if _, ok := r.Ports[path]; !ok {
+			return false, nil
+		}
+		delete(r.Ports, path)
+		return true, nil
+	})
+}
+
+// Move moves the block of the worktree at oldPath to newPath in the registry.
+func Move(oldPath, newPath string) error {
+	return update(func(r *Registry) (bool, error) {
+		port, ok := r.Ports[filepath.Clean(oldPath)]
+		if !ok {
+			return false, nil
+		}
+		delete(r.Ports, filepath.Clean(oldPath))
+		r.Ports[filepath.Clean(newPath)] = port
+		return true, nil
+	})
+}
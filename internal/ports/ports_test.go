@@ -0,0 +1,105 @@
+package ports
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssign(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	base := t.TempDir()
+	a := filepath.Join(base, "a")
+	b := filepath.Join(base, "b")
+	require.NoError(t, os.Mkdir(a, 0o755))
+	require.NoError(t, os.Mkdir(b, 0o755))
+	cfg := config.PortsConfig{Start: 3100, BlockSize: 100}
+
+	port, err := Assign(a, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port)
+
+	port, err = Assign(b, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3200, port)
+
+	port, err = Assign(a+"/", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port, "existing block is kept")
+
+	require.NoError(t, Release(a))
+	port, err = Assign(filepath.Join(base, "c"), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port, "released block is reused")
+}
+
+func TestAssignConcurrently(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	base := t.TempDir()
+	cfg := config.PortsConfig{Start: 6000, BlockSize: 10}
+
+	const n = 8
+	got := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		path := filepath.Join(base, fmt.Sprint(i))
+		require.NoError(t, os.Mkdir(path, 0o755))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			port, err := Assign(path, cfg)
+			assert.NoError(t, err)
+			got[i] = port
+		}()
+	}
+	wg.Wait()
+
+	r, err := Load()
+	require.NoError(t, err)
+	assert.Len(t, r.Ports, n, "no assignment is lost")
+	seen := map[int]bool{}
+	for _, port := range got {
+		assert.False(t, seen[port], "port %d assigned twice", port)
+		seen[port] = true
+	}
+}
+
+func TestMove(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	old := t.TempDir()
+	port, err := Assign(old, config.PortsConfig{Start: 5000, BlockSize: 10})
+	require.NoError(t, err)
+
+	moved := filepath.Join(t.TempDir(), "moved")
+	require.NoError(t, Move(old, moved))
+	r, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{moved: port}, r.Ports)
+}
+
+func TestAllocateFreesMissingWorktrees(t *testing.T) {
+	base := t.TempDir()
+	r := &Registry{Ports: map[string]int{
+		base:                        DefaultStart,
+		filepath.Join(base, "gone"): DefaultStart + DefaultBlockSize,
+	}}
+
+	port, err := r.Allocate(filepath.Join(base, "new"), config.PortsConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStart+DefaultBlockSize, port)
+	assert.NotContains(t, r.Ports, filepath.Join(base, "gone"))
+}
+
+func TestAllocateExhausted(t *testing.T) {
+	base := t.TempDir()
+	r := &Registry{Ports: map[string]int{base: 65000}}
+
+	_, err := r.Allocate(filepath.Join(base, "new"), config.PortsConfig{Start: 65000, BlockSize: 500})
+	assert.ErrorContains(t, err, "no free block")
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/ffalor/gh-wt/cmd"
+	"github.com/ffalor/gh-wt/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -31,6 +32,56 @@ func main() {
 	if err := genMarkdownTree(root, *out, *frontmatter); err != nil {
 		log.Fatal(err)
 	}
+	if err := genConfigReference(*out, *frontmatter); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// genConfigReference writes config_reference.mdx listing every config key,
+// its default, and its environment variable, and the environment variables
+// set in worktrees, so the docs match the code.
+func genConfigReference(outDir string, frontmatter bool) error {
+	keys, err := config.Keys()
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if frontmatter {
+		buf.WriteString("---\ntitle: \"config reference\"\nlayout: '../../../layouts/CLILayout.astro'\nslug: \"config_reference\"\ndescription: \"Config keys and environment variables of gh wt\"\n---\n\n")
+	}
+	buf.WriteString("## Config keys\n\n")
+	buf.WriteString("Scalar keys can also be set with their environment variable.\n\n")
+	buf.WriteString("| Key | Type | Default | Environment variable | Description |\n")
+	buf.WriteString("|-----|------|---------|----------------------|-------------|\n")
+	for _, k := range keys {
+		def := ""
+		if k.Default != nil {
+			def = fmt.Sprintf("`%v`", k.Default)
+		}
+		env := ""
+		if k.Env != "" {
+			env = "`" + k.Env + "`"
+		}
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n", k.Name, k.Type, def, env, escapeCell(k.Description))
+	}
+
+	buf.WriteString("\n## Environment variables set in worktrees\n\n")
+	buf.WriteString("| Variable | Description |\n")
+	buf.WriteString("|----------|-------------|\n")
+	for _, e := range config.WorktreeEnv {
+		fmt.Fprintf(buf, "| `%s` | %s |\n", e.Name, escapeCell(e.Description))
+	}
+
+	return os.WriteFile(filepath.Join(outDir, "config_reference.mdx"), buf.Bytes(), 0o644)
+}
+
+// escapeCell escapes text for a markdown table cell in MDX.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "{", "\\{")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	return s
 }
 
 // genMarkdownTree generates markdown docs for the command tree.
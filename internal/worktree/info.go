@@ -17,4 +17,13 @@ type WorktreeInfo struct {
 	Number       int
 	BranchName   string
 	WorktreeName string
+	// Provider hosts the PR or issue, e.g. "gitlab". Empty means GitHub.
+	Provider string
+	// Title is the PR or issue title, when known.
+	Title string
+	// BaseBranch is the branch a PR merges into, when known.
+	BaseBranch string
+	// StackedOn is the number of the PR whose head branch is BaseBranch, for
+	// a PR created as part of a stack with gh wt add --with-stack.
+	StackedOn int
 }
@@ -0,0 +1,59 @@
+package worktree
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiLetters spells letters that do not decompose into an ASCII base letter
+// and a combining mark.
+var asciiLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "Th",
+	'ı': "i", 'ſ': "s",
+}
+
+// NormalizeName returns name as used for worktree directories and branches.
+// Names are composed (NFC), so that a name typed on a system using
+// decomposed characters, like macOS, names the same worktree. With
+// transliterate, accented letters are also replaced by their ASCII base
+// letters and other non-ASCII characters, such as emoji, are dropped; a name
+// with nothing left is kept as-is.
+func NormalizeName(name string, transliterate bool) string {
+	name = norm.NFC.String(name)
+	if !transliterate {
+		return name
+	}
+	if t := Transliterate(name); t != "" {
+		return t
+	}
+	return name
+}
+
+// Transliterate returns s spelled with ASCII characters only: "Café Straße"
+// becomes "Cafe Strasse". Characters without an ASCII spelling are dropped,
+// along with the spaces, dashes, and underscores left around them.
+func Transliterate(s string) string {
+	var b strings.Builder
+	dropped := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII:
+			if dropped && (r == ' ' || r == '-' || r == '_') && strings.HasSuffix(b.String(), string(r)) {
+				continue
+			}
+			b.WriteRune(r)
+			dropped = false
+		case asciiLetters[r] != "":
+			b.WriteString(asciiLetters[r])
+			dropped = false
+		default:
+			dropped = true
+		}
+	}
+	return strings.Trim(b.String(), " -_")
+}
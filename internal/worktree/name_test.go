@@ -0,0 +1,36 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	decomposed := "cafe\u0301"
+	assert.Equal(t, "café", NormalizeName(decomposed, false))
+	assert.Equal(t, "🚀-launch", NormalizeName("🚀-launch", false))
+	assert.Equal(t, "修复-登录", NormalizeName("修复-登录", false))
+
+	assert.Equal(t, "cafe", NormalizeName(decomposed, true))
+	assert.Equal(t, "launch", NormalizeName("🚀-launch", true))
+	assert.Equal(t, "修复", NormalizeName("修复", true))
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"fix-login", "fix-login"},
+		{"Café Straße", "Cafe Strasse"},
+		{"Łódź-øre", "Lodz-ore"},
+		{"fix 🐛 bug", "fix bug"},
+		{"🚀_launch_🚀", "launch"},
+		{"naïve/résumé", "naive/resume"},
+		{"登录", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Transliterate(tt.in), tt.in)
+	}
+}
@@ -1,6 +1,8 @@
 package worktree
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,11 +11,11 @@ import (
 	"github.com/ffalor/gh-wt/internal/git"
 )
 
-// Create creates a new worktree.
+// Create creates a new worktree, running git through g.
 // path: The absolute path where the worktree should be created.
 // branch: The exact name of the branch to create.
 // startPoint: The ref to start from (e.g., HEAD, FETCH_HEAD, an existing branch).
-func Create(path, branch, startPoint string) error {
+func Create(ctx context.Context, g *git.Client, path, branch, startPoint string) error {
 	var err error
 
 	// Ensure the base directory exists
@@ -24,16 +26,16 @@ func Create(path, branch, startPoint string) error {
 
 	// Check if git still has a record of this worktree (even though it doesn't exist on disk)
 	// and remove it if necessary
-	if git.WorktreeIsRegistered(path) {
-		if err = git.WorktreeRemove(path, true); err != nil {
+	if g.WorktreeIsRegistered(ctx, path) {
+		if err = g.WorktreeRemove(ctx, path, true); err != nil {
 			return fmt.Errorf("failed to remove stale worktree record: %w", err)
 		}
 	}
 
 	if startPoint != "" {
-		err = git.WorktreeAddFromRef(branch, path, startPoint)
+		err = g.WorktreeAddFromRef(ctx, branch, path, startPoint)
 	} else {
-		err = git.WorktreeAdd(branch, path)
+		err = g.WorktreeAdd(ctx, branch, path)
 	}
 
 	if err != nil {
@@ -43,17 +45,17 @@ func Create(path, branch, startPoint string) error {
 	return nil
 }
 
-// Remove removes a worktree.
+// Remove removes a worktree, running git through g.
 // This function is responsible for running `git worktree remove` and ensuring the directory is gone.
-func Remove(path string, force bool) error {
+func Remove(ctx context.Context, g *git.Client, path string, force bool) error {
 	// Check for uncommitted changes if not forced
-	if !force && git.HasUncommittedChanges(path) {
-		return fmt.Errorf("worktree has uncommitted changes")
+	if !force && g.HasUncommittedChanges(ctx, path) {
+		return git.ErrDirtyWorktree
 	}
 
 	// Try to get the exact path from git's records
 	var exactPath string
-	worktrees, err := git.GetWorktreeInfo()
+	worktrees, err := g.GetWorktreeInfo(ctx)
 	if err == nil {
 		for _, wt := range worktrees {
 			if strings.HasSuffix(wt.Path, path) || wt.Path == path {
@@ -65,7 +67,11 @@ func Remove(path string, force bool) error {
 
 	// Remove worktree from git records
 	if exactPath != "" {
-		if err := git.WorktreeRemove(exactPath, force); err != nil {
+		if err := g.WorktreeRemove(ctx, exactPath, force); err != nil {
+			// A locked worktree is protected on purpose; leave it alone.
+			if errors.Is(err, git.ErrWorktreeLocked) {
+				return err
+			}
 			// If git worktree remove fails, try manual removal as a fallback
 			if err := os.RemoveAll(path); err != nil {
 				return err
@@ -0,0 +1,12 @@
+package wt
+
+import "github.com/ffalor/gh-wt/internal/git"
+
+// Git runs git commands for a Manager. It is the runner gh wt uses itself:
+// Run runs git with args in dir, writing its standard output and error to
+// stdout and stderr, and returns an error with an ExitCode() int method, such
+// as *exec.ExitError, when git fails.
+type Git = git.Runner
+
+// ExecGit runs the git executable found on PATH.
+type ExecGit = git.ExecRunner
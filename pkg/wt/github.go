@@ -0,0 +1,81 @@
+package wt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/ffalor/gh-wt/internal/github"
+)
+
+// Issue is the issue information needed to create its worktree.
+type Issue struct {
+	Number int
+	Title  string
+}
+
+// GitHub looks up pull requests and issues.
+type GitHub interface {
+	PullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error)
+	Issue(ctx context.Context, owner, repo string, number int) (*Issue, error)
+}
+
+// restGitHub implements GitHub with the REST API and gh's authentication.
+type restGitHub struct {
+	client *api.RESTClient
+}
+
+// NewGitHubClient returns a GitHub client authenticated the same way as gh.
+// Server errors and rate limits are retried with backoff.
+func NewGitHubClient() (GitHub, error) {
+	client, err := github.NewRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	return &restGitHub{client: client}, nil
+}
+
+func (g *restGitHub) PullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	var resp struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Head   struct {
+			Ref  string `json:"ref"`
+			Repo *struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref  string `json:"ref"`
+			Repo struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"base"`
+	}
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d", owner, repo, number)
+	if err := g.client.DoWithContext(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{
+		Number:      resp.Number,
+		Title:       resp.Title,
+		HeadRefName: resp.Head.Ref,
+		BaseRefName: resp.Base.Ref,
+		// A deleted fork has no head repository; treat it as a fork.
+		IsCrossRepository: resp.Head.Repo == nil || resp.Head.Repo.FullName != resp.Base.Repo.FullName,
+	}
+	if resp.Head.Repo != nil {
+		pr.HeadRepository = resp.Head.Repo.FullName
+	}
+	return pr, nil
+}
+
+func (g *restGitHub) Issue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
+	var resp Issue
+	path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, number)
+	if err := g.client.DoWithContext(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
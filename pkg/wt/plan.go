@@ -0,0 +1,123 @@
+package wt
+
+import "fmt"
+
+// RefLayout names the remote refs a forge publishes for each pull request.
+// Head and Merge are format strings taking the pull request number.
+type RefLayout struct {
+	Head  string
+	Merge string
+}
+
+// Ref layouts of the supported forges.
+var (
+	GitHubRefs = RefLayout{Head: "refs/pull/%d/head", Merge: "refs/pull/%d/merge"}
+	GitLabRefs = RefLayout{Head: "refs/merge-requests/%d/head", Merge: "refs/merge-requests/%d/merge"}
+)
+
+// PullRequest is the pull request information needed to create its worktree.
+type PullRequest struct {
+	Number      int
+	Title       string
+	HeadRefName string
+	// IsCrossRepository is set when the head branch lives in a fork.
+	IsCrossRepository bool
+	// HeadRepository is the owner/name of the repository the head branch
+	// lives in, when known.
+	HeadRepository string
+	// BaseRefName is the branch the pull request merges into, when known.
+	BaseRefName string
+	// Refs is where the forge publishes the pull request. Defaults to GitHubRefs.
+	Refs RefLayout
+}
+
+func (pr PullRequest) refs() RefLayout {
+	if pr.Refs == (RefLayout{}) {
+		return GitHubRefs
+	}
+	return pr.Refs
+}
+
+// Upstream is the remote branch a new branch tracks.
+type Upstream struct {
+	Remote string
+	// Merge is the ref on Remote, e.g. refs/heads/main or refs/pull/1/head.
+	Merge string
+}
+
+// PRPlan describes how a pull request worktree is fetched and created.
+type PRPlan struct {
+	// Branch is the default name of the branch to create.
+	Branch string
+	// Name is the default worktree directory name.
+	Name string
+	// RequireRef, when set, must exist on the remote before fetching.
+	RequireRef string
+	// Refspec is fetched from the remote.
+	Refspec string
+	// StartPoint is the ref the new branch starts from after fetching.
+	StartPoint string
+	// Upstream is what the new branch tracks, or nil.
+	Upstream *Upstream
+}
+
+// PlanPR decides how to check out pr from remote. Branches from the same
+// repository track <remote>/<head>; branches from forks track the pull
+// request's head ref on remote, as `gh pr checkout` does, so `git pull` works
+// in both cases. The merge ref is a snapshot and tracks nothing.
+//
+// When the head branch is unknown, as when GitHub is not queried, the pull
+// request is checked out like a fork and its branch is named pr_<number>.
+func PlanPR(pr PullRequest, remote string, mergeRef bool) PRPlan {
+	switch {
+	case mergeRef:
+		ref := fmt.Sprintf(pr.refs().Merge, pr.Number)
+		// The merge ref is a throwaway result of merging into the base
+		// branch; keep it apart from a worktree of the pull request head.
+		name := fmt.Sprintf("pr_%d_merge", pr.Number)
+		return PRPlan{
+			Branch:     name,
+			Name:       name,
+			RequireRef: ref,
+			Refspec:    ref,
+			StartPoint: "FETCH_HEAD",
+		}
+	case pr.IsCrossRepository || pr.HeadRefName == "":
+		ref := fmt.Sprintf(pr.refs().Head, pr.Number)
+		branch := pr.HeadRefName
+		if branch == "" {
+			branch = fmt.Sprintf("pr_%d", pr.Number)
+		}
+		return PRPlan{
+			Branch:     branch,
+			Name:       fmt.Sprintf("pr_%d", pr.Number),
+			Refspec:    ref,
+			StartPoint: "FETCH_HEAD",
+			Upstream:   &Upstream{Remote: remote, Merge: ref},
+		}
+	default:
+		remoteRef := fmt.Sprintf("refs/remotes/%s/%s", remote, pr.HeadRefName)
+		return PRPlan{
+			Branch:     pr.HeadRefName,
+			Name:       fmt.Sprintf("pr_%d", pr.Number),
+			Refspec:    fmt.Sprintf("+refs/heads/%s:%s", pr.HeadRefName, remoteRef),
+			StartPoint: remoteRef,
+			Upstream:   &Upstream{Remote: remote, Merge: "refs/heads/" + pr.HeadRefName},
+		}
+	}
+}
+
+// PlanForkPR checks out the head branch of pr from forkRemote, a remote for
+// the fork it lives in, instead of the pull request ref on the base
+// repository. The new branch tracks the fork's branch, so `git push` updates
+// the pull request when the fork grants push access.
+func PlanForkPR(pr PullRequest, forkRemote string) PRPlan {
+	remoteRef := fmt.Sprintf("refs/remotes/%s/%s", forkRemote, pr.HeadRefName)
+	return PRPlan{
+		Branch:     pr.HeadRefName,
+		Name:       fmt.Sprintf("pr_%d", pr.Number),
+		Refspec:    fmt.Sprintf("+refs/heads/%s:%s", pr.HeadRefName, remoteRef),
+		StartPoint: remoteRef,
+		Upstream:   &Upstream{Remote: forkRemote, Merge: "refs/heads/" + pr.HeadRefName},
+	}
+}
@@ -0,0 +1,392 @@
+// Package wt creates, lists, and removes git worktrees the way gh wt does, for
+// use by other Go tools. Dependencies are injected through Options so callers
+// can supply their own git runner, GitHub client, and logger.
+//
+// Worktrees are created at <BaseDir>/<repo>/<name>. Pull request and issue
+// worktrees use the pr_<number> and issue_<number> names gh wt uses unless
+// overridden; pull request branches are named after the head branch.
+//
+// A Manager runs the same code as the gh wt commands, with the same
+// safeguards: it takes the repository's lock while changing worktrees, never
+// deletes a default branch or a pinned worktree, and records worktrees in
+// the metadata gh wt list and gh wt rm use. Each Manager runs git through its
+// own Git, so Managers of different repositories can be used concurrently.
+package wt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ffalor/gh-wt/internal/git"
+	"github.com/ffalor/gh-wt/internal/lifecycle"
+	"github.com/ffalor/gh-wt/internal/lock"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/ffalor/gh-wt/internal/worktree"
+)
+
+// Kind is the source a worktree was created from.
+type Kind string
+
+const (
+	KindPR    Kind = "pr"
+	KindIssue Kind = "issue"
+	KindLocal Kind = "local"
+)
+
+var (
+	// ErrExists is returned when the worktree directory, its git registration,
+	// or its branch already exists and CreateOptions.Force is not set.
+	ErrExists = errors.New("wt: worktree or branch already exists")
+	// ErrDirty is returned when removing a worktree with uncommitted changes
+	// without RemoveOptions.Force.
+	ErrDirty = errors.New("wt: worktree has uncommitted changes")
+	// ErrNoMergeRef is returned when a pull request has no merge ref.
+	ErrNoMergeRef = errors.New("wt: pull request has no merge ref")
+	// ErrProtectedBranch is returned when CreateOptions.Force would delete a
+	// default branch in the way of a new worktree.
+	ErrProtectedBranch = lifecycle.ErrProtectedBranch
+	// ErrPinned is returned when removing a worktree pinned with gh wt pin,
+	// or when CreateOptions.Force would remove it.
+	ErrPinned = lifecycle.ErrPinned
+)
+
+// Logger receives progress messages.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Infof(string, ...any) {}
+
+// Options configures a Manager.
+type Options struct {
+	// RepoDir is the repository worktrees are created from. Defaults to the
+	// current directory.
+	RepoDir string
+	// BaseDir is the directory worktrees are created under. Required for Create.
+	BaseDir string
+	// Remote is the remote pull requests are fetched from. Defaults to origin.
+	Remote string
+	// Git runs git commands. Defaults to ExecGit.
+	Git Git
+	// GitHub looks up pull requests and issues. Defaults to a client using
+	// gh's authentication, created on first use.
+	GitHub GitHub
+	// GitOnly creates pull request and issue worktrees without querying
+	// GitHub. Pull requests are fetched from refs/pull/<number>/head.
+	GitOnly bool
+	// Logger receives progress messages. Defaults to discarding them.
+	Logger Logger
+}
+
+// Manager performs worktree operations for one repository.
+type Manager struct {
+	repoDir string
+	baseDir string
+	remote  string
+	git     *git.Client
+	github  GitHub
+	gitOnly bool
+	log     Logger
+}
+
+// New returns a Manager for opts.
+func New(opts Options) *Manager {
+	m := &Manager{
+		repoDir: opts.RepoDir,
+		baseDir: opts.BaseDir,
+		remote:  opts.Remote,
+		github:  opts.GitHub,
+		gitOnly: opts.GitOnly,
+		log:     opts.Logger,
+	}
+	if m.repoDir == "" {
+		m.repoDir = "."
+	}
+	if m.remote == "" {
+		m.remote = "origin"
+	}
+	runner := opts.Git
+	if runner == nil {
+		runner = ExecGit{}
+	}
+	m.git = git.NewClient(runner, m.repoDir, nil)
+	if m.log == nil {
+		m.log = nopLogger{}
+	}
+	return m
+}
+
+// Worktree is a git worktree.
+type Worktree struct {
+	Path   string
+	Branch string
+	Head   string
+	// Detached is set when the worktree has no branch checked out.
+	Detached bool
+	// Bare is set for the bare repository entry of a bare clone.
+	Bare bool
+	// Type and Number are set on worktrees returned by Create.
+	Type   Kind
+	Number int
+}
+
+// CreateOptions customizes Create calls.
+type CreateOptions struct {
+	// Branch overrides the name of the branch to create.
+	Branch string
+	// Name overrides the worktree directory name.
+	Name string
+	// StartPoint is the ref local and issue branches start from. Defaults to HEAD.
+	StartPoint string
+	// MergeRef checks out a pull request's merge ref instead of its head.
+	MergeRef bool
+	// Force removes an existing worktree, registration, or branch in the way,
+	// except a default branch or a pinned worktree.
+	Force bool
+}
+
+// RemoveOptions customizes Remove.
+type RemoveOptions struct {
+	// Force removes the worktree even if it has uncommitted changes.
+	Force bool
+	// KeepBranch keeps the worktree's branch instead of deleting it. Default
+	// branches are always kept.
+	KeepBranch bool
+}
+
+// List returns the worktrees of the repository.
+func (m *Manager) List(ctx context.Context) ([]Worktree, error) {
+	infos, err := m.git.GetWorktreeInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktrees := make([]Worktree, len(infos))
+	for i, info := range infos {
+		worktrees[i] = Worktree{Path: info.Path, Branch: info.Branch, Head: info.Head, Detached: info.Detached, Bare: info.Bare}
+	}
+	return worktrees, nil
+}
+
+// CreateLocal creates a worktree with a new branch named name.
+func (m *Manager) CreateLocal(ctx context.Context, name string, opts CreateOptions) (*Worktree, error) {
+	root, err := m.git.GetGitRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	repo := filepath.Base(root)
+
+	wt := &Worktree{
+		Type:   KindLocal,
+		Branch: orDefault(opts.Branch, name),
+		Path:   filepath.Join(m.baseDir, repo, orDefault(opts.Name, name)),
+	}
+	return wt, m.create(ctx, wt, "", repo, opts.StartPoint, nil, opts.Force)
+}
+
+// CreateFromPR creates a worktree for pull request number of owner/repo. The
+// new branch tracks the pull request's head, except with MergeRef.
+func (m *Manager) CreateFromPR(ctx context.Context, owner, repo string, number int, opts CreateOptions) (*Worktree, error) {
+	pr := &PullRequest{Number: number}
+	if !m.gitOnly {
+		gh, err := m.gitHub()
+		if err != nil {
+			return nil, err
+		}
+		if pr, err = gh.PullRequest(ctx, owner, repo, number); err != nil {
+			return nil, fmt.Errorf("failed to fetch PR #%d: %w", number, err)
+		}
+	}
+
+	plan := PlanPR(*pr, m.remote, opts.MergeRef)
+	if plan.RequireRef != "" {
+		exists, err := m.git.RemoteRefExists(ctx, m.remote, plan.RequireRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for PR merge ref: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: PR #%d (%s)", ErrNoMergeRef, number, plan.RequireRef)
+		}
+	}
+
+	m.log.Infof("Fetching PR #%d...\n", number)
+	if err := m.git.Fetch(ctx, m.remote, git.FetchOptions{}, plan.Refspec); err != nil {
+		return nil, fmt.Errorf("failed to fetch PR: %w", err)
+	}
+
+	wt := &Worktree{
+		Type:   KindPR,
+		Number: number,
+		Branch: orDefault(opts.Branch, plan.Branch),
+	}
+	wt.Path = filepath.Join(m.baseDir, repo, orDefault(opts.Name, plan.Name))
+	return wt, m.create(ctx, wt, owner, repo, plan.StartPoint, plan.Upstream, opts.Force)
+}
+
+// CreateFromIssue creates a worktree for issue number of owner/repo.
+func (m *Manager) CreateFromIssue(ctx context.Context, owner, repo string, number int, opts CreateOptions) (*Worktree, error) {
+	if !m.gitOnly {
+		gh, err := m.gitHub()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := gh.Issue(ctx, owner, repo, number); err != nil {
+			return nil, fmt.Errorf("failed to fetch issue #%d: %w", number, err)
+		}
+	}
+
+	wt := &Worktree{
+		Type:   KindIssue,
+		Number: number,
+		Branch: orDefault(opts.Branch, fmt.Sprintf("issue_%d", number)),
+	}
+	wt.Path = filepath.Join(m.baseDir, repo, orDefault(opts.Name, wt.Branch))
+	return wt, m.create(ctx, wt, owner, repo, opts.StartPoint, nil, opts.Force)
+}
+
+// create adds the worktree at wt.Path on a new branch from startPoint, under
+// the lock of its repository's worktree directory, and records it.
+func (m *Manager) create(ctx context.Context, wt *Worktree, owner, repo, startPoint string, track *Upstream, force bool) error {
+	if m.baseDir == "" {
+		return errors.New("wt: Options.BaseDir is required to create worktrees")
+	}
+	path, err := filepath.Abs(wt.Path)
+	if err != nil {
+		return err
+	}
+	wt.Path = path
+
+	l, err := m.lock(ctx, filepath.Dir(wt.Path))
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	if c := lifecycle.FindConflicts(ctx, m.git, wt.Path, wt.Branch); c.Any() {
+		if !force {
+			return fmt.Errorf("%w: %s (branch %s)", ErrExists, wt.Path, wt.Branch)
+		}
+		if err := lifecycle.CheckOverwrite(ctx, m.git, wt.Path, wt.Branch, c, false); err != nil {
+			return err
+		}
+		if err := lifecycle.Clear(ctx, m.git, wt.Path, wt.Branch, c); err != nil {
+			return err
+		}
+	}
+
+	m.log.Infof("Creating worktree %s...\n", wt.Path)
+	if err := lifecycle.Add(ctx, m.git, wt.Path, wt.Branch, startPoint); err != nil {
+		return err
+	}
+	if _, err := lifecycle.AssignPorts(wt.Path); err != nil {
+		m.log.Infof("%v\n", err)
+	}
+	if track != nil {
+		if err := m.git.SetUpstream(ctx, wt.Branch, track.Remote, track.Merge); err != nil {
+			return fmt.Errorf("failed to set upstream: %w", err)
+		}
+	}
+
+	now := time.Now()
+	if err := metadata.Record(metadata.Entry{
+		Path:       wt.Path,
+		Name:       filepath.Base(wt.Path),
+		Branch:     wt.Branch,
+		Type:       worktree.WorktreeType(wt.Type),
+		Owner:      owner,
+		Repo:       repo,
+		Number:     wt.Number,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		UseCount:   1,
+	}); err != nil {
+		m.log.Infof("Failed to record worktree metadata: %v\n", err)
+	}
+	return nil
+}
+
+// Remove removes the worktree at path and, unless opts.KeepBranch is set,
+// deletes its branch. Default branches are kept, and pinned worktrees are not
+// removed. Failing to delete the branch is not an error, since git refuses to
+// delete branches checked out elsewhere.
+func (m *Manager) Remove(ctx context.Context, path string, opts RemoveOptions) error {
+	worktrees, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	var target *Worktree
+	for i := range worktrees {
+		if worktrees[i].Path == path {
+			target = &worktrees[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("wt: %s is not a worktree of this repository", path)
+	}
+	if err := lifecycle.CheckNotPinned(path); err != nil {
+		return err
+	}
+
+	l, err := m.lock(ctx, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	if !opts.Force && m.git.HasUncommittedChanges(ctx, path) {
+		return fmt.Errorf("%w: %s", ErrDirty, path)
+	}
+	if err := worktree.Remove(ctx, m.git, path, opts.Force); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	if err := lifecycle.Forget(path); err != nil {
+		m.log.Infof("%v\n", err)
+	}
+
+	switch {
+	case opts.KeepBranch || target.Branch == "":
+	case !lifecycle.DeletableBranch(ctx, m.git, target.Branch, false):
+		m.log.Infof("Kept branch '%s': it is a default branch\n", target.Branch)
+	default:
+		if err := m.git.BranchDelete(ctx, target.Branch, true); err != nil {
+			m.log.Infof("Kept branch '%s': %v\n", target.Branch, err)
+		}
+	}
+	return nil
+}
+
+// lock takes the lock of the worktree directory dir, the one gh wt takes, so
+// that the Manager and gh wt do not run git worktree commands on it at once.
+// It waits for another holder until ctx is done.
+func (m *Manager) lock(ctx context.Context, dir string) (*lock.Lock, error) {
+	l, err := lock.Acquire(ctx, filepath.Join(dir, lock.FileName), "wt (Go API)", func(h lock.Holder) {
+		m.log.Infof("Waiting for %s to finish...\n", h)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", dir, err)
+	}
+	return l, nil
+}
+
+func (m *Manager) gitHub() (GitHub, error) {
+	if m.github == nil {
+		gh, err := NewGitHubClient()
+		if err != nil {
+			return nil, err
+		}
+		m.github = gh
+	}
+	return m.github, nil
+}
+
+func orDefault(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
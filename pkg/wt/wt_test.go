@@ -0,0 +1,270 @@
+package wt
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ffalor/gh-wt/internal/git/gittest"
+	"github.com/ffalor/gh-wt/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGitHub struct {
+	pr *PullRequest
+}
+
+func (f *fakeGitHub) PullRequest(context.Context, string, string, int) (*PullRequest, error) {
+	return f.pr, nil
+}
+
+func (f *fakeGitHub) Issue(_ context.Context, _, _ string, number int) (*Issue, error) {
+	return &Issue{Number: number}, nil
+}
+
+func TestPlanPR(t *testing.T) {
+	tests := []struct {
+		name     string
+		pr       PullRequest
+		mergeRef bool
+		expected PRPlan
+	}{
+		{
+			name: "same repository",
+			pr:   PullRequest{Number: 1, HeadRefName: "feature"},
+			expected: PRPlan{
+				Branch:     "feature",
+				Name:       "pr_1",
+				Refspec:    "+refs/heads/feature:refs/remotes/origin/feature",
+				StartPoint: "refs/remotes/origin/feature",
+				Upstream:   &Upstream{Remote: "origin", Merge: "refs/heads/feature"},
+			},
+		},
+		{
+			name: "fork",
+			pr:   PullRequest{Number: 2, HeadRefName: "main", IsCrossRepository: true},
+			expected: PRPlan{
+				Branch:     "main",
+				Name:       "pr_2",
+				Refspec:    "refs/pull/2/head",
+				StartPoint: "FETCH_HEAD",
+				Upstream:   &Upstream{Remote: "origin", Merge: "refs/pull/2/head"},
+			},
+		},
+		{
+			name: "unknown head branch",
+			pr:   PullRequest{Number: 4},
+			expected: PRPlan{
+				Branch:     "pr_4",
+				Name:       "pr_4",
+				Refspec:    "refs/pull/4/head",
+				StartPoint: "FETCH_HEAD",
+				Upstream:   &Upstream{Remote: "origin", Merge: "refs/pull/4/head"},
+			},
+		},
+		{
+			name: "GitLab fork",
+			pr:   PullRequest{Number: 5, HeadRefName: "fix", IsCrossRepository: true, Refs: GitLabRefs},
+			expected: PRPlan{
+				Branch:     "fix",
+				Name:       "pr_5",
+				Refspec:    "refs/merge-requests/5/head",
+				StartPoint: "FETCH_HEAD",
+				Upstream:   &Upstream{Remote: "origin", Merge: "refs/merge-requests/5/head"},
+			},
+		},
+		{
+			name:     "merge ref",
+			pr:       PullRequest{Number: 3, HeadRefName: "feature"},
+			mergeRef: true,
+			expected: PRPlan{
+				Branch:     "pr_3_merge",
+				Name:       "pr_3_merge",
+				RequireRef: "refs/pull/3/merge",
+				Refspec:    "refs/pull/3/merge",
+				StartPoint: "FETCH_HEAD",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlanPR(tt.pr, "origin", tt.mergeRef))
+		})
+	}
+}
+
+func TestPlanForkPR(t *testing.T) {
+	pr := PullRequest{Number: 4, HeadRefName: "fix-typo", IsCrossRepository: true, HeadRepository: "alice/repo"}
+	assert.Equal(t, PRPlan{
+		Branch:     "fix-typo",
+		Name:       "pr_4",
+		Refspec:    "+refs/heads/fix-typo:refs/remotes/alice/fix-typo",
+		StartPoint: "refs/remotes/alice/fix-typo",
+		Upstream:   &Upstream{Remote: "alice", Merge: "refs/heads/fix-typo"},
+	}, PlanForkPR(pr, "alice"))
+}
+
+func TestCreateFromPR(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	base := t.TempDir()
+	path := filepath.Join(base, "repo", "pr_7")
+	fake := &gittest.Fake{Responses: map[string]gittest.Response{
+		"show-ref --verify --quiet refs/heads/feature": {ExitCode: 1},
+	}}
+	m := New(Options{
+		BaseDir: base,
+		Git:     fake,
+		GitHub:  &fakeGitHub{pr: &PullRequest{Number: 7, HeadRefName: "feature"}},
+	})
+
+	got, err := m.CreateFromPR(context.Background(), "octo", "repo", 7, CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Worktree{Path: path, Branch: "feature", Type: KindPR, Number: 7}, got)
+	calls := fake.Calls()
+	assert.Contains(t, calls, "fetch origin +refs/heads/feature:refs/remotes/origin/feature")
+	assert.Contains(t, calls, "worktree add -b feature "+path+" refs/remotes/origin/feature")
+	assert.Contains(t, calls, "config branch.feature.merge refs/heads/feature")
+
+	store, err := metadata.Load()
+	require.NoError(t, err)
+	entry, ok := store.Get(path)
+	require.True(t, ok, "worktree recorded in the metadata")
+	assert.Equal(t, "feature", entry.Branch)
+	assert.Equal(t, 7, entry.Number)
+}
+
+func TestCreateRefusesConflictsWithoutForce(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := New(Options{BaseDir: t.TempDir(), Git: &gittest.Fake{}, GitHub: &fakeGitHub{}})
+
+	_, err := m.CreateFromIssue(context.Background(), "octo", "repo", 5, CreateOptions{})
+	assert.ErrorIs(t, err, ErrExists, "issue_5 branch reported as existing by show-ref")
+}
+
+func TestCreateForceKeepsDefaultBranch(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := New(Options{BaseDir: t.TempDir(), Git: &gittest.Fake{}, GitHub: &fakeGitHub{}})
+
+	_, err := m.CreateFromIssue(context.Background(), "octo", "repo", 5, CreateOptions{Branch: "main", Force: true})
+	assert.ErrorIs(t, err, ErrProtectedBranch)
+}
+
+// gitIn runs git in repo for test setup.
+func gitIn(t *testing.T, repo string, args ...string) {
+	t.Helper()
+	require.NoError(t, ExecGit{}.Run(context.Background(), repo, io.Discard, io.Discard, args...))
+}
+
+// newRepo returns a git repository with one commit on main.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-q", "-b", "main")
+	gitIn(t, repo, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	return repo
+}
+
+func TestLocalLifecycle(t *testing.T) {
+	repo := newRepo(t)
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	m := New(Options{RepoDir: repo, BaseDir: base})
+
+	created, err := m.CreateLocal(ctx, "topic", CreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(base, filepath.Base(repo), "topic"), created.Path)
+
+	worktrees, err := m.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, worktrees, 2)
+	assert.Equal(t, "topic", worktrees[1].Branch)
+
+	require.NoError(t, os.WriteFile(filepath.Join(created.Path, "dirty"), nil, 0o644))
+	assert.ErrorIs(t, m.Remove(ctx, created.Path, RemoveOptions{}), ErrDirty)
+	require.NoError(t, m.Remove(ctx, created.Path, RemoveOptions{Force: true}))
+
+	worktrees, err = m.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, worktrees, 1)
+	assert.False(t, m.git.BranchExists(ctx, "topic"), "branch is deleted with the worktree")
+
+	store, err := metadata.Load()
+	require.NoError(t, err)
+	_, ok := store.Get(created.Path)
+	assert.False(t, ok, "worktree forgotten from the metadata")
+}
+
+func TestRemoveSafeguards(t *testing.T) {
+	repo := newRepo(t)
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	gitIn(t, repo, "checkout", "-q", "-b", "other")
+
+	ctx := context.Background()
+	m := New(Options{RepoDir: repo, BaseDir: base})
+
+	path := filepath.Join(base, "main")
+	gitIn(t, repo, "worktree", "add", "-q", path, "main")
+	require.NoError(t, m.Remove(ctx, path, RemoveOptions{}))
+	assert.True(t, m.git.BranchExists(ctx, "main"), "default branch is kept")
+
+	pinned, err := m.CreateLocal(ctx, "pinned", CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, metadata.Update(func(store *metadata.Store) (bool, error) {
+		entry, _ := store.Get(pinned.Path)
+		entry.Pinned = true
+		store.Put(entry)
+		return true, nil
+	}))
+	assert.ErrorIs(t, m.Remove(ctx, pinned.Path, RemoveOptions{Force: true}), ErrPinned)
+	assert.DirExists(t, pinned.Path)
+}
+
+func TestManagersRunConcurrently(t *testing.T) {
+	repos := []string{newRepo(t), newRepo(t)}
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := New(Options{RepoDir: repo, BaseDir: filepath.Join(base, strconv.Itoa(i))})
+			_, err := m.CreateLocal(ctx, "topic", CreateOptions{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	for _, repo := range repos {
+		worktrees, err := New(Options{RepoDir: repo}).List(ctx)
+		require.NoError(t, err)
+		require.Len(t, worktrees, 2, "each Manager creates in its own repository")
+		assert.Equal(t, "topic", worktrees[1].Branch)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	repo := newRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(Options{RepoDir: repo}).List(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
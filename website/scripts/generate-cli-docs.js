@@ -1,5 +1,5 @@
 import { execSync } from 'child_process';
-import { readdirSync, readFileSync, writeFileSync, rmSync } from 'fs';
+import { readdirSync, readFileSync, writeFileSync, rmSync, mkdirSync, copyFileSync } from 'fs';
 import { join, dirname } from 'path';
 import { fileURLToPath } from 'url';
 
@@ -49,4 +49,10 @@ files.filter(f => f.endsWith('.mdx')).forEach(f => {
   writeFileSync(filepath, content);
 });
 
+// Publish the config schema at /gh-wt/schema/config.json
+const schemaDir = join(projectRoot, 'website/public/schema');
+mkdirSync(schemaDir, { recursive: true });
+copyFileSync(join(projectRoot, 'internal/config/schema.json'), join(schemaDir, 'config.json'));
+console.log('Copied config schema');
+
 console.log('CLI docs generation complete!');